@@ -18,6 +18,9 @@ type Account struct {
 	Password string
 	Birthday time.Time
 	Profiles []Profile
+	// Settings are the account preferences, they are
+	// not part of the token and are loaded apart.
+	Settings *Settings
 }
 
 // FromUserToken gets the user information from a firebase token
@@ -88,6 +91,12 @@ func ProfileFromRequest(r *http.Request, acc *Account) (int, error) {
 	return p, nil
 }
 
+// ProfileKey returns a key that identifies the profile with index id,
+// it is used to store profile data out of TMDB lists.
+func (a *Account) ProfileKey(id int) string {
+	return a.Email + "/" + strconv.Itoa(id)
+}
+
 // Profile stores the name the profile and 3 list IDs.
 type Profile struct {
 	// Name is the name of profile.
@@ -0,0 +1,185 @@
+package account
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"firebase.google.com/go/auth"
+	"github.com/rschio/movieApp/client"
+	"github.com/rschio/movieApp/client/clienttest"
+)
+
+func newClient(t *testing.T) (*client.Client, *clienttest.Server) {
+	srv := clienttest.NewServer()
+	t.Cleanup(srv.Close)
+	return client.New(srv.URL, clienttest.Token, srv.Client()), srv
+}
+
+func TestNew(t *testing.T) {
+	c, srv := newClient(t)
+	birthday := time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)
+	acc := New("a@b.com", "secret", "Ana", birthday, c)
+	if len(acc.Profiles) != 1 {
+		t.Fatalf("got %d profiles, want 1", len(acc.Profiles))
+	}
+	p := acc.Profiles[0]
+	ids := map[int]string{
+		p.WatchListID:      "a@b.comAnaWatchList",
+		p.WatchedListID:    "a@b.comAnaWatchedList",
+		p.SujestionsListID: "a@b.comAnaSujestionsList",
+	}
+	if len(ids) != 3 {
+		t.Fatalf("profile lists are not distinct: %+v", p)
+	}
+	for id, name := range ids {
+		if l, ok := srv.List(id); !ok || l.Name != name {
+			t.Errorf("list %d = %+v, want name %q", id, l, name)
+		}
+	}
+}
+
+func TestNewProfileLimit(t *testing.T) {
+	c, _ := newClient(t)
+	acc := New("a@b.com", "secret", "Ana", time.Now(), c)
+	for _, name := range []string{"Bia", "Caio", "Davi"} {
+		if err := acc.NewProfile(name, c); err != nil {
+			t.Fatalf("failed to create profile %s: %v", name, err)
+		}
+	}
+	if err := acc.NewProfile("Eva", c); err == nil {
+		t.Errorf("expected error creating the fifth profile")
+	}
+	if len(acc.Profiles) != 4 {
+		t.Errorf("got %d profiles, want 4", len(acc.Profiles))
+	}
+}
+
+func TestFromUserToken(t *testing.T) {
+	c, _ := newClient(t)
+	birthday := time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)
+	want := New("a@b.com", "secret", "Ana", birthday, c)
+	// Claims are JSON in the token, so encode and
+	// decode them like firebase does.
+	b, err := json.Marshal(map[string]interface{}{
+		"email":    want.Email,
+		"name":     want.Name,
+		"birthday": want.Birthday,
+		"profiles": want.Profiles,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := new(auth.Token)
+	if err := json.Unmarshal(b, &token.Claims); err != nil {
+		t.Fatal(err)
+	}
+	acc, err := FromUserToken(token)
+	if err != nil {
+		t.Fatalf("failed to get account from token: %v", err)
+	}
+	if acc.Email != want.Email || acc.Name != want.Name || !acc.Birthday.Equal(want.Birthday) {
+		t.Errorf("got %+v, want %+v", acc, want)
+	}
+	if len(acc.Profiles) != 1 || acc.Profiles[0] != want.Profiles[0] {
+		t.Errorf("got profiles %+v, want %+v", acc.Profiles, want.Profiles)
+	}
+}
+
+func TestProfileFromRequest(t *testing.T) {
+	acc := &Account{Profiles: make([]Profile, 2)}
+	tests := []struct {
+		cookie string
+		want   int
+		ok     bool
+	}{
+		{"1", 1, true},
+		{"2", -1, false},
+		{"x", -1, false},
+		{"", -1, false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/browse", nil)
+		if tt.cookie != "" {
+			r.AddCookie(&http.Cookie{Name: "profile", Value: tt.cookie})
+		}
+		got, err := ProfileFromRequest(r, acc)
+		if got != tt.want || (err == nil) != tt.ok {
+			t.Errorf("cookie %q: got %d, %v, want %d", tt.cookie, got, err, tt.want)
+		}
+	}
+}
+
+func TestCopyLists(t *testing.T) {
+	app, srv := newClient(t)
+	acc := New("a@b.com", "secret", "Ana", time.Now(), app)
+	if err := acc.NewProfile("Kids", app); err != nil {
+		t.Fatal(err)
+	}
+	watch := acc.Profiles[0].WatchListID
+	item := client.TVItem(1396)
+	item.Comment = "recommended by Bia"
+	if _, err := app.AddItems(watch, client.MovieItem(550), item); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.UpdateItems(watch, item); err != nil {
+		t.Fatal(err)
+	}
+	user := app.WithToken("user-token")
+
+	profiles, err := acc.CopyLists(app, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profiles) != 2 || profiles[1].Name != "Kids" {
+		t.Fatalf("unexpected profiles: %+v", profiles)
+	}
+	l, ok := srv.List(profiles[0].WatchListID)
+	if !ok || l.Owner != "user-token" {
+		t.Fatalf("WatchList not created by the user: %+v", l)
+	}
+	if len(l.Items) != 2 || l.Items[0] != "movie/550" || l.Items[1] != "tv/1396" {
+		t.Errorf("WatchList items = %v", l.Items)
+	}
+	if l.Comments["tv/1396"] != "recommended by Bia" {
+		t.Errorf("comments = %v", l.Comments)
+	}
+	// The account and the old lists are not changed.
+	if acc.Profiles[0].WatchListID != watch {
+		t.Error("account profiles changed")
+	}
+	if old, _ := srv.List(watch); len(old.Items) != 2 {
+		t.Errorf("old WatchList items = %v", old.Items)
+	}
+
+	DeleteLists(app, acc.Profiles)
+	if _, ok := srv.List(watch); ok {
+		t.Error("old WatchList not deleted")
+	}
+}
+
+func TestCopyLargeList(t *testing.T) {
+	app, srv := newClient(t)
+	acc := New("a@b.com", "secret", "Ana", time.Now(), app)
+	watched := acc.Profiles[0].WatchedListID
+	n := 2*copyBatchSize + 1
+	var items []client.Item
+	for id := 1000; id < 1000+n; id++ {
+		srv.AddMedia(clienttest.Media{MediaType: client.MediaMovie, ID: id, Title: "Movie"})
+		items = append(items, client.MovieItem(id))
+	}
+	for _, batch := range client.Batches(items, copyBatchSize) {
+		if _, err := app.AddItems(watched, batch...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	profiles, err := acc.CopyLists(app, app.WithToken("user-token"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l, _ := srv.List(profiles[0].WatchedListID); len(l.Items) != n {
+		t.Errorf("got %d items copied, want %d", len(l.Items), n)
+	}
+}
@@ -0,0 +1,86 @@
+package account
+
+import (
+	"log"
+
+	"github.com/rschio/movieApp/client"
+)
+
+// CopyLists copies the lists of all the profiles, with the items
+// and comments, from the TMDB account of client from to the TMDB
+// account of client to. It returns the profiles with the new list
+// IDs, the account is not changed and the old lists are kept.
+// If it fails the lists already created are deleted.
+func (a *Account) CopyLists(from, to *client.Client) ([]Profile, error) {
+	profiles := make([]Profile, 0, len(a.Profiles))
+	for _, p := range a.Profiles {
+		ids, err := createListIDs(a.Email+p.Name, to)
+		if err != nil {
+			DeleteLists(to, profiles)
+			return nil, err
+		}
+		np := Profile{
+			Name:             p.Name,
+			WatchListID:      ids[0],
+			WatchedListID:    ids[1],
+			SujestionsListID: ids[2],
+		}
+		profiles = append(profiles, np)
+		pairs := [][2]int{
+			{p.WatchListID, np.WatchListID},
+			{p.WatchedListID, np.WatchedListID},
+			{p.SujestionsListID, np.SujestionsListID},
+		}
+		for _, pair := range pairs {
+			if err := copyList(from, to, pair[0], pair[1]); err != nil {
+				DeleteLists(to, profiles)
+				return nil, err
+			}
+		}
+	}
+	return profiles, nil
+}
+
+// copyBatchSize is the number of items
+// copied to a list in each request.
+const copyBatchSize = 100
+
+// copyList copies the items and comments of list fromID
+// to list toID, in batches of copyBatchSize.
+func copyList(from, to *client.Client, fromID, toID int) error {
+	l, err := from.GetWholeList(fromID, "")
+	if err != nil {
+		return err
+	}
+	var items, commented []client.Item
+	for _, r := range l.Results {
+		item := r.Item()
+		items = append(items, item)
+		if item.Comment = l.Comment(r); item.Comment != "" {
+			commented = append(commented, item)
+		}
+	}
+	for _, batch := range client.Batches(items, copyBatchSize) {
+		if _, err := to.AddItems(toID, batch...); err != nil {
+			return err
+		}
+	}
+	for _, batch := range client.Batches(commented, copyBatchSize) {
+		if _, err := to.UpdateItems(toID, batch...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteLists deletes the lists of profiles with client c.
+// It is best effort, the errors are only logged.
+func DeleteLists(c *client.Client, profiles []Profile) {
+	for _, p := range profiles {
+		for _, id := range []int{p.WatchListID, p.WatchedListID, p.SujestionsListID} {
+			if err := c.DeleteList(id); err != nil {
+				log.Printf("failed to delete list %d: %v", id, err)
+			}
+		}
+	}
+}
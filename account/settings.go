@@ -0,0 +1,97 @@
+package account
+
+import (
+	"time"
+
+	"github.com/rschio/movieApp/store"
+)
+
+// settingsBucket is the store bucket of settings,
+// the keys are the accounts emails.
+const settingsBucket = "settings"
+
+// Settings stores the account preferences. Unlike profiles,
+// settings are not stored as firebase claims, so they can
+// change without a new login.
+type Settings struct {
+	// Country is the ISO 3166-1 code of the country used
+	// to check where movies are available, e.g. "BR".
+	Country string
+	// Language is the IETF tag of the language of the TMDB
+	// content, e.g. "pt-BR". Empty means the TMDB default.
+	Language string
+	// Providers are the IDs of the watch providers the
+	// account is subscribed to.
+	Providers []int
+	// TMDBAccessToken is the access token of the user's own
+	// TMDB account. When set, the profiles' lists belong to
+	// the user's TMDB account instead of the app account.
+	TMDBAccessToken string
+	// TMDBAccountID is the ID of the linked TMDB account.
+	TMDBAccountID string
+	// TMDBRequestToken is the request token waiting for the
+	// user approval to link the TMDB account.
+	TMDBRequestToken string
+	// Sync is the profile synced with the linked TMDB
+	// account, nil if no profile is synced.
+	Sync *Sync
+}
+
+// Sync is a profile that receives the rated, favorite and
+// watchlist movies of the linked TMDB account.
+type Sync struct {
+	// Profile is the name of the profile.
+	Profile string
+	// ProfileKey is the key of the profile, see Account.ProfileKey,
+	// it is empty for the profiles synced before it was stored.
+	ProfileKey    string
+	WatchListID   int
+	WatchedListID int
+	// LastSync is the time of the last successful sync.
+	LastSync time.Time
+}
+
+// Linked reports if the account is linked to a TMDB account.
+func (s *Settings) Linked() bool {
+	return s.TMDBAccessToken != ""
+}
+
+// Subscribed reports if the account is subscribed to provider.
+func (s *Settings) Subscribed(provider int) bool {
+	for _, id := range s.Providers {
+		if id == provider {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadSettings returns the settings of the account with email
+// email, an account without settings has the zero Settings.
+func LoadSettings(db *store.Store, email string) (*Settings, error) {
+	s := new(Settings)
+	if _, err := db.Get(settingsBucket, email, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// UpdateSettings calls fn with the stored settings of the account
+// with email email and stores the changes, atomically. Use it when
+// the settings may change between a load and a save.
+func UpdateSettings(db *store.Store, email string, fn func(*Settings) error) error {
+	s := new(Settings)
+	return db.Update(settingsBucket, email, s, func() error {
+		return fn(s)
+	})
+}
+
+// SettingsEmails returns the emails of the accounts with settings.
+func SettingsEmails(db *store.Store) []string {
+	return db.Keys(settingsBucket)
+}
+
+// Save stores the settings of the account with email email.
+func (s *Settings) Save(db *store.Store, email string) error {
+	return db.Put(settingsBucket, email, s)
+}
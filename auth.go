@@ -21,8 +21,9 @@ func (s *server) authenticate(r *http.Request) (*auth.Token, error) {
 }
 
 // createAccount creates a account on firebase with account info, store profiles
-// as firebase claims and send a email to a.Email with verification link.
-func (s *server) createAccount(ctx context.Context, a *account.Account) error {
+// as firebase claims and send a email to a.Email with verification link
+// written in lang.
+func (s *server) createAccount(ctx context.Context, a *account.Account, lang string) error {
 	u := new(auth.UserToCreate)
 	u.Email(a.Email)
 	u.Password(a.Password)
@@ -51,7 +52,26 @@ func (s *server) createAccount(ctx context.Context, a *account.Account) error {
 	}
 	// Send verification link to confirm that emails is owned
 	// by user.
-	return s.mailer.SendVerificationLink(a.Name, a.Email, link)
+	return s.mailer.SendVerificationLink(lang, a.Name, a.Email, link)
+}
+
+// updateProfiles stores the account profiles as claims of the
+// firebase user and revokes the user tokens, the user must login
+// again to get a token with the new profiles.
+func (s *server) updateProfiles(ctx context.Context, acc *account.Account) error {
+	record, err := s.auther.GetUserByEmail(ctx, acc.Email)
+	if err != nil {
+		return err
+	}
+	update := new(auth.UserToUpdate)
+	update.CustomClaims(map[string]interface{}{
+		"birthday": acc.Birthday,
+		"profiles": acc.Profiles,
+	})
+	if _, err = s.auther.UpdateUser(ctx, record.UID, update); err != nil {
+		return err
+	}
+	return s.auther.RevokeRefreshTokens(ctx, record.UID)
 }
 
 func getIDTokenFromBody(r *http.Request) (string, error) {
@@ -25,6 +25,13 @@ func (s *server) Authorize(fn accountHandler) http.HandlerFunc {
 			http.Redirect(w, r, "/login", http.StatusFound)
 			return
 		}
+		// Load the account settings.
+		acc.Settings, err = account.LoadSettings(s.store, acc.Email)
+		if err != nil {
+			log.Println(err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
 		// Execute the protected handler.
 		fn(w, r, acc)
 	}
@@ -51,7 +58,7 @@ func (s *server) signup(w http.ResponseWriter, r *http.Request) {
 	// Create a new account, set it in firebase
 	// and send verification email.
 	acc := account.New(email, password, name, date, s.client)
-	err = s.createAccount(r.Context(), acc)
+	err = s.createAccount(r.Context(), acc, uiLanguage(r, nil))
 	if err != nil {
 		log.Printf("failed to create account: %v", err)
 		http.Error(w, "account already exists or invalid parameters", http.StatusBadRequest)
@@ -115,5 +122,5 @@ func (s *server) login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	// Show login page with login fields.
-	s.tmpl.ExecuteTemplate(w, "login.html", nil)
+	s.tmpl(r, nil).ExecuteTemplate(w, "login.html", nil)
 }
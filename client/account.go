@@ -0,0 +1,111 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Kinds of movies of a TMDB account.
+const (
+	AccountRated     = "rated"
+	AccountFavorites = "favorites"
+	AccountWatchlist = "watchlist"
+)
+
+// AccountRating is the rating of a movie by the account.
+type AccountRating struct {
+	Value     float64 `json:"value"`
+	CreatedAt string  `json:"created_at"`
+}
+
+// AccountMovie is a movie of a TMDB account, the
+// rated movies have AccountRating.
+type AccountMovie struct {
+	Result
+	AccountRating *AccountRating `json:"account_rating"`
+}
+
+// AccountMovies is a page of movies of a TMDB account.
+type AccountMovies struct {
+	Page         int            `json:"page"`
+	Results      []AccountMovie `json:"results"`
+	TotalPages   int            `json:"total_pages"`
+	TotalResults int            `json:"total_results"`
+}
+
+// GetAccountMovies get a page of the rated, favorite or watchlist
+// movies of the account with ID accountID, kind is AccountRated,
+// AccountFavorites or AccountWatchlist. It must be called with
+// the access token of the account.
+func (c *Client) GetAccountMovies(accountID, kind string, page int) (*AccountMovies, error) {
+	switch kind {
+	case AccountRated, AccountFavorites, AccountWatchlist:
+	default:
+		return nil, fmt.Errorf("invalid account movies kind: %s", kind)
+	}
+	path := "/account/" + url.PathEscape(accountID) + "/movie/" + kind
+	if page < 1 {
+		page = 1
+	}
+	params := make(url.Values)
+	params.Set("page", strconv.Itoa(page))
+	resp, err := c.MakeGet(path, params)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	movies := new(AccountMovies)
+	if err := decodeResponse(movies, resp.Body); err != nil {
+		return nil, err
+	}
+	return movies, nil
+}
+
+// GetAllAccountMovies get the movies of all the pages of
+// GetAccountMovies.
+func (c *Client) GetAllAccountMovies(accountID, kind string) ([]AccountMovie, error) {
+	var movies []AccountMovie
+	for page, total := 1, 1; page <= total; page++ {
+		resp, err := c.GetAccountMovies(accountID, kind, page)
+		if err != nil {
+			return nil, err
+		}
+		movies = append(movies, resp.Results...)
+		total = resp.TotalPages
+	}
+	return movies, nil
+}
+
+// RateMovie rates the movie with ID id with value, from 0.5 to 10
+// in steps of 0.5. It must be called with the access token of the
+// user, TMDB only rates movies in api v3.
+func (c *Client) RateMovie(id int, value float64) error {
+	if value < 0.5 || value > 10 || value*2 != float64(int(value*2)) {
+		return fmt.Errorf("invalid rating: %v", value)
+	}
+	path := "/movie/" + strconv.Itoa(id) + "/rating"
+	payload, err := json.Marshal(map[string]float64{"value": value})
+	if err != nil {
+		return err
+	}
+	r, err := http.NewRequest("POST", c.v3URL+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Authorization", "Bearer "+c.apiToken)
+	resp, err := c.client.Do(r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	sr := new(statusResponse)
+	if err := decodeResponse(sr, resp.Body); err != nil {
+		return err
+	}
+	return sr.check("rate movie")
+}
@@ -0,0 +1,116 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// AuthApproveURL is the TMDB page where the user
+// approves a request token.
+const AuthApproveURL = "https://www.themoviedb.org/auth/access"
+
+// WithToken returns a copy of c that makes the requests with
+// token, e.g. the access token of a user. The http client is
+// shared with c.
+func (c *Client) WithToken(token string) *Client {
+	cp := *c
+	cp.apiToken = token
+	return &cp
+}
+
+// ApproveURL returns the URL of the TMDB page where the
+// user approves requestToken.
+func ApproveURL(requestToken string) string {
+	return AuthApproveURL + "?request_token=" + url.QueryEscape(requestToken)
+}
+
+type requestTokenReq struct {
+	RedirectTo string `json:"redirect_to,omitempty"`
+}
+
+type requestTokenResp struct {
+	statusResponse
+	RequestToken string `json:"request_token"`
+}
+
+// CreateRequestToken creates a request token that the user must
+// approve in ApproveURL, after the approval TMDB redirects the
+// user to redirectTo. It must be called with the app token.
+func (c *Client) CreateRequestToken(redirectTo string) (string, error) {
+	const path = "/auth/request_token"
+	payload, err := json.Marshal(requestTokenReq{RedirectTo: redirectTo})
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.MakePost(path, bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	rt := new(requestTokenResp)
+	if err := decodeResponse(rt, resp.Body); err != nil {
+		return "", err
+	}
+	if err := rt.check("create request token"); err != nil {
+		return "", err
+	}
+	return rt.RequestToken, nil
+}
+
+// AccessToken is the access token of a user's TMDB account.
+type AccessToken struct {
+	AccessToken string `json:"access_token"`
+	AccountID   string `json:"account_id"`
+}
+
+type accessTokenResp struct {
+	statusResponse
+	AccessToken
+}
+
+// CreateAccessToken creates the access token of the user that
+// approved requestToken. It must be called with the app token.
+func (c *Client) CreateAccessToken(requestToken string) (*AccessToken, error) {
+	const path = "/auth/access_token"
+	payload, err := json.Marshal(map[string]string{"request_token": requestToken})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.MakePost(path, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	at := new(accessTokenResp)
+	if err := decodeResponse(at, resp.Body); err != nil {
+		return nil, err
+	}
+	if err := at.check("create access token"); err != nil {
+		return nil, err
+	}
+	if at.AccessToken.AccessToken == "" {
+		return nil, fmt.Errorf("failed to create access token: empty token")
+	}
+	return &at.AccessToken, nil
+}
+
+// DeleteAccessToken logs out the access token accessToken.
+func (c *Client) DeleteAccessToken(accessToken string) error {
+	const path = "/auth/access_token"
+	payload, err := json.Marshal(map[string]string{"access_token": accessToken})
+	if err != nil {
+		return err
+	}
+	resp, err := c.MakeDelete(path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	sr := new(statusResponse)
+	if err := decodeResponse(sr, resp.Body); err != nil {
+		return err
+	}
+	return sr.check("delete access token")
+}
@@ -4,6 +4,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 )
 
 // DefaultURL is the URL to api v4 of TMDB.
@@ -14,9 +15,20 @@ type Client struct {
 	client   *http.Client
 	apiToken string
 	baseURL  string
+	// v3URL is the URL of the endpoints that only exist in
+	// api v3, all but the lists, account and auth ones, e.g.
+	// movie details, search, discover and movie rating.
+	v3URL string
+	// language and region are sent in all GET
+	// requests, see WithLocale.
+	language string
+	region   string
 }
 
 // New creates a new client of TMDB API.
+// If baseURL is the URL of api v4, like DefaultURL, the
+// v3 only endpoints use the URL of api v3, otherwise they
+// use baseURL too.
 func New(baseURL, apiToken string, client *http.Client) *Client {
 	if client == nil {
 		client = http.DefaultClient
@@ -25,14 +37,66 @@ func New(baseURL, apiToken string, client *http.Client) *Client {
 		client:   client,
 		apiToken: apiToken,
 		baseURL:  baseURL,
+		v3URL:    baseURL,
+	}
+	if strings.HasSuffix(baseURL, "/4") {
+		c.v3URL = strings.TrimSuffix(baseURL, "/4") + "/3"
 	}
 	return c
 }
 
-// MakeGet make a GET request with specified params to path path.
+// WithLocale returns a copy of c that sends language, e.g. "pt-BR",
+// and region, e.g. "BR", in all GET requests, so titles, overviews
+// and release dates are localized. Empty values are not sent and
+// params set by the caller, like SearchOptions.Language, are kept.
+func (c *Client) WithLocale(language, region string) *Client {
+	cp := *c
+	cp.language = language
+	cp.region = region
+	return &cp
+}
+
+// LanguageCode returns the ISO 639-1 code of the client
+// language, e.g. "pt" for "pt-BR", or "en" without language.
+func (c *Client) LanguageCode() string {
+	if c.language == "" {
+		return "en"
+	}
+	return strings.SplitN(c.language, "-", 2)[0]
+}
+
+// locale returns params with the language and region of c.
+func (c *Client) locale(params url.Values) url.Values {
+	if c.language == "" && c.region == "" {
+		return params
+	}
+	out := make(url.Values, len(params)+2)
+	for k, v := range params {
+		out[k] = v
+	}
+	if c.language != "" && out.Get("language") == "" {
+		out.Set("language", c.language)
+	}
+	if c.region != "" && out.Get("region") == "" {
+		out.Set("region", c.region)
+	}
+	return out
+}
+
+// MakeGet make a GET request with specified params to path path,
+// the language and region of c are added to params.
 func (c *Client) MakeGet(path string, params url.Values) (*http.Response, error) {
-	encoded := params.Encode()
-	r, err := http.NewRequest("GET", c.baseURL+path, nil)
+	return c.get(c.baseURL+path, params)
+}
+
+// getV3 is MakeGet of an endpoint that only exists in api v3.
+func (c *Client) getV3(path string, params url.Values) (*http.Response, error) {
+	return c.get(c.v3URL+path, params)
+}
+
+func (c *Client) get(rawURL string, params url.Values) (*http.Response, error) {
+	encoded := c.locale(params).Encode()
+	r, err := http.NewRequest("GET", rawURL, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -52,6 +116,17 @@ func (c *Client) MakePost(path string, body io.Reader) (*http.Response, error) {
 	return c.client.Do(r)
 }
 
+// MakePut make a PUT request with specified body body to path path.
+func (c *Client) MakePut(path string, body io.Reader) (*http.Response, error) {
+	r, err := http.NewRequest("PUT", c.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Authorization", "Bearer "+c.apiToken)
+	return c.client.Do(r)
+}
+
 // MakeDelete make a DELETe request with specified body body to path path.
 func (c *Client) MakeDelete(path string, body io.Reader) (*http.Response, error) {
 	r, err := http.NewRequest("DELETE", c.baseURL+path, body)
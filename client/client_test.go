@@ -1,22 +1,38 @@
 package client
 
 import (
-	"os"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
 	"testing"
-)
 
-var (
-	testID      int
-	godfatherID = 238
+	"github.com/rschio/movieApp/client/clienttest"
 )
 
-func newClient() *Client {
-	token := os.Getenv("TMDBTOKEN")
-	return New(DefaultURL, token, nil)
+var godfatherID = 238
+
+// newClient returns a client to a fake TMDB server seeded
+// with clienttest.Fixtures, the server is closed at test end.
+func newClient(t *testing.T) (*Client, *clienttest.Server) {
+	srv := clienttest.NewServer()
+	t.Cleanup(srv.Close)
+	return New(srv.URL, clienttest.Token, srv.Client()), srv
+}
+
+// newList creates a new list for a test and returns its ID.
+func newList(t *testing.T, c *Client) int {
+	id, err := c.CreateList("testlist")
+	if err != nil {
+		t.Fatalf("failed to create list: %v", err)
+	}
+	return id
 }
 
 func TestSearchMovie(t *testing.T) {
-	c := newClient()
+	c, _ := newClient(t)
 	query := "GodFather"
 	res, err := c.SearchMovie(query)
 	if err != nil {
@@ -29,7 +45,7 @@ func TestSearchMovie(t *testing.T) {
 }
 
 func TestDiscoverMovie(t *testing.T) {
-	c := newClient()
+	c, _ := newClient(t)
 	genres := []int{18, 80}
 	res, err := c.DiscoverMovie(genres)
 	if err != nil {
@@ -42,21 +58,24 @@ func TestDiscoverMovie(t *testing.T) {
 }
 
 func TestCreateList(t *testing.T) {
-	c := newClient()
+	c, srv := newClient(t)
 	listname := "testlist"
-	var err error
-	testID, err = c.CreateList(listname)
+	id, err := c.CreateList(listname)
 	if err != nil {
 		t.Errorf("failed to create list: %v", err)
 		return
 	}
-	if testID < 0 {
+	if id < 0 {
 		t.Errorf("negative id")
 	}
+	if l, ok := srv.List(id); !ok || l.Name != listname {
+		t.Errorf("list %d was not created: %+v", id, l)
+	}
 }
 
 func TestGetList(t *testing.T) {
-	c := newClient()
+	c, _ := newClient(t)
+	testID := newList(t, c)
 	page := 1
 	listResp, err := c.GetList(testID, page)
 	if err != nil {
@@ -70,9 +89,211 @@ func TestGetList(t *testing.T) {
 
 }
 
+func TestGetListPages(t *testing.T) {
+	c, srv := newClient(t)
+	srv.PageSize = 2
+	testID := newList(t, c)
+	_, err := c.AddItems(testID, MovieItem(238), MovieItem(240), TVItem(1396))
+	if err != nil {
+		t.Fatalf("failed to add items: %v", err)
+	}
+	lists, err := c.GetLists(testID, 1, testID, 2)
+	if err != nil {
+		t.Fatalf("failed to get lists: %v", err)
+	}
+	first, second := lists[0], lists[1]
+	if first.TotalPages != 2 || first.TotalResults != 3 || len(first.Results) != 2 {
+		t.Errorf("unexpected first page: %+v", first)
+	}
+	if second.Page != 2 || len(second.Results) != 1 || second.Results[0].Key() != "tv/1396" {
+		t.Errorf("unexpected second page: %+v", second)
+	}
+}
+
+func TestGetAllItems(t *testing.T) {
+	c, srv := newClient(t)
+	srv.PageSize = 2
+	testID := newList(t, c)
+	// With page size 1 the 7 pages are requested in
+	// more than one batch of page workers.
+	items := []Item{
+		MovieItem(238), MovieItem(240), MovieItem(278), MovieItem(550),
+		MovieItem(680), MovieItem(13), TVItem(1396),
+	}
+	if _, err := c.AddItems(testID, items...); err != nil {
+		t.Fatalf("failed to add items: %v", err)
+	}
+	for _, size := range []int{2, 1} {
+		srv.PageSize = size
+		results, err := c.GetAllItems(testID)
+		if err != nil {
+			t.Fatalf("page size %d: %v", size, err)
+		}
+		if len(results) != len(items) {
+			t.Fatalf("page size %d: got %d results, want %d", size, len(results), len(items))
+		}
+		for i, r := range results {
+			if r.Key() != items[i].Key() {
+				t.Errorf("page size %d: result %d = %s, want %s", size, i, r.Key(), items[i].Key())
+			}
+		}
+	}
+}
+
+func TestBatches(t *testing.T) {
+	items := []Item{MovieItem(238), MovieItem(240), MovieItem(278), MovieItem(550), MovieItem(680)}
+	tests := []struct {
+		size  int
+		sizes []int
+	}{
+		{2, []int{2, 2, 1}},
+		{5, []int{5}},
+		{10, []int{5}},
+		{0, []int{5}},
+		{-1, []int{5}},
+	}
+	for _, tt := range tests {
+		var sizes []int
+		for _, b := range Batches(items, tt.size) {
+			sizes = append(sizes, len(b))
+		}
+		if !reflect.DeepEqual(sizes, tt.sizes) {
+			t.Errorf("Batches of %d = %v, want %v", tt.size, sizes, tt.sizes)
+		}
+	}
+	if b := Batches(nil, 2); len(b) != 0 {
+		t.Errorf("Batches of no items = %v, want none", b)
+	}
+}
+
+func TestGetWholeList(t *testing.T) {
+	c, srv := newClient(t)
+	srv.PageSize = 1
+	testID := newList(t, c)
+	if _, err := c.AddItems(testID, MovieItem(550), MovieItem(238), MovieItem(680)); err != nil {
+		t.Fatalf("failed to add items: %v", err)
+	}
+	commented := MovieItem(680)
+	commented.Comment = "Watch it"
+	if _, err := c.UpdateItems(testID, commented); err != nil {
+		t.Fatal(err)
+	}
+	l, err := c.GetWholeList(testID, ListSortTitleAsc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for _, r := range l.Results {
+		keys = append(keys, r.Key())
+	}
+	if strings.Join(keys, ",") != "movie/550,movie/680,movie/238" {
+		t.Errorf("got %v, want the items sorted by title", keys)
+	}
+	if l.Page != 1 || l.TotalPages != 1 {
+		t.Errorf("got page %d of %d, want 1 of 1", l.Page, l.TotalPages)
+	}
+	if got := l.Comment(l.Results[1]); got != "Watch it" {
+		t.Errorf("comment of the last page = %q, want %q", got, "Watch it")
+	}
+}
+
+func TestWalkListStop(t *testing.T) {
+	c, srv := newClient(t)
+	srv.PageSize = 1
+	testID := newList(t, c)
+	_, err := c.AddItems(testID, MovieItem(238), MovieItem(240), MovieItem(278))
+	if err != nil {
+		t.Fatalf("failed to add items: %v", err)
+	}
+	var keys []string
+	err = c.WalkList(testID, func(r Result) bool {
+		keys = append(keys, r.Key())
+		return r.ID != 240
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 || keys[1] != "movie/240" {
+		t.Errorf("got %v, want the walk to stop at movie/240", keys)
+	}
+}
+
+func TestGetSortedList(t *testing.T) {
+	c, _ := newClient(t)
+	testID := newList(t, c)
+	_, err := c.AddItems(testID, MovieItem(550), MovieItem(238), MovieItem(13))
+	if err != nil {
+		t.Fatalf("failed to add items: %v", err)
+	}
+	tests := []struct {
+		sortBy string
+		want   []int
+	}{
+		{"", []int{550, 238, 13}},
+		{ListSortOriginalDesc, []int{13, 238, 550}},
+		{ListSortTitleAsc, []int{550, 13, 238}},
+		{ListSortReleaseDateAsc, []int{238, 13, 550}},
+	}
+	for _, tt := range tests {
+		l, err := c.GetSortedList(testID, 1, tt.sortBy)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.sortBy, err)
+		}
+		var got []int
+		for _, r := range l.Results {
+			got = append(got, r.ID)
+		}
+		if joinInts(got, ",") != joinInts(tt.want, ",") {
+			t.Errorf("%q: got %v, want %v", tt.sortBy, got, tt.want)
+		}
+	}
+}
+
+func TestUpdateList(t *testing.T) {
+	c, srv := newClient(t)
+	testID := newList(t, c)
+	name, public := "Renamed", true
+	err := c.UpdateList(testID, &ListUpdate{Name: &name, Public: &public})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, _ := srv.List(testID)
+	if l.Name != "Renamed" || !l.Public || l.ISO != "en" {
+		t.Errorf("unexpected list: %+v", l)
+	}
+	empty := ""
+	if err := c.UpdateList(testID, &ListUpdate{Name: &empty}); err == nil {
+		t.Error("update with empty name did not fail")
+	}
+}
+
+func TestClearAndDeleteList(t *testing.T) {
+	c, srv := newClient(t)
+	testID := newList(t, c)
+	if _, err := c.AddItems(testID, MovieItem(550), TVItem(1396)); err != nil {
+		t.Fatalf("failed to add items: %v", err)
+	}
+	if err := c.ClearList(testID); err != nil {
+		t.Fatal(err)
+	}
+	if l, ok := srv.List(testID); !ok || len(l.Items) != 0 {
+		t.Errorf("list not cleared: %+v", l)
+	}
+	if err := c.DeleteList(testID); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := srv.List(testID); ok {
+		t.Error("list not deleted")
+	}
+	if err := c.DeleteList(testID); err == nil {
+		t.Error("delete of deleted list did not fail")
+	}
+}
+
 func TestAddItem(t *testing.T) {
-	c := newClient()
-	resp, err := c.AddItems(testID, godfatherID)
+	c, _ := newClient(t)
+	testID := newList(t, c)
+	resp, err := c.AddItems(testID, MovieItem(godfatherID))
 	if err != nil {
 		t.Errorf("failed to add movie: %v", err)
 		return
@@ -83,9 +304,50 @@ func TestAddItem(t *testing.T) {
 	}
 }
 
+func TestUpdateItems(t *testing.T) {
+	c, _ := newClient(t)
+	testID := newList(t, c)
+	if _, err := c.AddItems(testID, MovieItem(550), TVItem(1396)); err != nil {
+		t.Fatalf("failed to add items: %v", err)
+	}
+	item := TVItem(1396)
+	item.Comment = "watch with subtitles"
+	resp, err := c.UpdateItems(testID, item, MovieItem(238))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Results[0].Success || resp.Results[1].Success {
+		t.Errorf("unexpected results: %+v", resp.Results)
+	}
+	l, err := c.GetList(testID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := l.Comment(l.Results[1]); got != "watch with subtitles" {
+		t.Errorf("tv comment = %q, want %q", got, "watch with subtitles")
+	}
+	if got := l.Comment(l.Results[0]); got != "" {
+		t.Errorf("movie comment = %q, want none", got)
+	}
+	// An empty comment clears it.
+	if _, err := c.UpdateItems(testID, TVItem(1396)); err != nil {
+		t.Fatal(err)
+	}
+	if l, err = c.GetList(testID, 1); err != nil {
+		t.Fatal(err)
+	}
+	if got := l.Comment(l.Results[1]); got != "" {
+		t.Errorf("tv comment = %q after clearing it, want none", got)
+	}
+}
+
 func TestDeleteItem(t *testing.T) {
-	c := newClient()
-	resp, err := c.DeleteItems(testID, godfatherID)
+	c, _ := newClient(t)
+	testID := newList(t, c)
+	if _, err := c.AddItems(testID, MovieItem(godfatherID)); err != nil {
+		t.Fatalf("failed to add movie: %v", err)
+	}
+	resp, err := c.DeleteItems(testID, MovieItem(godfatherID))
 	if err != nil {
 		t.Errorf("failed to delete movie: %v", err)
 		return
@@ -95,3 +357,559 @@ func TestDeleteItem(t *testing.T) {
 		t.Errorf("failed to remove godfather movie")
 	}
 }
+
+func TestGetMovieFake(t *testing.T) {
+	c, _ := newClient(t)
+	movie, err := c.GetMovie(godfatherID)
+	if err != nil {
+		t.Fatalf("failed to get movie: %v", err)
+	}
+	if movie.Title != "The Godfather" || movie.Runtime != 175 || len(movie.Genres) != 2 {
+		t.Errorf("unexpected movie: %+v", movie)
+	}
+	if _, err := c.GetMovie(1); err == nil {
+		t.Errorf("expected error for unknown movie")
+	}
+}
+
+func TestWithLocale(t *testing.T) {
+	c, srv := newClient(t)
+	pt := c.WithLocale("pt-BR", "BR")
+	if pt.LanguageCode() != "pt" || c.LanguageCode() != "en" {
+		t.Errorf("LanguageCode = %q and %q, want pt and en", pt.LanguageCode(), c.LanguageCode())
+	}
+	movie, err := pt.GetMovie(godfatherID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if movie.DisplayTitle() != "O Poderoso Chefão" {
+		t.Errorf("got title %q, want the translation", movie.DisplayTitle())
+	}
+	// Fight Club has no translation in the fake.
+	movie, err = pt.GetMovie(550)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if movie.Title != "" || movie.DisplayTitle() != "Fight Club" {
+		t.Errorf("got title %q, display %q, want the original", movie.Title, movie.DisplayTitle())
+	}
+	id, err := pt.CreateList("filmes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l, _ := srv.List(id); l.ISO != "pt" {
+		t.Errorf("list language = %q, want pt", l.ISO)
+	}
+}
+
+func TestLocaleParams(t *testing.T) {
+	var got url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.Query()
+		io.WriteString(w, `{"results":[]}`)
+	}))
+	defer srv.Close()
+	c := New(srv.URL, "token", srv.Client()).WithLocale("pt-BR", "BR")
+	if _, err := c.SearchMovies(&SearchOptions{Query: "a", Language: "es-ES"}); err != nil {
+		t.Fatal(err)
+	}
+	if got.Get("language") != "es-ES" || got.Get("region") != "BR" {
+		t.Errorf("got params %v, want the search language and the client region", got)
+	}
+	if _, err := c.GetVideos(MediaMovie, godfatherID); err != nil {
+		t.Fatal(err)
+	}
+	if got.Get("include_video_language") != "pt,en,null" {
+		t.Errorf("include_video_language = %q", got.Get("include_video_language"))
+	}
+}
+
+// newFakeClient returns a client to a test server that
+// responds body to every request to path.
+func newFakeClient(t *testing.T, path, body string) (*Client, func()) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, body)
+	}))
+	return New(srv.URL, "token", srv.Client()), srv.Close
+}
+
+func TestGetMovie(t *testing.T) {
+	body := `{"id":238,"title":"The Godfather","runtime":175,"genres":[{"id":18,"name":"Drama"}]}`
+	c, done := newFakeClient(t, "/movie/238", body)
+	defer done()
+	movie, err := c.GetMovie(godfatherID)
+	if err != nil {
+		t.Errorf("failed to get movie: %v", err)
+		return
+	}
+	if movie.ID != godfatherID || movie.Runtime != 175 || len(movie.Genres) != 1 {
+		t.Errorf("unexpected movie: %+v", movie)
+	}
+}
+
+func TestRelatedMovies(t *testing.T) {
+	c, _ := newClient(t)
+	recommended, err := c.GetRecommendations(godfatherID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	similar, err := c.GetSimilar(godfatherID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recommended.Results) == 0 || len(similar.Results) <= len(recommended.Results) {
+		t.Fatalf("got %d recommended and %d similar movies", len(recommended.Results), len(similar.Results))
+	}
+	for _, r := range append(recommended.Results, similar.Results...) {
+		if r.ID == godfatherID {
+			t.Error("movie is related to itself")
+		}
+	}
+}
+
+func TestGetVideos(t *testing.T) {
+	body := `{"id":238,"results":[
+		{"iso_639_1":"en","key":"a","site":"YouTube","size":720,"type":"Trailer","official":true},
+		{"iso_639_1":"en","key":"b","site":"YouTube","size":1080,"type":"Teaser","official":true}
+	]}`
+	c, done := newFakeClient(t, "/movie/238/videos", body)
+	defer done()
+	videos, err := c.GetVideos(MediaMovie, godfatherID)
+	if err != nil {
+		t.Errorf("failed to get videos: %v", err)
+		return
+	}
+	if len(videos) != 2 {
+		t.Errorf("got %d videos, want 2", len(videos))
+	}
+}
+
+func TestBestTrailer(t *testing.T) {
+	videos := []Video{
+		{ISO639: "en", Key: "teaser", Site: "YouTube", Size: 2160, Type: "Teaser", Official: true},
+		{ISO639: "en", Key: "fan", Site: "YouTube", Size: 2160, Type: "Trailer"},
+		{ISO639: "en", Key: "old", Site: "YouTube", Size: 720, Type: "Trailer", Official: true, PublishedAt: "2010-01-01"},
+		{ISO639: "en", Key: "new", Site: "YouTube", Size: 720, Type: "Trailer", Official: true, PublishedAt: "2020-01-01"},
+		{ISO639: "pt", Key: "pt", Site: "YouTube", Size: 480, Type: "Trailer", Official: true},
+		{ISO639: "de", Key: "de", Site: "Unknown", Size: 1080, Type: "Trailer", Official: true},
+	}
+	tests := []struct {
+		lang string
+		key  string
+	}{
+		{"en", "new"},
+		{"pt", "pt"},
+		{"de", "new"},
+	}
+	for _, tt := range tests {
+		v := BestTrailer(videos, tt.lang)
+		if v == nil || v.Key != tt.key {
+			t.Errorf("BestTrailer(%q) = %+v, want key %q", tt.lang, v, tt.key)
+		}
+	}
+	if v := BestTrailer(nil, "en"); v != nil {
+		t.Errorf("BestTrailer(nil) = %+v, want nil", v)
+	}
+}
+
+func TestGetCredits(t *testing.T) {
+	body := `{"id":238,"cast":[{"id":3084,"name":"Marlon Brando","character":"Don Vito Corleone"}],
+		"crew":[{"id":1776,"name":"Francis Ford Coppola","job":"Director"},{"id":1,"name":"Someone","job":"Producer"}]}`
+	c, done := newFakeClient(t, "/movie/238/credits", body)
+	defer done()
+	credits, err := c.GetCredits(MediaMovie, godfatherID)
+	if err != nil {
+		t.Errorf("failed to get credits: %v", err)
+		return
+	}
+	if len(credits.Cast) != 1 || credits.Cast[0].Character != "Don Vito Corleone" {
+		t.Errorf("unexpected cast: %+v", credits.Cast)
+	}
+	directors := credits.Directors()
+	if len(directors) != 1 || directors[0].ID != 1776 {
+		t.Errorf("unexpected directors: %+v", directors)
+	}
+}
+
+func TestGetPersonMovieCredits(t *testing.T) {
+	body := `{"id":1776,"cast":[],"crew":[{"id":238,"title":"The Godfather","job":"Director"}]}`
+	c, done := newFakeClient(t, "/person/1776/movie_credits", body)
+	defer done()
+	credits, err := c.GetPersonMovieCredits(1776)
+	if err != nil {
+		t.Errorf("failed to get person credits: %v", err)
+		return
+	}
+	if len(credits.Crew) != 1 || credits.Crew[0].ID != godfatherID || credits.Crew[0].Job != "Director" {
+		t.Errorf("unexpected crew: %+v", credits.Crew)
+	}
+}
+
+func TestSearchPerson(t *testing.T) {
+	body := `{"page":1,"results":[{"id":1776,"name":"Francis Ford Coppola"}],"total_pages":1}`
+	c, done := newFakeClient(t, "/search/person", body)
+	defer done()
+	res, err := c.SearchPerson("Coppola")
+	if err != nil {
+		t.Errorf("failed to search person: %v", err)
+		return
+	}
+	if len(res) != 1 || res[0].Name != "Francis Ford Coppola" {
+		t.Errorf("unexpected results: %+v", res)
+	}
+}
+
+func TestSearchOptionsValues(t *testing.T) {
+	opts := &SearchOptions{
+		Query:        "GodFather",
+		Page:         2,
+		Year:         1972,
+		Language:     "pt-BR",
+		Region:       "BR",
+		IncludeAdult: true,
+	}
+	got := opts.Values().Encode()
+	want := "include_adult=true&language=pt-BR&page=2&query=GodFather&region=BR&year=1972"
+	if got != want {
+		t.Errorf("Values() = %q, want %q", got, want)
+	}
+	got = (&SearchOptions{Query: "GodFather", Page: 1}).Values().Encode()
+	if want = "query=GodFather"; got != want {
+		t.Errorf("Values() = %q, want %q", got, want)
+	}
+}
+
+func TestSearchMovies(t *testing.T) {
+	body := `{"page":2,"results":[{"id":238,"title":"The Godfather"}],"total_results":21,"total_pages":3}`
+	c, done := newFakeClient(t, "/search/movie", body)
+	defer done()
+	res, err := c.SearchMovies(&SearchOptions{Query: "GodFather", Page: 2})
+	if err != nil {
+		t.Errorf("failed to search movies: %v", err)
+		return
+	}
+	if res.Page != 2 || res.TotalPages != 3 || len(res.Results) != 1 {
+		t.Errorf("unexpected response: %+v", res)
+	}
+	if _, err := c.SearchMovies(&SearchOptions{}); err == nil {
+		t.Errorf("expected error with empty query")
+	}
+}
+
+func TestFind(t *testing.T) {
+	c, _ := newClient(t)
+	res, err := c.Find("tt0068646")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.MovieResults) != 1 || res.MovieResults[0].ID != godfatherID || len(res.TVResults) != 0 {
+		t.Errorf("unexpected response: %+v", res)
+	}
+	res, err = c.Find("tt0903747")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.TVResults) != 1 || res.TVResults[0].Key() != "tv/1396" {
+		t.Errorf("unexpected response: %+v", res)
+	}
+	res, err = c.Find("tt0000000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.MovieResults)+len(res.TVResults) != 0 {
+		t.Errorf("found unknown IMDb ID: %+v", res)
+	}
+	// The fake filters the searches by year.
+	movies, err := c.SearchMovies(&SearchOptions{Query: "the godfather", Year: 1972})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(movies.Results) != 1 || movies.Results[0].ID != godfatherID {
+		t.Errorf("got %+v, want The Godfather only", movies.Results)
+	}
+}
+
+func TestDiscoverOptionsValues(t *testing.T) {
+	opts := &DiscoverOptions{
+		SortBy:         SortVoteAverageDesc,
+		ReleaseDateGTE: "1970-01-01",
+		VoteAverageGTE: 7.5,
+		VoteCountGTE:   100,
+		RuntimeLTE:     120,
+		Genres:         []int{18, 80},
+		Cast:           []int{3084},
+		WatchProviders: []int{8, 337},
+		WatchRegion:    "BR",
+	}
+	params := opts.Values()
+	tests := map[string]string{
+		"sort_by":                  "vote_average.desc",
+		"primary_release_date.gte": "1970-01-01",
+		"vote_average.gte":         "7.5",
+		"vote_count.gte":           "100",
+		"with_runtime.lte":         "120",
+		"with_genres":              "18,80",
+		"with_cast":                "3084",
+		"with_watch_providers":     "8|337",
+		"watch_region":             "BR",
+	}
+	for key, want := range tests {
+		if got := params.Get(key); got != want {
+			t.Errorf("param %s = %q, want %q", key, got, want)
+		}
+	}
+	if len(params) != len(tests) {
+		t.Errorf("got %d params, want %d: %v", len(params), len(tests), params)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	body := `{"page":1,"results":[{"id":238,"title":"The Godfather"}],"total_results":1,"total_pages":1}`
+	c, done := newFakeClient(t, "/discover/movie", body)
+	defer done()
+	res, err := c.Discover(&DiscoverOptions{Genres: []int{18}})
+	if err != nil {
+		t.Errorf("failed to discover: %v", err)
+		return
+	}
+	if len(res.Results) != 1 {
+		t.Errorf("got %d results, want 1", len(res.Results))
+	}
+	if _, err := c.Discover(&DiscoverOptions{WatchProviders: []int{8}}); err == nil {
+		t.Errorf("expected error with providers without region")
+	}
+}
+
+func TestResult(t *testing.T) {
+	movie := Result{ID: godfatherID, Title: "The Godfather", ReleaseDate: "1972-03-14"}
+	show := Result{ID: 1396, MediaType: MediaTV, Name: "Breaking Bad", FirstAirDate: "2008-01-20"}
+	if movie.Key() != "movie/238" || movie.DisplayTitle() != "The Godfather" || movie.Date() != "1972-03-14" {
+		t.Errorf("unexpected movie: %s %s %s", movie.Key(), movie.DisplayTitle(), movie.Date())
+	}
+	if show.Key() != "tv/1396" || show.DisplayTitle() != "Breaking Bad" || show.Date() != "2008-01-20" {
+		t.Errorf("unexpected show: %s %s %s", show.Key(), show.DisplayTitle(), show.Date())
+	}
+	untranslated := Result{ID: 598, OriginalTitle: "Cidade de Deus"}
+	if untranslated.DisplayTitle() != "Cidade de Deus" {
+		t.Errorf("DisplayTitle() = %q, want the original title", untranslated.DisplayTitle())
+	}
+	if show.Item() != TVItem(1396) {
+		t.Errorf("Item() = %+v, want %+v", show.Item(), TVItem(1396))
+	}
+}
+
+func TestMarshalItems(t *testing.T) {
+	b, err := marshalItems([]Item{MovieItem(godfatherID), TVItem(1396)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"items":[{"media_type":"movie","media_id":238},{"media_type":"tv","media_id":1396}]}`
+	if string(b) != want {
+		t.Errorf("marshalItems = %s, want %s", b, want)
+	}
+	b, err = marshalUpdates([]Item{MovieItem(godfatherID)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = `{"items":[{"media_type":"movie","media_id":238,"comment":""}]}`
+	if string(b) != want {
+		t.Errorf("marshalUpdates = %s, want %s", b, want)
+	}
+}
+
+func TestDiscoverTV(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/discover/tv" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if q.Get("first_air_date_year") != "2008" || q.Get("sort_by") != "first_air_date.desc" {
+			t.Errorf("unexpected params: %v", q)
+		}
+		io.WriteString(w, `{"page":1,"results":[{"id":1396,"name":"Breaking Bad"}],"total_pages":1}`)
+	}))
+	defer srv.Close()
+	c := New(srv.URL, "token", srv.Client())
+	res, err := c.DiscoverTV(&DiscoverOptions{Year: 2008, SortBy: SortReleaseDateDesc})
+	if err != nil {
+		t.Errorf("failed to discover tv: %v", err)
+		return
+	}
+	if len(res.Results) != 1 || res.Results[0].Type() != MediaTV {
+		t.Errorf("unexpected results: %+v", res.Results)
+	}
+	if _, err := c.DiscoverTV(&DiscoverOptions{Cast: []int{1}}); err == nil {
+		t.Errorf("expected error with cast filter")
+	}
+}
+
+func TestGetTV(t *testing.T) {
+	body := `{"id":1396,"name":"Breaking Bad","number_of_seasons":5,
+		"seasons":[{"season_number":1,"episode_count":7}],"last_episode_to_air":{"season_number":5,"episode_number":16}}`
+	c, done := newFakeClient(t, "/tv/1396", body)
+	defer done()
+	show, err := c.GetTV(1396)
+	if err != nil {
+		t.Errorf("failed to get tv show: %v", err)
+		return
+	}
+	if show.NumberOfSeasons != 5 || len(show.Seasons) != 1 || show.LastEpisodeToAir.EpisodeNumber != 16 {
+		t.Errorf("unexpected show: %+v", show)
+	}
+}
+
+func TestGetSeason(t *testing.T) {
+	body := `{"id":3572,"season_number":1,"episodes":[{"id":62085,"episode_number":1,"season_number":1,"air_date":"2008-01-20"}]}`
+	c, done := newFakeClient(t, "/tv/1396/season/1", body)
+	defer done()
+	season, err := c.GetSeason(1396, 1)
+	if err != nil {
+		t.Errorf("failed to get season: %v", err)
+		return
+	}
+	if len(season.Episodes) != 1 || season.Episodes[0].EpisodeNumber != 1 {
+		t.Errorf("unexpected season: %+v", season)
+	}
+}
+
+func TestGetWatchProviders(t *testing.T) {
+	body := `{"id":238,"results":{"BR":{"link":"https://tmdb.org","flatrate":[{"provider_id":8,"provider_name":"Netflix"}],
+		"ads":[{"provider_id":300,"provider_name":"Pluto TV"}],"rent":[{"provider_id":2,"provider_name":"Apple TV"}]}}}`
+	c, done := newFakeClient(t, "/movie/238/watch/providers", body)
+	defer done()
+	providers, err := c.GetWatchProviders(MediaMovie, godfatherID)
+	if err != nil {
+		t.Errorf("failed to get watch providers: %v", err)
+		return
+	}
+	br, ok := providers["BR"]
+	if !ok {
+		t.Fatalf("no providers in BR: %+v", providers)
+	}
+	streaming := br.Streaming()
+	if len(streaming) != 2 || streaming[0].ProviderID != 8 || streaming[1].ProviderID != 300 {
+		t.Errorf("unexpected streaming providers: %+v", streaming)
+	}
+}
+
+func TestAccessToken(t *testing.T) {
+	c, srv := newClient(t)
+	requestToken, err := c.CreateRequestToken("http://localhost/tmdb/callback")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := AuthApproveURL + "?request_token=" + requestToken
+	if got := ApproveURL(requestToken); got != want {
+		t.Errorf("ApproveURL = %q, want %q", got, want)
+	}
+	token, err := c.CreateAccessToken(requestToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, ok := srv.AccessToken(token.AccessToken); !ok || id != token.AccountID {
+		t.Errorf("access token %+v not created", token)
+	}
+	if _, err := c.CreateAccessToken(requestToken); err == nil {
+		t.Error("request token used twice")
+	}
+	// The user client creates lists in the user account.
+	listID, err := c.WithToken(token.AccessToken).CreateList("user list")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l, _ := srv.List(listID); l.Owner != token.AccessToken {
+		t.Errorf("list owner = %q, want %q", l.Owner, token.AccessToken)
+	}
+	if err := c.DeleteList(listID); err == nil {
+		t.Error("app token deleted the user list")
+	}
+	if err := c.DeleteAccessToken(token.AccessToken); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DeleteAccessToken(token.AccessToken); err == nil {
+		t.Error("access token deleted twice")
+	}
+}
+
+func TestNewV3URL(t *testing.T) {
+	c := New(DefaultURL, "token", nil)
+	if c.v3URL != "https://api.themoviedb.org/3" {
+		t.Errorf("v3URL = %q", c.v3URL)
+	}
+	c = New("http://localhost:8080", "token", nil)
+	if c.v3URL != "http://localhost:8080" {
+		t.Errorf("v3URL = %q", c.v3URL)
+	}
+}
+
+func TestV3Endpoints(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, "{}")
+	}))
+	defer srv.Close()
+	c := New(srv.URL+"/4", "token", srv.Client())
+	c.GetMovie(550)
+	c.SearchMovies(&SearchOptions{Query: "fight club"})
+	c.GetList(1, 1)
+	want := []string{"/3/movie/550", "/3/search/movie", "/4/list/1"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("requested %v, want %v", paths, want)
+	}
+}
+
+func TestAccountMovies(t *testing.T) {
+	c, srv := newClient(t)
+	srv.PageSize = 1
+	requestToken, err := c.CreateRequestToken("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := c.CreateAccessToken(requestToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := c.WithToken(token.AccessToken)
+	srv.AddAccountMovie(token.AccountID, AccountRated, 550, 8)
+	srv.AddAccountMovie(token.AccountID, AccountWatchlist, 238, 0)
+	srv.AddAccountMovie(token.AccountID, AccountWatchlist, 13, 0)
+
+	if err := user.RateMovie(238, 9.5); err != nil {
+		t.Fatal(err)
+	}
+	rated, err := user.GetAllAccountMovies(token.AccountID, AccountRated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rated) != 2 || rated[0].ID != 550 || rated[1].ID != 238 {
+		t.Fatalf("unexpected rated movies: %+v", rated)
+	}
+	if r := rated[1].AccountRating; r == nil || r.Value != 9.5 {
+		t.Errorf("rating of 238 = %+v, want 9.5", r)
+	}
+	watchlist, err := user.GetAllAccountMovies(token.AccountID, AccountWatchlist)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(watchlist) != 2 || watchlist[1].Key() != "movie/13" {
+		t.Errorf("unexpected watchlist: %+v", watchlist)
+	}
+	if _, err := user.GetAccountMovies(token.AccountID, "seen", 1); err == nil {
+		t.Error("invalid kind did not fail")
+	}
+	for _, v := range []float64{0, 10.5, 7.3} {
+		if err := user.RateMovie(550, v); err == nil {
+			t.Errorf("rating %v did not fail", v)
+		}
+	}
+	if err := c.RateMovie(550, 5); err == nil {
+		t.Error("app token rated a movie")
+	}
+}
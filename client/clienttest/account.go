@@ -0,0 +1,131 @@
+package clienttest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// Kinds of account movies.
+const (
+	rated     = "rated"
+	favorites = "favorites"
+	watchlist = "watchlist"
+)
+
+// AddAccountMovie adds the movie with ID id to the rated, favorites
+// or watchlist movies of the account with ID accountID, the rating
+// is used only by rated movies.
+func (s *Server) AddAccountMovie(accountID, kind string, id int, rating float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addAccountMovie(accountID, kind, id)
+	if kind == rated {
+		s.rate(accountID, id, rating)
+	}
+}
+
+// Rating returns the rating of the movie with ID
+// id by the account with ID accountID.
+func (s *Server) Rating(accountID string, id int) (float64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.ratings[accountID][id]
+	return v, ok
+}
+
+func (s *Server) addAccountMovie(accountID, kind string, id int) {
+	kinds, ok := s.accountMovies[accountID]
+	if !ok {
+		kinds = make(map[string][]int)
+		s.accountMovies[accountID] = kinds
+	}
+	for _, m := range kinds[kind] {
+		if m == id {
+			return
+		}
+	}
+	kinds[kind] = append(kinds[kind], id)
+}
+
+func (s *Server) rate(accountID string, id int, value float64) {
+	if s.ratings[accountID] == nil {
+		s.ratings[accountID] = make(map[int]float64)
+	}
+	s.ratings[accountID][id] = value
+}
+
+type accountRating struct {
+	Value     float64 `json:"value"`
+	CreatedAt string  `json:"created_at"`
+}
+
+type accountMovie struct {
+	*Media
+	AccountRating *accountRating `json:"account_rating,omitempty"`
+}
+
+type accountMoviesPage struct {
+	Page         int             `json:"page"`
+	Results      []*accountMovie `json:"results"`
+	TotalPages   int             `json:"total_pages"`
+	TotalResults int             `json:"total_results"`
+}
+
+// getAccountMovies serves a page of the movies of an account,
+// only the access token of the account can read them.
+func (s *Server) getAccountMovies(w http.ResponseWriter, r *http.Request, accountID, kind string) {
+	if s.accessTokens[token(r)] != accountID {
+		writeError(w, http.StatusUnauthorized, 3, "Authentication failed: You do not have permissions to access the service.")
+		return
+	}
+	if kind != rated && kind != favorites && kind != watchlist {
+		writeNotFound(w)
+		return
+	}
+	var media []*Media
+	for _, id := range s.accountMovies[accountID][kind] {
+		if m, ok := s.media[key("movie", id)]; ok {
+			media = append(media, m)
+		}
+	}
+	p := s.paginate(r, media)
+	resp := accountMoviesPage{
+		Page:         p.Page,
+		Results:      []*accountMovie{},
+		TotalPages:   p.TotalPages,
+		TotalResults: p.TotalResults,
+	}
+	for _, m := range p.Results {
+		am := &accountMovie{Media: m}
+		if kind == rated {
+			am.AccountRating = &accountRating{Value: s.ratings[accountID][m.ID], CreatedAt: "2020-01-01T00:00:00.000Z"}
+		}
+		resp.Results = append(resp.Results, am)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// rateMovie rates a movie by the account of the access token.
+func (s *Server) rateMovie(w http.ResponseWriter, r *http.Request, strID string) {
+	accountID, ok := s.accessTokens[token(r)]
+	if !ok {
+		writeError(w, http.StatusUnauthorized, 3, "Authentication failed: You do not have permissions to access the service.")
+		return
+	}
+	id, _ := strconv.Atoi(strID)
+	if _, ok := s.media[key("movie", id)]; !ok {
+		writeNotFound(w)
+		return
+	}
+	req := new(struct {
+		Value float64 `json:"value"`
+	})
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil || req.Value < 0.5 || req.Value > 10 {
+		writeError(w, http.StatusBadRequest, 18, "Value too low: Value must be greater than 0.0.")
+		return
+	}
+	s.addAccountMovie(accountID, rated, id)
+	s.rate(accountID, id, req.Value)
+	writeJSON(w, http.StatusCreated, statusResp{StatusCode: 1, StatusMessage: "Success.", Success: true})
+}
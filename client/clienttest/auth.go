@@ -0,0 +1,76 @@
+package clienttest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// AccessToken reports if token is a valid access token
+// and returns the ID of its account.
+func (s *Server) AccessToken(token string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, ok := s.accessTokens[token]
+	return id, ok
+}
+
+type requestTokenResp struct {
+	statusResp
+	RequestToken string `json:"request_token"`
+}
+
+func (s *Server) createRequestToken(w http.ResponseWriter, r *http.Request) {
+	s.nextToken++
+	rt := "request-token-" + strconv.Itoa(s.nextToken)
+	s.requestTokens[rt] = true
+	writeJSON(w, http.StatusOK, requestTokenResp{
+		statusResp:   statusResp{StatusCode: 1, StatusMessage: "Success.", Success: true},
+		RequestToken: rt,
+	})
+}
+
+type accessTokenReq struct {
+	RequestToken string `json:"request_token"`
+	AccessToken  string `json:"access_token"`
+}
+
+type accessTokenResp struct {
+	statusResp
+	AccessToken string `json:"access_token"`
+	AccountID   string `json:"account_id"`
+}
+
+// createAccessToken exchanges a request token for an access
+// token, each request token can be used once.
+func (s *Server) createAccessToken(w http.ResponseWriter, r *http.Request) {
+	req := new(accessTokenReq)
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil || !s.requestTokens[req.RequestToken] {
+		writeError(w, http.StatusUnauthorized, 36, "This token hasn't been granted write permission by the user.")
+		return
+	}
+	delete(s.requestTokens, req.RequestToken)
+	s.nextToken++
+	n := strconv.Itoa(s.nextToken)
+	resp := accessTokenResp{
+		statusResp:  statusResp{StatusCode: 1, StatusMessage: "Success.", Success: true},
+		AccessToken: "access-token-" + n,
+		AccountID:   "account-" + n,
+	}
+	s.accessTokens[resp.AccessToken] = resp.AccountID
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) deleteAccessToken(w http.ResponseWriter, r *http.Request) {
+	req := new(accessTokenReq)
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, 5, "Invalid parameters: Your request parameters are incorrect.")
+		return
+	}
+	if _, ok := s.accessTokens[req.AccessToken]; !ok {
+		writeError(w, http.StatusUnauthorized, 35, "Invalid token.")
+		return
+	}
+	delete(s.accessTokens, req.AccessToken)
+	writeJSON(w, http.StatusOK, statusResp{StatusCode: 13, StatusMessage: "The item/record was deleted successfully.", Success: true})
+}
@@ -0,0 +1,733 @@
+// Package clienttest implements an in-process fake of the TMDB API
+// used by the client package, so tests and local development run
+// without network and without a real TMDB account.
+//
+// The fake serves lists CRUD with pagination and sorting, movie and tv
+// search, find by IMDb ID, discover, genres, details and movie
+// recommendations, seeded from Fixtures. It does not import the client
+// package, so it can be used by client tests.
+//
+// The v4 authentication is simplified, request tokens are approved
+// when created, so they can become access tokens right away.
+//
+// Recorder records real TMDB interactions into golden files and
+// replays them, to keep the client types in sync with the real API.
+package clienttest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Token is an API token accepted by the fake, the fake accepts
+// any non empty bearer token.
+const Token = "clienttest-token"
+
+// DefaultPageSize is the default number of results in each page.
+const DefaultPageSize = 20
+
+// List is a TMDB list stored by the fake.
+type List struct {
+	ID          int
+	Name        string
+	Description string
+	ISO         string
+	Public      bool
+	// Owner is the token that created the list, only
+	// requests with the same token can access it.
+	Owner string
+	// SortBy is the default sort order of the items,
+	// empty means "original_order.asc".
+	SortBy string
+	// Items are the keys of the list items,
+	// e.g. "movie/238", in the order they were added.
+	Items []string
+	// Comments maps the item key to its comment.
+	Comments map[string]string
+}
+
+// Server is a fake TMDB API server.
+type Server struct {
+	*httptest.Server
+	// PageSize is the number of results in each page of
+	// lists, searches and discovers.
+	PageSize int
+
+	mu         sync.Mutex
+	media      map[string]*Media
+	lists      map[int]*List
+	nextListID int
+	// requestTokens are the request tokens created, the
+	// fake approves them on creation.
+	requestTokens map[string]bool
+	// accessTokens maps the access tokens to the account IDs.
+	accessTokens map[string]string
+	nextToken    int
+	// accountMovies maps the account ID and the kind,
+	// e.g. "rated", to the IDs of the account movies.
+	accountMovies map[string]map[string][]int
+	// ratings maps the account ID to its movie ratings.
+	ratings map[string]map[int]float64
+}
+
+// NewServer starts a fake TMDB API server seeded with Fixtures.
+// The caller should call Close when finished.
+func NewServer() *Server {
+	s := &Server{
+		PageSize:   DefaultPageSize,
+		media:      make(map[string]*Media),
+		lists:      make(map[int]*List),
+		nextListID: 1,
+
+		requestTokens: make(map[string]bool),
+		accessTokens:  make(map[string]string),
+		accountMovies: make(map[string]map[string][]int),
+		ratings:       make(map[string]map[int]float64),
+	}
+	for _, m := range Fixtures {
+		s.AddMedia(m)
+	}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// AddMedia adds a movie or tv show to the fake.
+func (s *Server) AddMedia(m Media) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.media[key(m.MediaType, m.ID)] = &m
+}
+
+// RemoveMedia removes the movie or tv show with type mediaType and
+// ID id from the fake, the lists keep it but it can not be added
+// to other lists.
+func (s *Server) RemoveMedia(mediaType string, id int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.media, key(mediaType, id))
+}
+
+// List returns a copy of the list with ID id.
+func (s *Server) List(id int) (List, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l, ok := s.lists[id]
+	if !ok {
+		return List{}, false
+	}
+	out := *l
+	out.Items = append([]string(nil), l.Items...)
+	out.Comments = make(map[string]string, len(l.Comments))
+	for k, c := range l.Comments {
+		out.Comments[k] = c
+	}
+	return out, true
+}
+
+func key(mediaType string, id int) string {
+	return mediaType + "/" + strconv.Itoa(id)
+}
+
+// ServeHTTP routes the requests to the fake endpoints.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") || len(auth) == len("Bearer ") {
+		writeError(w, http.StatusUnauthorized, 7, "Invalid API key: You must be granted a valid key.")
+		return
+	}
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case match(parts, "auth", "request_token") && r.Method == "POST":
+		s.createRequestToken(w, r)
+	case match(parts, "auth", "access_token") && r.Method == "POST":
+		s.createAccessToken(w, r)
+	case match(parts, "auth", "access_token") && r.Method == "DELETE":
+		s.deleteAccessToken(w, r)
+	case match(parts, "account", "*", "movie", "*") && r.Method == "GET":
+		s.getAccountMovies(w, r, parts[1], parts[3])
+	case match(parts, "movie", "*", "rating") && r.Method == "POST":
+		s.rateMovie(w, r, parts[1])
+	case match(parts, "list") && r.Method == "POST":
+		s.createList(w, r)
+	case match(parts, "list", "*") && r.Method == "GET":
+		s.getList(w, r, parts[1])
+	case match(parts, "list", "*") && r.Method == "PUT":
+		s.updateList(w, r, parts[1])
+	case match(parts, "list", "*") && r.Method == "DELETE":
+		s.deleteList(w, r, parts[1])
+	case match(parts, "list", "*", "clear") && r.Method == "GET":
+		s.clearList(w, r, parts[1])
+	case match(parts, "list", "*", "items") && (r.Method == "POST" || r.Method == "PUT" || r.Method == "DELETE"):
+		s.changeItems(w, r, parts[1])
+	case match(parts, "search", "*") && r.Method == "GET":
+		s.search(w, r, parts[1])
+	case match(parts, "find", "*") && r.Method == "GET":
+		s.find(w, r, parts[1])
+	case match(parts, "discover", "*") && r.Method == "GET":
+		s.discover(w, r, parts[1])
+	case match(parts, "movie", "*", "recommendations") && r.Method == "GET":
+		s.related(w, r, parts[1], 2)
+	case match(parts, "movie", "*", "similar") && r.Method == "GET":
+		s.related(w, r, parts[1], 1)
+	case match(parts, "genre", "*", "list") && r.Method == "GET":
+		s.genres(w)
+	case match(parts, "*", "*") && r.Method == "GET":
+		s.details(w, r, parts[0], parts[1])
+	default:
+		writeNotFound(w)
+	}
+}
+
+// match reports if the path parts match pattern,
+// "*" matches any part.
+func match(parts []string, pattern ...string) bool {
+	if len(parts) != len(pattern) {
+		return false
+	}
+	for i, p := range pattern {
+		if p != "*" && p != parts[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json;charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+type statusResp struct {
+	StatusCode    int    `json:"status_code"`
+	StatusMessage string `json:"status_message"`
+	Success       bool   `json:"success"`
+}
+
+func writeError(w http.ResponseWriter, status, code int, msg string) {
+	writeJSON(w, status, statusResp{StatusCode: code, StatusMessage: msg})
+}
+
+func writeNotFound(w http.ResponseWriter) {
+	writeError(w, http.StatusNotFound, 34, "The resource you requested could not be found.")
+}
+
+type createListReq struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	ISO         string `json:"iso_639_1"`
+	Public      bool   `json:"public"`
+}
+
+type createListResp struct {
+	statusResp
+	ID int `json:"id"`
+}
+
+func (s *Server) createList(w http.ResponseWriter, r *http.Request) {
+	req := new(createListReq)
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil || req.Name == "" {
+		writeError(w, http.StatusUnprocessableEntity, 5, "Invalid parameters: Your request parameters are incorrect.")
+		return
+	}
+	l := &List{
+		Owner:       token(r),
+		ID:          s.nextListID,
+		Name:        req.Name,
+		Description: req.Description,
+		ISO:         req.ISO,
+		Public:      req.Public,
+	}
+	s.nextListID++
+	s.lists[l.ID] = l
+	resp := createListResp{
+		statusResp: statusResp{StatusCode: 1, StatusMessage: "The item/record was created successfully.", Success: true},
+		ID:         l.ID,
+	}
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+// page is a page of results.
+type page struct {
+	Page         int      `json:"page"`
+	Results      []*Media `json:"results"`
+	TotalPages   int      `json:"total_pages"`
+	TotalResults int      `json:"total_results"`
+}
+
+// paginate returns the page of results requested by r.
+func (s *Server) paginate(r *http.Request, results []*Media) page {
+	n, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || n < 1 {
+		n = 1
+	}
+	size := s.PageSize
+	if size <= 0 {
+		size = DefaultPageSize
+	}
+	total := (len(results) + size - 1) / size
+	if total < 1 {
+		total = 1
+	}
+	p := page{Page: n, TotalPages: total, TotalResults: len(results), Results: []*Media{}}
+	start := (n - 1) * size
+	if start < len(results) {
+		end := start + size
+		if end > len(results) {
+			end = len(results)
+		}
+		p.Results = results[start:end]
+	}
+	return p
+}
+
+type listResp struct {
+	page
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	ISO         string `json:"iso_639_1"`
+	Public      bool   `json:"public"`
+	// Comments maps "{media type}:{id}" to the item comment.
+	Comments map[string]string `json:"comments"`
+}
+
+func (s *Server) getList(w http.ResponseWriter, r *http.Request, strID string) {
+	l, ok := s.ownedList(w, r, strID)
+	if !ok {
+		return
+	}
+	results := make([]*Media, 0, len(l.Items))
+	for _, k := range l.Items {
+		if m, ok := s.media[k]; ok {
+			results = append(results, m)
+		}
+	}
+	sortBy := r.URL.Query().Get("sort_by")
+	if sortBy == "" {
+		sortBy = l.SortBy
+	}
+	switch sortBy {
+	case "", "original_order.asc":
+	case "original_order.desc":
+		for i, j := 0, len(results)-1; i < j; i, j = i+1, j-1 {
+			results[i], results[j] = results[j], results[i]
+		}
+	default:
+		sortMedia(results, sortBy)
+	}
+	resp := listResp{
+		page:        s.paginate(r, results),
+		ID:          l.ID,
+		Name:        l.Name,
+		Description: l.Description,
+		ISO:         l.ISO,
+		Public:      l.Public,
+		Comments:    make(map[string]string),
+	}
+	for _, k := range l.Items {
+		// TMDB comment keys are "movie:550", not "movie/550".
+		resp.Comments[strings.Replace(k, "/", ":", 1)] = l.Comments[k]
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// token returns the bearer token of r.
+func token(r *http.Request) string {
+	return strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+}
+
+// ownedList returns the list with ID strID if it was created with
+// the token of r, otherwise it writes the TMDB error.
+func (s *Server) ownedList(w http.ResponseWriter, r *http.Request, strID string) (*List, bool) {
+	id, _ := strconv.Atoi(strID)
+	l, ok := s.lists[id]
+	if !ok {
+		writeNotFound(w)
+		return nil, false
+	}
+	if l.Owner != token(r) {
+		writeError(w, http.StatusUnauthorized, 3, "Authentication failed: You do not have permissions to access the service.")
+		return nil, false
+	}
+	return l, true
+}
+
+type updateListReq struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	Public      *bool   `json:"public"`
+	SortBy      *string `json:"sort_by"`
+}
+
+// updateList updates the fields of a list present in the request.
+func (s *Server) updateList(w http.ResponseWriter, r *http.Request, strID string) {
+	l, ok := s.ownedList(w, r, strID)
+	if !ok {
+		return
+	}
+	req := new(updateListReq)
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil || (req.Name != nil && *req.Name == "") {
+		writeError(w, http.StatusUnprocessableEntity, 5, "Invalid parameters: Your request parameters are incorrect.")
+		return
+	}
+	if req.Name != nil {
+		l.Name = *req.Name
+	}
+	if req.Description != nil {
+		l.Description = *req.Description
+	}
+	if req.Public != nil {
+		l.Public = *req.Public
+	}
+	if req.SortBy != nil {
+		l.SortBy = *req.SortBy
+	}
+	writeJSON(w, http.StatusCreated, statusResp{StatusCode: 12, StatusMessage: "The item/record was updated successfully.", Success: true})
+}
+
+func (s *Server) clearList(w http.ResponseWriter, r *http.Request, strID string) {
+	l, ok := s.ownedList(w, r, strID)
+	if !ok {
+		return
+	}
+	l.Items = nil
+	l.Comments = nil
+	writeJSON(w, http.StatusOK, statusResp{StatusCode: 1, StatusMessage: "Success.", Success: true})
+}
+
+func (s *Server) deleteList(w http.ResponseWriter, r *http.Request, strID string) {
+	l, ok := s.ownedList(w, r, strID)
+	if !ok {
+		return
+	}
+	delete(s.lists, l.ID)
+	writeJSON(w, http.StatusOK, statusResp{StatusCode: 13, StatusMessage: "The item/record was deleted successfully.", Success: true})
+}
+
+type item struct {
+	MediaType string `json:"media_type"`
+	MediaID   int    `json:"media_id"`
+	// Comment is nil when the request has no comment.
+	Comment *string `json:"comment,omitempty"`
+}
+
+type changeItemsReq struct {
+	Items []item `json:"items"`
+}
+
+type itemResult struct {
+	item
+	Success bool `json:"success"`
+}
+
+type changeItemsResp struct {
+	statusResp
+	Results []itemResult `json:"results"`
+}
+
+// changeItems adds (POST), updates the comments (PUT) or removes
+// (DELETE) items from a list. Like TMDB, adding an existing item
+// or an unknown media and updating or removing an item not in the
+// list fail only for that item.
+func (s *Server) changeItems(w http.ResponseWriter, r *http.Request, strID string) {
+	l, ok := s.ownedList(w, r, strID)
+	if !ok {
+		return
+	}
+	req := new(changeItemsReq)
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, 5, "Invalid parameters: Your request parameters are incorrect.")
+		return
+	}
+	resp := changeItemsResp{
+		statusResp: statusResp{StatusCode: 1, StatusMessage: "Success.", Success: true},
+	}
+	for _, it := range req.Items {
+		k := key(it.MediaType, it.MediaID)
+		i := indexOf(l.Items, k)
+		success := false
+		switch {
+		case r.Method == "POST" && i < 0 && s.media[k] != nil:
+			l.Items = append(l.Items, k)
+			success = true
+		case r.Method == "PUT" && i >= 0:
+			// Like TMDB, an item without comment keeps
+			// its comment and an empty one clears it.
+			switch {
+			case it.Comment == nil:
+			case *it.Comment == "":
+				delete(l.Comments, k)
+			default:
+				if l.Comments == nil {
+					l.Comments = make(map[string]string)
+				}
+				l.Comments[k] = *it.Comment
+			}
+			success = true
+		case r.Method == "DELETE" && i >= 0:
+			l.Items = append(l.Items[:i], l.Items[i+1:]...)
+			delete(l.Comments, k)
+			success = true
+		}
+		resp.Results = append(resp.Results, itemResult{item: it, Success: success})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func indexOf(keys []string, k string) int {
+	for i, key := range keys {
+		if key == k {
+			return i
+		}
+	}
+	return -1
+}
+
+// mediaOf returns the media of mediaType sorted by popularity.
+func (s *Server) mediaOf(mediaType string) []*Media {
+	var out []*Media
+	for _, m := range s.media {
+		if m.MediaType == mediaType {
+			out = append(out, m)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Popularity != out[j].Popularity {
+			return out[i].Popularity > out[j].Popularity
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+// search matches the query with the title, case insensitive, and
+// filters by year or first_air_date_year.
+func (s *Server) search(w http.ResponseWriter, r *http.Request, mediaType string) {
+	q := r.URL.Query()
+	query := strings.ToLower(q.Get("query"))
+	if query == "" {
+		writeError(w, http.StatusUnprocessableEntity, 5, "query must be provided")
+		return
+	}
+	year := q.Get("year") + q.Get("first_air_date_year")
+	var results []*Media
+	for _, m := range s.mediaOf(mediaType) {
+		date := m.ReleaseDate + m.FirstAirDate
+		if strings.Contains(strings.ToLower(m.title()), query) && strings.HasPrefix(date, year) {
+			results = append(results, m)
+		}
+	}
+	writeJSON(w, http.StatusOK, s.paginate(r, results))
+}
+
+type findResp struct {
+	MovieResults []*Media `json:"movie_results"`
+	TVResults    []*Media `json:"tv_results"`
+}
+
+// find finds the movies and tv shows by their IMDb ID,
+// only the external_source imdb_id is supported.
+func (s *Server) find(w http.ResponseWriter, r *http.Request, imdbID string) {
+	if r.URL.Query().Get("external_source") != "imdb_id" {
+		writeError(w, http.StatusUnprocessableEntity, 5, "Invalid parameters: Your request parameters are incorrect.")
+		return
+	}
+	resp := findResp{MovieResults: []*Media{}, TVResults: []*Media{}}
+	for _, m := range s.media {
+		switch {
+		case m.ImdbID != imdbID:
+		case m.MediaType == "tv":
+			resp.TVResults = append(resp.TVResults, m)
+		default:
+			resp.MovieResults = append(resp.MovieResults, m)
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// discover filters by with_genres, primary_release_year,
+// first_air_date_year, vote_average.gte and with_original_language
+// and sorts by sort_by, by default popularity.desc.
+func (s *Server) discover(w http.ResponseWriter, r *http.Request, mediaType string) {
+	q := r.URL.Query()
+	year := q.Get("primary_release_year") + q.Get("first_air_date_year")
+	minVote, _ := strconv.ParseFloat(q.Get("vote_average.gte"), 64)
+	var results []*Media
+	for _, m := range s.mediaOf(mediaType) {
+		date := m.ReleaseDate + m.FirstAirDate
+		switch {
+		case !hasGenres(m.GenreIDs, q.Get("with_genres")),
+			year != "" && !strings.HasPrefix(date, year),
+			m.VoteAverage < minVote,
+			q.Get("with_original_language") != "" && m.OriginalLanguage != q.Get("with_original_language"):
+			continue
+		}
+		results = append(results, m)
+	}
+	sortMedia(results, q.Get("sort_by"))
+	writeJSON(w, http.StatusOK, s.paginate(r, results))
+}
+
+// related serves the movies with at least minGenres genres in common
+// with the movie with ID strID, or all its genres if it has fewer, the
+// ones with more genres in common first. The recommendations have
+// minGenres 2 and the similar movies 1.
+func (s *Server) related(w http.ResponseWriter, r *http.Request, strID string, minGenres int) {
+	id, _ := strconv.Atoi(strID)
+	m, ok := s.media[key("movie", id)]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+	if minGenres > len(m.GenreIDs) {
+		minGenres = len(m.GenreIDs)
+	}
+	if minGenres < 1 {
+		minGenres = 1
+	}
+	common := func(o *Media) int {
+		n := 0
+		for _, g := range o.GenreIDs {
+			if indexOfInt(m.GenreIDs, g) >= 0 {
+				n++
+			}
+		}
+		return n
+	}
+	results := []*Media{}
+	for _, o := range s.mediaOf("movie") {
+		if o.ID != id && common(o) >= minGenres {
+			results = append(results, o)
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return common(results[i]) > common(results[j])
+	})
+	writeJSON(w, http.StatusOK, s.paginate(r, results))
+}
+
+// indexOfInt returns the index of v in s or -1 if s has no v.
+func indexOfInt(s []int, v int) int {
+	for i, x := range s {
+		if x == v {
+			return i
+		}
+	}
+	return -1
+}
+
+// hasGenres reports if genres match filter, comma
+// separated IDs means AND and pipe separated means OR.
+func hasGenres(genres []int, filter string) bool {
+	if filter == "" {
+		return true
+	}
+	has := func(id string) bool {
+		for _, g := range genres {
+			if strconv.Itoa(g) == id {
+				return true
+			}
+		}
+		return false
+	}
+	if strings.Contains(filter, "|") {
+		for _, id := range strings.Split(filter, "|") {
+			if has(id) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, id := range strings.Split(filter, ",") {
+		if !has(id) {
+			return false
+		}
+	}
+	return true
+}
+
+// sortMedia sorts media by sortBy, e.g. "vote_average.desc".
+func sortMedia(media []*Media, sortBy string) {
+	field, order := sortBy, "desc"
+	if i := strings.LastIndexByte(sortBy, '.'); i >= 0 {
+		field, order = sortBy[:i], sortBy[i+1:]
+	}
+	less := func(a, b *Media) bool { return a.Popularity < b.Popularity }
+	switch field {
+	case "vote_average":
+		less = func(a, b *Media) bool { return a.VoteAverage < b.VoteAverage }
+	case "vote_count":
+		less = func(a, b *Media) bool { return a.VoteCount < b.VoteCount }
+	case "primary_release_date", "first_air_date", "release_date":
+		less = func(a, b *Media) bool { return a.ReleaseDate+a.FirstAirDate < b.ReleaseDate+b.FirstAirDate }
+	case "original_title", "original_name", "title", "name":
+		less = func(a, b *Media) bool { return a.title() < b.title() }
+	}
+	sort.SliceStable(media, func(i, j int) bool {
+		if order == "asc" {
+			return less(media[i], media[j])
+		}
+		return less(media[j], media[i])
+	})
+}
+
+type genre struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func (s *Server) genres(w http.ResponseWriter) {
+	out := make([]genre, 0, len(Genres))
+	for id, name := range Genres {
+		out = append(out, genre{ID: id, Name: name})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	writeJSON(w, http.StatusOK, map[string][]genre{"genres": out})
+}
+
+// details serves the details of a movie or tv show, the
+// genre_ids become genres and movies have the runtime. With a
+// language other than English the title is the translation,
+// empty if the media has no translation to the language.
+func (s *Server) details(w http.ResponseWriter, r *http.Request, mediaType, strID string) {
+	id, _ := strconv.Atoi(strID)
+	m, ok := s.media[key(mediaType, id)]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, 11, err.Error())
+		return
+	}
+	details := make(map[string]interface{})
+	json.Unmarshal(b, &details)
+	delete(details, "genre_ids")
+	delete(details, "media_type")
+	genres := make([]genre, 0, len(m.GenreIDs))
+	for _, g := range m.GenreIDs {
+		genres = append(genres, genre{ID: g, Name: Genres[g]})
+	}
+	details["genres"] = genres
+	if mediaType == "movie" {
+		details["runtime"] = m.Runtime
+		details["imdb_id"] = m.ImdbID
+	}
+	if lang := strings.SplitN(r.URL.Query().Get("language"), "-", 2)[0]; lang != "" && lang != "en" {
+		title := "title"
+		if mediaType == "tv" {
+			title = "name"
+		}
+		details[title] = m.Translations[lang]
+	}
+	writeJSON(w, http.StatusOK, details)
+}
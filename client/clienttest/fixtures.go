@@ -0,0 +1,112 @@
+package clienttest
+
+// Media is a movie or tv show served by the fake. It has the fields
+// of TMDB search results and details, movies have Title and
+// ReleaseDate and tv shows have Name and FirstAirDate.
+type Media struct {
+	MediaType        string  `json:"media_type"`
+	ID               int     `json:"id"`
+	Title            string  `json:"title,omitempty"`
+	OriginalTitle    string  `json:"original_title,omitempty"`
+	ReleaseDate      string  `json:"release_date,omitempty"`
+	Name             string  `json:"name,omitempty"`
+	OriginalName     string  `json:"original_name,omitempty"`
+	FirstAirDate     string  `json:"first_air_date,omitempty"`
+	OriginalLanguage string  `json:"original_language"`
+	Overview         string  `json:"overview"`
+	GenreIDs         []int   `json:"genre_ids"`
+	Popularity       float64 `json:"popularity"`
+	VoteCount        int     `json:"vote_count"`
+	VoteAverage      float64 `json:"vote_average"`
+	// Runtime is only sent in movie details.
+	Runtime int `json:"-"`
+	// ImdbID is the IMDb ID, e.g. "tt0068646", only sent in
+	// movie details and used by find.
+	ImdbID string `json:"-"`
+	// Translations maps an ISO 639-1 code, e.g. "pt", to the
+	// translated title or name, only used in the details.
+	Translations map[string]string `json:"-"`
+}
+
+// title returns the title of a movie or the name of a tv show.
+func (m *Media) title() string {
+	if m.MediaType == "tv" {
+		return m.Name
+	}
+	return m.Title
+}
+
+// Genres are the movie and tv genres known by the fake.
+var Genres = map[int]string{
+	18:    "Drama",
+	35:    "Comedy",
+	53:    "Thriller",
+	80:    "Crime",
+	10765: "Sci-Fi & Fantasy",
+}
+
+// Fixtures are the movies and tv shows served by a new Server.
+var Fixtures = []Media{
+	{
+		MediaType: "movie", ID: 238, Title: "The Godfather", OriginalTitle: "The Godfather",
+		ReleaseDate: "1972-03-14", OriginalLanguage: "en", GenreIDs: []int{18, 80},
+		Overview:   "Spanning the years 1945 to 1955, a chronicle of the fictional Italian-American Corleone crime family.",
+		Popularity: 90.5, VoteCount: 17000, VoteAverage: 8.7, Runtime: 175, ImdbID: "tt0068646",
+		Translations: map[string]string{"pt": "O Poderoso Chefão"},
+	},
+	{
+		MediaType: "movie", ID: 240, Title: "The Godfather Part II", OriginalTitle: "The Godfather Part II",
+		ReleaseDate: "1974-12-20", OriginalLanguage: "en", GenreIDs: []int{18, 80},
+		Overview:   "In the continuing saga of the Corleone crime family, a young Vito Corleone grows up in Sicily and in 1910s New York.",
+		Popularity: 60.1, VoteCount: 10000, VoteAverage: 8.6, Runtime: 202, ImdbID: "tt0071562",
+	},
+	{
+		MediaType: "movie", ID: 278, Title: "The Shawshank Redemption", OriginalTitle: "The Shawshank Redemption",
+		ReleaseDate: "1994-09-23", OriginalLanguage: "en", GenreIDs: []int{18, 80},
+		Overview:   "Framed in the 1940s for the double murder of his wife and her lover, banker Andy Dufresne begins a new life at the Shawshank prison.",
+		Popularity: 95.2, VoteCount: 23000, VoteAverage: 8.7, Runtime: 142, ImdbID: "tt0111161",
+	},
+	{
+		MediaType: "movie", ID: 550, Title: "Fight Club", OriginalTitle: "Fight Club",
+		ReleaseDate: "1999-10-15", OriginalLanguage: "en", GenreIDs: []int{18},
+		Overview:   "A ticking-time-bomb insomniac and a slippery soap salesman channel primal male aggression into a shocking new form of therapy.",
+		Popularity: 70.3, VoteCount: 25000, VoteAverage: 8.4, Runtime: 139, ImdbID: "tt0137523",
+	},
+	{
+		MediaType: "movie", ID: 680, Title: "Pulp Fiction", OriginalTitle: "Pulp Fiction",
+		ReleaseDate: "1994-09-10", OriginalLanguage: "en", GenreIDs: []int{53, 80},
+		Overview:   "A burger-loving hit man, his philosophical partner, a drug-addled gangster's moll and a washed-up boxer converge in this sprawling crime caper.",
+		Popularity: 80.7, VoteCount: 24000, VoteAverage: 8.5, Runtime: 154, ImdbID: "tt0110912",
+	},
+	{
+		MediaType: "movie", ID: 598, Title: "City of God", OriginalTitle: "Cidade de Deus",
+		ReleaseDate: "2002-02-05", OriginalLanguage: "pt", GenreIDs: []int{18, 80},
+		Overview:   "In the poverty-stricken favelas of Rio de Janeiro in the 1970s, two young men choose different paths.",
+		Popularity: 40.4, VoteCount: 6000, VoteAverage: 8.4, Runtime: 130, ImdbID: "tt0317248",
+		Translations: map[string]string{"pt": "Cidade de Deus"},
+	},
+	{
+		MediaType: "movie", ID: 666, Title: "Central Station", OriginalTitle: "Central do Brasil",
+		ReleaseDate: "1998-04-03", OriginalLanguage: "pt", GenreIDs: []int{18},
+		Overview:   "An emotive journey of a former school teacher, who writes letters for illiterate people, and a young boy.",
+		Popularity: 15.9, VoteCount: 900, VoteAverage: 7.9, Runtime: 110, ImdbID: "tt0140888",
+	},
+	{
+		MediaType: "movie", ID: 13, Title: "Forrest Gump", OriginalTitle: "Forrest Gump",
+		ReleaseDate: "1994-06-23", OriginalLanguage: "en", GenreIDs: []int{35, 18},
+		Overview:   "A man with a low IQ has accomplished great things in his life and been present during significant historic events.",
+		Popularity: 85.0, VoteCount: 25000, VoteAverage: 8.5, Runtime: 142, ImdbID: "tt0109830",
+	},
+	{
+		MediaType: "tv", ID: 1396, Name: "Breaking Bad", OriginalName: "Breaking Bad",
+		FirstAirDate: "2008-01-20", OriginalLanguage: "en", GenreIDs: []int{18, 80},
+		Overview:   "A high school chemistry teacher diagnosed with terminal lung cancer turns to manufacturing methamphetamine.",
+		Popularity: 200.3, VoteCount: 12000, VoteAverage: 8.9, ImdbID: "tt0903747",
+	},
+	{
+		MediaType: "tv", ID: 66732, Name: "Stranger Things", OriginalName: "Stranger Things",
+		FirstAirDate: "2016-07-15", OriginalLanguage: "en", GenreIDs: []int{18, 10765},
+		Overview:   "When a young boy vanishes, a small town uncovers a mystery involving secret experiments.",
+		Popularity: 150.8, VoteCount: 15000, VoteAverage: 8.6, ImdbID: "tt4574334",
+	},
+}
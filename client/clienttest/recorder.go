@@ -0,0 +1,224 @@
+package clienttest
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Mode is the mode of a Recorder.
+type Mode int
+
+const (
+	// Replay answers the requests from the recorded
+	// golden files, without network.
+	Replay Mode = iota
+	// Record sends the requests to the real API and
+	// writes the interactions to golden files.
+	Record
+)
+
+// redacted replaces the secrets in the recorded files.
+const redacted = "REDACTED"
+
+// Recorder is a http.RoundTripper that records the interactions with
+// the TMDB API into golden files, one file per request, and replays
+// them deterministically. Use Client to plug it in client.New.
+//
+// The bearer token of the requests is never written, the Authorization
+// header is dropped and any occurrence of the token in the bodies is
+// replaced by REDACTED.
+type Recorder struct {
+	// Dir is the directory of the golden files.
+	Dir string
+	// Mode is Replay or Record.
+	Mode Mode
+	// Transport sends the requests when recording,
+	// if nil http.DefaultTransport is used.
+	Transport http.RoundTripper
+}
+
+// NewRecorder creates a Recorder that reads and writes
+// golden files in dir.
+func NewRecorder(dir string, mode Mode) *Recorder {
+	return &Recorder{Dir: dir, Mode: mode}
+}
+
+// Client returns a http.Client that uses the Recorder.
+func (rec *Recorder) Client() *http.Client {
+	return &http.Client{Transport: rec}
+}
+
+// Interaction is the content of a golden file.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a request without host and credentials.
+type RecordedRequest struct {
+	Method string `json:"method"`
+	// URL is the path and the query of the request.
+	URL  string          `json:"url"`
+	Body json.RawMessage `json:"body,omitempty"`
+}
+
+// RecordedResponse is a recorded response.
+type RecordedResponse struct {
+	StatusCode int             `json:"status_code"`
+	Header     http.Header     `json:"header,omitempty"`
+	Body       json.RawMessage `json:"body"`
+}
+
+// recordedHeaders are the response headers kept in golden files,
+// the others change on each request.
+var recordedHeaders = []string{"Content-Type"}
+
+// RoundTrip implements http.RoundTripper.
+func (rec *Recorder) RoundTrip(r *http.Request) (*http.Response, error) {
+	var body []byte
+	if r.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	// The token may be in the body, scrub it before naming the
+	// file so recordings and replays with other tokens match.
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	file := filepath.Join(rec.Dir, fileName(r, scrub(body, token)))
+	if rec.Mode == Record {
+		if err := rec.record(r, body, token, file); err != nil {
+			return nil, err
+		}
+	}
+	return replay(r, file)
+}
+
+// record sends r to the API and writes the interaction to file.
+func (rec *Recorder) record(r *http.Request, body []byte, token, file string) error {
+	transport := rec.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	out := r.Clone(r.Context())
+	out.Body = ioutil.NopCloser(bytes.NewReader(body))
+	resp, err := transport.RoundTrip(out)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	it := Interaction{
+		Request: RecordedRequest{
+			Method: r.Method,
+			URL:    r.URL.RequestURI(),
+			Body:   rawJSON(scrub(body, token)),
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     make(http.Header),
+			Body:       rawJSON(scrub(respBody, token)),
+		},
+	}
+	for _, h := range recordedHeaders {
+		if v := resp.Header.Get(h); v != "" {
+			it.Response.Header.Set(h, v)
+		}
+	}
+	b, err := json.MarshalIndent(it, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(rec.Dir, 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, append(b, '\n'), 0644)
+}
+
+// replay answers r with the response recorded in file.
+func replay(r *http.Request, file string) (*http.Response, error) {
+	b, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("clienttest: no recording of %s %s in %s",
+			r.Method, r.URL.RequestURI(), file)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var it Interaction
+	if err := json.Unmarshal(b, &it); err != nil {
+		return nil, fmt.Errorf("clienttest: %s: %v", file, err)
+	}
+	body := []byte(it.Response.Body)
+	// Non JSON bodies are recorded as JSON strings.
+	var s string
+	if json.Unmarshal(body, &s) == nil {
+		body = []byte(s)
+	}
+	header := it.Response.Header
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", it.Response.StatusCode, http.StatusText(it.Response.StatusCode)),
+		StatusCode:    it.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       r,
+	}, nil
+}
+
+// fileName returns the golden file name of a request, it is
+// readable and unique for the method, path, query and body.
+func fileName(r *http.Request, body []byte) string {
+	h := sha1.New()
+	fmt.Fprintf(h, "%s %s\n", r.Method, r.URL.RequestURI())
+	h.Write(body)
+	sum := hex.EncodeToString(h.Sum(nil))[:8]
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, strings.Trim(r.URL.Path, "/"))
+	return r.Method + "_" + name + "_" + sum + ".json"
+}
+
+// scrub replaces the occurrences of token in b.
+func scrub(b []byte, token string) []byte {
+	if token == "" {
+		return b
+	}
+	return bytes.ReplaceAll(b, []byte(token), []byte(redacted))
+}
+
+// rawJSON returns b as a JSON value, bodies that are
+// not JSON are encoded as JSON strings.
+func rawJSON(b []byte) json.RawMessage {
+	if len(b) == 0 {
+		return nil
+	}
+	if json.Valid(b) {
+		return b
+	}
+	s, _ := json.Marshal(string(b))
+	return s
+}
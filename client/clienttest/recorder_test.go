@@ -0,0 +1,85 @@
+package clienttest
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const secret = "very-secret-token"
+
+func get(t *testing.T, c *http.Client, url string) (int, string) {
+	t.Helper()
+	r, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("Authorization", "Bearer "+secret)
+	resp, err := c.Do(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Recorded bodies are indented, ignore the spaces.
+	return resp.StatusCode, strings.Join(strings.Fields(string(b)), "")
+}
+
+func TestRecorder(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Date", "changes on each request")
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		// Echo the token to check it is scrubbed.
+		w.Write([]byte(`{"path":"` + r.URL.Path + `","auth":"` + r.Header.Get("Authorization") + `"}`))
+	}))
+	dir := t.TempDir()
+
+	rec := NewRecorder(dir, Record)
+	code, body := get(t, rec.Client(), api.URL+"/movie/550?language=en")
+	if code != http.StatusOK || !strings.Contains(body, `"path":"/movie/550"`) {
+		t.Fatalf("record: got %d %s", code, body)
+	}
+	get(t, rec.Client(), api.URL+"/missing")
+	api.Close()
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil || len(files) != 2 {
+		t.Fatalf("got files %v, %v, want 2", files, err)
+	}
+	for _, f := range files {
+		b, err := ioutil.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(b), secret) {
+			t.Errorf("%s contains the token:\n%s", f, b)
+		}
+		if strings.Contains(string(b), "Date") {
+			t.Errorf("%s contains the Date header", f)
+		}
+	}
+
+	// Replay without the API.
+	rec = NewRecorder(dir, Replay)
+	code, body = get(t, rec.Client(), api.URL+"/movie/550?language=en")
+	if code != http.StatusOK || !strings.Contains(body, `"auth":"BearerREDACTED"`) {
+		t.Errorf("replay: got %d %s", code, body)
+	}
+	code, _ = get(t, rec.Client(), api.URL+"/missing")
+	if code != http.StatusNotFound {
+		t.Errorf("replay: got status %d, want %d", code, http.StatusNotFound)
+	}
+	r, _ := http.NewRequest("GET", api.URL+"/movie/550?language=pt", nil)
+	if _, err := rec.Client().Do(r); err == nil {
+		t.Error("replay of a request not recorded did not fail")
+	}
+}
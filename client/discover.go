@@ -0,0 +1,182 @@
+package client
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// Sort orders of discover results.
+const (
+	SortPopularityDesc  = "popularity.desc"
+	SortPopularityAsc   = "popularity.asc"
+	SortReleaseDateDesc = "primary_release_date.desc"
+	SortReleaseDateAsc  = "primary_release_date.asc"
+	SortRevenueDesc     = "revenue.desc"
+	SortRevenueAsc      = "revenue.asc"
+	SortVoteAverageDesc = "vote_average.desc"
+	SortVoteAverageAsc  = "vote_average.asc"
+	SortVoteCountDesc   = "vote_count.desc"
+	SortVoteCountAsc    = "vote_count.asc"
+	SortTitleAsc        = "original_title.asc"
+	SortTitleDesc       = "original_title.desc"
+)
+
+// DiscoverOptions are the filters of a movie discover.
+// Zero values are not sent to TMDB.
+type DiscoverOptions struct {
+	// SortBy is one of the Sort constants.
+	SortBy string
+	// Page is the page of results, starting at 1.
+	Page int
+	// Language is the language of the results, e.g. "pt-BR".
+	Language string
+	// Region filters release dates by a ISO 3166-1 code, e.g. "BR".
+	Region string
+	// IncludeAdult includes adult movies in results.
+	IncludeAdult bool
+	// Year filters by primary release year.
+	Year int
+	// ReleaseDateGTE and ReleaseDateLTE filter by primary
+	// release date range, the format is YYYY-MM-DD.
+	ReleaseDateGTE string
+	ReleaseDateLTE string
+	// VoteAverageGTE and VoteAverageLTE filter by vote average range.
+	VoteAverageGTE float64
+	VoteAverageLTE float64
+	// VoteCountGTE and VoteCountLTE filter by vote count range.
+	VoteCountGTE int
+	VoteCountLTE int
+	// RuntimeGTE and RuntimeLTE filter by runtime range, in minutes.
+	RuntimeGTE int
+	RuntimeLTE int
+	// OriginalLanguage filters by the ISO 639-1 code of
+	// the original language, e.g. "pt".
+	OriginalLanguage string
+	// Genres are the genres the movies must have, all of them.
+	Genres []int
+	// WithoutGenres are the genres the movies must not have.
+	WithoutGenres []int
+	// Keywords are the keywords the movies must have, all of them.
+	Keywords []int
+	// Cast are the people the movies must have in the cast.
+	Cast []int
+	// Crew are the people the movies must have in the crew.
+	Crew []int
+	// People are the people the movies must have in cast or crew.
+	People []int
+	// WatchProviders are the streaming providers, the movies
+	// must be available in at least one of them.
+	// WatchRegion is required when it is set.
+	WatchProviders []int
+	// WatchRegion is the ISO 3166-1 code of the region
+	// used to filter by WatchProviders.
+	WatchRegion string
+}
+
+// Values returns the options encoded as TMDB query params.
+func (o *DiscoverOptions) Values() url.Values {
+	params := make(url.Values)
+	setString := func(key, val string) {
+		if val != "" {
+			params.Set(key, val)
+		}
+	}
+	setInt := func(key string, val int) {
+		if val > 0 {
+			params.Set(key, strconv.Itoa(val))
+		}
+	}
+	setFloat := func(key string, val float64) {
+		if val > 0 {
+			params.Set(key, strconv.FormatFloat(val, 'f', -1, 64))
+		}
+	}
+	setInts := func(key string, vals []int, sep string) {
+		if len(vals) > 0 {
+			params.Set(key, joinInts(vals, sep))
+		}
+	}
+	setString("sort_by", o.SortBy)
+	if o.Page > 1 {
+		setInt("page", o.Page)
+	}
+	setString("language", o.Language)
+	setString("region", o.Region)
+	if o.IncludeAdult {
+		params.Set("include_adult", "true")
+	}
+	setInt("primary_release_year", o.Year)
+	setString("primary_release_date.gte", o.ReleaseDateGTE)
+	setString("primary_release_date.lte", o.ReleaseDateLTE)
+	setFloat("vote_average.gte", o.VoteAverageGTE)
+	setFloat("vote_average.lte", o.VoteAverageLTE)
+	setInt("vote_count.gte", o.VoteCountGTE)
+	setInt("vote_count.lte", o.VoteCountLTE)
+	setInt("with_runtime.gte", o.RuntimeGTE)
+	setInt("with_runtime.lte", o.RuntimeLTE)
+	setString("with_original_language", o.OriginalLanguage)
+	// Comma separated values means AND and
+	// pipe separated values means OR.
+	setInts("with_genres", o.Genres, ",")
+	setInts("without_genres", o.WithoutGenres, ",")
+	setInts("with_keywords", o.Keywords, ",")
+	setInts("with_cast", o.Cast, ",")
+	setInts("with_crew", o.Crew, ",")
+	setInts("with_people", o.People, ",")
+	setInts("with_watch_providers", o.WatchProviders, "|")
+	setString("watch_region", o.WatchRegion)
+	return params
+}
+
+// DiscoverMovie searchs for movies based in genres.
+func (c *Client) DiscoverMovie(genres []int) ([]Result, error) {
+	movieResp, err := c.Discover(&DiscoverOptions{Genres: genres})
+	if err != nil {
+		return nil, err
+	}
+	return movieResp.Results, nil
+}
+
+// Discover searchs for movies with the options opts and
+// returns the requested page of results.
+func (c *Client) Discover(opts *DiscoverOptions) (*SearchMovieResp, error) {
+	const path = "/discover/movie"
+	if len(opts.WatchProviders) > 0 && opts.WatchRegion == "" {
+		return nil, fmt.Errorf("watch providers require a watch region")
+	}
+	resp, err := c.getV3(path, opts.Values())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	movieResp := new(SearchMovieResp)
+	err = decodeResponse(movieResp, resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if movieResp.Results == nil {
+		return nil, fmt.Errorf("invalid results")
+	}
+	return movieResp, nil
+}
+
+type genresResp struct {
+	Genres []Genre `json:"genres"`
+}
+
+// GetGenres returns the list of genres of media type mediaType.
+func (c *Client) GetGenres(mediaType string) ([]Genre, error) {
+	path := "/genre/" + mediaType + "/list"
+	resp, err := c.getV3(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	gResp := new(genresResp)
+	err = decodeResponse(gResp, resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return gResp.Genres, nil
+}
@@ -0,0 +1,36 @@
+package client
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// FindResp is the response of a search by an external ID.
+type FindResp struct {
+	MovieResults []Result `json:"movie_results"`
+	TVResults    []Result `json:"tv_results"`
+}
+
+// Find finds the movies and tv shows with the IMDb ID imdbID,
+// e.g. "tt0068646".
+func (c *Client) Find(imdbID string) (*FindResp, error) {
+	if imdbID == "" {
+		return nil, fmt.Errorf("empty IMDb ID")
+	}
+	path := "/find/" + url.PathEscape(imdbID)
+	params := make(url.Values)
+	params.Set("external_source", "imdb_id")
+	resp, err := c.getV3(path, params)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	findResp := new(FindResp)
+	err = decodeResponse(findResp, resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	setMediaType(findResp.MovieResults, MediaMovie)
+	setMediaType(findResp.TVResults, MediaTV)
+	return findResp, nil
+}
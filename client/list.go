@@ -27,7 +27,7 @@ type createListResponse struct {
 // and returns the list ID or error.
 func (c *Client) CreateList(name string) (int, error) {
 	const path = "/list"
-	l := listToCreate{Name: name, ISO: "en"}
+	l := listToCreate{Name: name, ISO: c.LanguageCode()}
 	payload, err := json.Marshal(l)
 	if err != nil {
 		return 0, err
@@ -63,17 +63,47 @@ type List struct {
 	Description   string   `json:"description"`
 	AverageRating float64  `json:"average_rating"`
 	Name          string   `json:"name"`
+	// Comments maps "{media type}:{id}" to the
+	// comment of the item, e.g. "movie:550".
+	Comments map[string]string `json:"comments"`
 }
 
+// Comment returns the comment of result r in the list.
+func (l *List) Comment(r Result) string {
+	return l.Comments[r.Type()+":"+strconv.Itoa(r.ID)]
+}
+
+// Sort orders of list items.
+const (
+	ListSortOriginalAsc     = "original_order.asc"
+	ListSortOriginalDesc    = "original_order.desc"
+	ListSortVoteAverageAsc  = "vote_average.asc"
+	ListSortVoteAverageDesc = "vote_average.desc"
+	ListSortReleaseDateAsc  = "primary_release_date.asc"
+	ListSortReleaseDateDesc = "primary_release_date.desc"
+	ListSortTitleAsc        = "title.asc"
+	ListSortTitleDesc       = "title.desc"
+)
+
 // GetList get a list by id and page and returns the
 // list *List or error.
 func (c *Client) GetList(id, page int) (*List, error) {
+	return c.GetSortedList(id, page, "")
+}
+
+// GetSortedList get a list by id and page with the items sorted
+// by sortBy, e.g. ListSortTitleAsc. If sortBy is empty the list
+// sort order is used.
+func (c *Client) GetSortedList(id, page int, sortBy string) (*List, error) {
 	path := "/list/" + strconv.Itoa(id)
 	if page < 1 {
 		page = 1
 	}
 	params := make(url.Values)
 	params.Set("page", strconv.Itoa(page))
+	if sortBy != "" {
+		params.Set("sort_by", sortBy)
+	}
 	resp, err := c.MakeGet(path, params)
 	if err != nil {
 		return nil, err
@@ -90,6 +120,12 @@ func (c *Client) GetList(id, page int) (*List, error) {
 
 // Get lists get lists concurrently, based on id and page of list.
 func (c *Client) GetLists(idPage ...int) ([]*List, error) {
+	return c.GetSortedLists("", idPage...)
+}
+
+// GetSortedLists get lists concurrently, based on id and page of list,
+// with the items sorted by sortBy.
+func (c *Client) GetSortedLists(sortBy string, idPage ...int) ([]*List, error) {
 	if len(idPage)%2 != 0 {
 		return nil, fmt.Errorf("invalid length of idPage")
 	}
@@ -104,7 +140,7 @@ func (c *Client) GetLists(idPage ...int) ([]*List, error) {
 		// Fetch list concurrently.
 		go func(j, id, page int) {
 			var err error
-			lists[j], err = c.GetList(id, page)
+			lists[j], err = c.GetSortedList(id, page, sortBy)
 			errs <- err
 		}(j, id, page)
 		j++
@@ -125,13 +161,219 @@ func (c *Client) GetLists(idPage ...int) ([]*List, error) {
 	return lists, nil
 }
 
+// ListUpdate are the fields of a list to update,
+// nil fields are not changed.
+type ListUpdate struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Public      *bool   `json:"public,omitempty"`
+	// SortBy is the default sort order of the list items.
+	SortBy *string `json:"sort_by,omitempty"`
+}
+
+// statusResponse is the response of requests
+// that only report success.
+type statusResponse struct {
+	StatusMessage string `json:"status_message"`
+	Success       bool   `json:"success"`
+	StatusCode    int    `json:"status_code"`
+}
+
+// check returns an error with the status message if the
+// request failed.
+func (sr *statusResponse) check(action string) error {
+	if !sr.Success {
+		return fmt.Errorf("failed to %s: %s", action, sr.StatusMessage)
+	}
+	return nil
+}
+
+// UpdateList updates the name, description, public flag or
+// default sort order of the list with ID id.
+func (c *Client) UpdateList(id int, u *ListUpdate) error {
+	path := "/list/" + strconv.Itoa(id)
+	payload, err := json.Marshal(u)
+	if err != nil {
+		return err
+	}
+	resp, err := c.MakePut(path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	sr := new(statusResponse)
+	if err := decodeResponse(sr, resp.Body); err != nil {
+		return err
+	}
+	return sr.check("update list")
+}
+
+// ClearList removes all the items of the list with ID id.
+func (c *Client) ClearList(id int) error {
+	path := "/list/" + strconv.Itoa(id) + "/clear"
+	resp, err := c.MakeGet(path, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	sr := new(statusResponse)
+	if err := decodeResponse(sr, resp.Body); err != nil {
+		return err
+	}
+	return sr.check("clear list")
+}
+
+// DeleteList deletes the list with ID id.
+func (c *Client) DeleteList(id int) error {
+	path := "/list/" + strconv.Itoa(id)
+	resp, err := c.MakeDelete(path, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	sr := new(statusResponse)
+	if err := decodeResponse(sr, resp.Body); err != nil {
+		return err
+	}
+	return sr.check("delete list")
+}
+
+// maxPageWorkers is the max number of pages of
+// a list requested concurrently by WalkList.
+const maxPageWorkers = 4
+
+// WalkList calls fn with each result of the list with ID id, in the
+// list order, walking all the pages. The first page is requested alone
+// to know the number of pages, the others are requested concurrently,
+// at most maxPageWorkers at a time. If fn returns false the walk stops
+// and no more pages are requested.
+func (c *Client) WalkList(id int, fn func(r Result) bool) error {
+	return c.walkPages(id, "", func(l *List) bool {
+		for _, r := range l.Results {
+			if !fn(r) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// walkPages calls fn with each page of the list with ID id, sorted
+// by sortBy, in order, as described in WalkList.
+func (c *Client) walkPages(id int, sortBy string, fn func(l *List) bool) error {
+	first, err := c.GetSortedList(id, 1, sortBy)
+	if err != nil {
+		return err
+	}
+	if !fn(first) {
+		return nil
+	}
+	for page := 2; page <= first.TotalPages; page += maxPageWorkers {
+		n := first.TotalPages - page + 1
+		if n > maxPageWorkers {
+			n = maxPageWorkers
+		}
+		idPage := make([]int, 0, 2*n)
+		for i := 0; i < n; i++ {
+			idPage = append(idPage, id, page+i)
+		}
+		lists, err := c.GetSortedLists(sortBy, idPage...)
+		if err != nil {
+			return err
+		}
+		for _, l := range lists {
+			if !fn(l) {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// GetWholeList returns the list with ID id with the results and the
+// comments of all its pages, sorted by sortBy, as a single page.
+func (c *Client) GetWholeList(id int, sortBy string) (*List, error) {
+	var whole *List
+	err := c.walkPages(id, sortBy, func(l *List) bool {
+		if whole == nil {
+			whole = l
+			if whole.Comments == nil {
+				whole.Comments = make(map[string]string)
+			}
+			return true
+		}
+		whole.Results = append(whole.Results, l.Results...)
+		for k, v := range l.Comments {
+			whole.Comments[k] = v
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	whole.Page, whole.TotalPages = 1, 1
+	return whole, nil
+}
+
+// GetAllItems returns the results of all the pages
+// of the list with ID id.
+func (c *Client) GetAllItems(id int) ([]Result, error) {
+	var results []Result
+	err := c.WalkList(id, func(r Result) bool {
+		results = append(results, r)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Media types of TMDB items.
+const (
+	MediaMovie = "movie"
+	MediaTV    = "tv"
+)
+
+// Item is a media of a list, a movie or a tv show.
+type Item struct {
+	MediaType string `json:"media_type"`
+	MediaID   int    `json:"media_id"`
+	// Comment is the comment of the item in the list,
+	// it is only sent by UpdateItems.
+	Comment string `json:"comment,omitempty"`
+}
+
+// MovieItem returns the Item of the movie with ID id.
+func MovieItem(id int) Item {
+	return Item{MediaType: MediaMovie, MediaID: id}
+}
+
+// TVItem returns the Item of the tv show with ID id.
+func TVItem(id int) Item {
+	return Item{MediaType: MediaTV, MediaID: id}
+}
+
+// Key returns a string that identifies the item, the
+// same as Result.Key, e.g. "movie/238".
+func (i Item) Key() string {
+	return i.MediaType + "/" + strconv.Itoa(i.MediaID)
+}
+
 type toChangeList struct {
-	Items []listItem `json:"items"`
+	Items []Item `json:"items"`
 }
 
-type listItem struct {
+// updateItem is the Item of UpdateItems, the comment is
+// always sent, otherwise an empty comment is not cleared.
+type updateItem struct {
 	MediaType string `json:"media_type"`
 	MediaID   int    `json:"media_id"`
+	Comment   string `json:"comment"`
+}
+
+type toUpdateList struct {
+	Items []updateItem `json:"items"`
 }
 
 type changeListResponse struct {
@@ -147,13 +389,9 @@ type changeListResponse struct {
 
 type reqWithBodyFunc func(path string, body io.Reader) (*http.Response, error)
 
-// list change change list (add or remove items).
-func listChange(listID int, fn reqWithBodyFunc, items []int) (*changeListResponse, error) {
+// list change change list (add, update or remove items).
+func listChange(listID int, fn reqWithBodyFunc, payload []byte) (*changeListResponse, error) {
 	path := "/list/" + strconv.Itoa(listID) + "/items"
-	payload, err := marshalItems(items)
-	if err != nil {
-		return nil, err
-	}
 	resp, err := fn(path, bytes.NewReader(payload))
 	if err != nil {
 		return nil, err
@@ -168,18 +406,57 @@ func listChange(listID int, fn reqWithBodyFunc, items []int) (*changeListRespons
 	return clResp, nil
 }
 
+// Batches splits items in batches of at most size items, to
+// change a large number of items in more than one request.
+// A size less than 1 returns all the items in one batch.
+func Batches(items []Item, size int) [][]Item {
+	var out [][]Item
+	if size < 1 {
+		size = len(items)
+	}
+	for len(items) > size {
+		out = append(out, items[:size])
+		items = items[size:]
+	}
+	if len(items) > 0 {
+		out = append(out, items)
+	}
+	return out
+}
+
 // AddItems add items to list with ID listID.
-func (c *Client) AddItems(listID int, items ...int) (*changeListResponse, error) {
+func (c *Client) AddItems(listID int, items ...Item) (*changeListResponse, error) {
 	if len(items) == 0 {
 		return nil, fmt.Errorf("need at least 1 item to add")
 	}
-	return listChange(listID, c.MakePost, items)
+	payload, err := marshalItems(items)
+	if err != nil {
+		return nil, err
+	}
+	return listChange(listID, c.MakePost, payload)
+}
+
+// UpdateItems updates the comments of items of list with ID listID,
+// an empty comment removes the item comment.
+func (c *Client) UpdateItems(listID int, items ...Item) (*changeListResponse, error) {
+	if len(items) == 0 {
+		return nil, fmt.Errorf("need at least 1 item to update")
+	}
+	payload, err := marshalUpdates(items)
+	if err != nil {
+		return nil, err
+	}
+	return listChange(listID, c.MakePut, payload)
 }
 
 // DeleteItems delete items from list with ID listID.
-func (c *Client) DeleteItems(listID int, items ...int) (*changeListResponse, error) {
+func (c *Client) DeleteItems(listID int, items ...Item) (*changeListResponse, error) {
 	if len(items) == 0 {
 		return nil, fmt.Errorf("need at least 1 item to remove")
 	}
-	return listChange(listID, c.MakeDelete, items)
+	payload, err := marshalItems(items)
+	if err != nil {
+		return nil, err
+	}
+	return listChange(listID, c.MakeDelete, payload)
 }
@@ -3,24 +3,78 @@ package client
 import (
 	"fmt"
 	"net/url"
+	"strconv"
 )
 
-// Result is a movie search result.
+// Result is a movie or tv show search result.
+// Movies have Title, OriginalTitle and ReleaseDate
+// while tv shows have Name, OriginalName and FirstAirDate.
 type Result struct {
-	PosterPath       string  `json:"poster_path"`
-	Adult            bool    `json:"adult"`
-	Overview         string  `json:"overview"`
-	ReleaseDate      string  `json:"release_date"`
-	GenreIds         []int   `json:"genre_ids"`
-	ID               int     `json:"id"`
-	OriginalTitle    string  `json:"original_title"`
-	OriginalLanguage string  `json:"original_language"`
-	Title            string  `json:"title"`
-	BackdropPath     string  `json:"backdrop_path"`
-	Popularity       float64 `json:"popularity"`
-	VoteCount        int     `json:"vote_count"`
-	Video            bool    `json:"video"`
-	VoteAverage      float64 `json:"vote_average"`
+	MediaType        string   `json:"media_type"`
+	PosterPath       string   `json:"poster_path"`
+	Adult            bool     `json:"adult"`
+	Overview         string   `json:"overview"`
+	ReleaseDate      string   `json:"release_date"`
+	FirstAirDate     string   `json:"first_air_date"`
+	GenreIds         []int    `json:"genre_ids"`
+	ID               int      `json:"id"`
+	OriginalTitle    string   `json:"original_title"`
+	OriginalName     string   `json:"original_name"`
+	OriginalLanguage string   `json:"original_language"`
+	OriginCountry    []string `json:"origin_country"`
+	Title            string   `json:"title"`
+	Name             string   `json:"name"`
+	BackdropPath     string   `json:"backdrop_path"`
+	Popularity       float64  `json:"popularity"`
+	VoteCount        int      `json:"vote_count"`
+	Video            bool     `json:"video"`
+	VoteAverage      float64  `json:"vote_average"`
+}
+
+// Type returns the media type of the result,
+// results without media type are movies.
+func (r Result) Type() string {
+	if r.MediaType == "" {
+		return MediaMovie
+	}
+	return r.MediaType
+}
+
+// Item returns the list Item of the result.
+func (r Result) Item() Item {
+	return Item{MediaType: r.Type(), MediaID: r.ID}
+}
+
+// Key returns a string that identifies the result,
+// e.g. "movie/238" or "tv/1396".
+func (r Result) Key() string {
+	return r.Item().Key()
+}
+
+// DisplayTitle returns the title of a movie or the name of a tv show,
+// or the original one if it has no translation.
+func (r Result) DisplayTitle() string {
+	if r.Type() == MediaTV {
+		return fallback(r.Name, r.OriginalName)
+	}
+	return fallback(r.Title, r.OriginalTitle)
+}
+
+// fallback returns s, or original if s is empty.
+func fallback(s, original string) string {
+	if s == "" {
+		return original
+	}
+	return s
+}
+
+// Date returns the release date of a movie or the
+// first air date of a tv show.
+func (r Result) Date() string {
+	if r.Type() == MediaTV {
+		return r.FirstAirDate
+	}
+	return r.ReleaseDate
 }
 
 type SearchMovieResp struct {
@@ -30,12 +84,63 @@ type SearchMovieResp struct {
 	TotalPages   int      `json:"total_pages"`
 }
 
-// SearchMovie seachs a movie by a term an return the results.
+// SearchOptions are the filters of a movie search.
+// Zero values are not sent to TMDB.
+type SearchOptions struct {
+	// Query is the term to search, it is required.
+	Query string
+	// Page is the page of results, starting at 1.
+	Page int
+	// Year filters by release year.
+	Year int
+	// Language is the language of the results, e.g. "pt-BR".
+	Language string
+	// Region filters release dates by a ISO 3166-1 code, e.g. "BR".
+	Region string
+	// IncludeAdult includes adult movies in results.
+	IncludeAdult bool
+}
+
+// Values returns the options encoded as TMDB query params.
+func (o *SearchOptions) Values() url.Values {
+	params := make(url.Values)
+	params.Set("query", o.Query)
+	if o.Page > 1 {
+		params.Set("page", strconv.Itoa(o.Page))
+	}
+	if o.Year > 0 {
+		params.Set("year", strconv.Itoa(o.Year))
+	}
+	if o.Language != "" {
+		params.Set("language", o.Language)
+	}
+	if o.Region != "" {
+		params.Set("region", o.Region)
+	}
+	if o.IncludeAdult {
+		params.Set("include_adult", "true")
+	}
+	return params
+}
+
+// SearchMovie seachs a movie by a term an return the results
+// of the first page.
 func (c *Client) SearchMovie(query string) ([]Result, error) {
+	movieResp, err := c.SearchMovies(&SearchOptions{Query: query})
+	if err != nil {
+		return nil, err
+	}
+	return movieResp.Results, nil
+}
+
+// SearchMovies seachs movies with the options opts and returns
+// the requested page of results.
+func (c *Client) SearchMovies(opts *SearchOptions) (*SearchMovieResp, error) {
 	const path = "/search/movie"
-	params := make(url.Values)
-	params.Set("query", query)
-	resp, err := c.MakeGet(path, params)
+	if opts.Query == "" {
+		return nil, fmt.Errorf("empty query")
+	}
+	resp, err := c.getV3(path, opts.Values())
 	if err != nil {
 		return nil, err
 	}
@@ -45,32 +150,61 @@ func (c *Client) SearchMovie(query string) ([]Result, error) {
 	if err != nil {
 		return nil, err
 	}
-
-	results := movieResp.Results
-	if results == nil {
+	if movieResp.Results == nil {
 		return nil, fmt.Errorf("invalid results")
 	}
-	return results, nil
+	return movieResp, nil
 }
 
-// DiscoverMovie searchs for movies based in genres.
-func (c *Client) DiscoverMovie(genres []int) ([]Result, error) {
-	const path = "/discover/movie"
-	params := make(url.Values)
-	params.Set("with_genres", intsToString(genres))
-	resp, err := c.MakeGet(path, params)
+// Genre is a TMDB genre.
+type Genre struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// Movie stores the details of a movie.
+type Movie struct {
+	ID               int     `json:"id"`
+	ImdbID           string  `json:"imdb_id"`
+	Title            string  `json:"title"`
+	OriginalTitle    string  `json:"original_title"`
+	OriginalLanguage string  `json:"original_language"`
+	Tagline          string  `json:"tagline"`
+	Overview         string  `json:"overview"`
+	PosterPath       string  `json:"poster_path"`
+	BackdropPath     string  `json:"backdrop_path"`
+	ReleaseDate      string  `json:"release_date"`
+	Runtime          int     `json:"runtime"`
+	Genres           []Genre `json:"genres"`
+	Status           string  `json:"status"`
+	Homepage         string  `json:"homepage"`
+	Adult            bool    `json:"adult"`
+	Popularity       float64 `json:"popularity"`
+	VoteCount        int     `json:"vote_count"`
+	VoteAverage      float64 `json:"vote_average"`
+}
+
+// DisplayTitle returns the title of the movie, or the
+// original title if it has no translation.
+func (m *Movie) DisplayTitle() string {
+	return fallback(m.Title, m.OriginalTitle)
+}
+
+// GetMovie get the details of the movie with ID id.
+func (c *Client) GetMovie(id int) (*Movie, error) {
+	path := "/movie/" + strconv.Itoa(id)
+	resp, err := c.getV3(path, nil)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
-	movieResp := new(SearchMovieResp)
-	err = decodeResponse(movieResp, resp.Body)
+	movie := new(Movie)
+	err = decodeResponse(movie, resp.Body)
 	if err != nil {
 		return nil, err
 	}
-	results := movieResp.Results
-	if results == nil {
-		return nil, fmt.Errorf("invalid results")
+	if movie.ID == 0 {
+		return nil, fmt.Errorf("movie %d not found", id)
 	}
-	return results, nil
+	return movie, nil
 }
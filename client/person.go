@@ -0,0 +1,170 @@
+package client
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// Cast is an actor of a movie.
+type Cast struct {
+	ID                 int    `json:"id"`
+	Name               string `json:"name"`
+	Character          string `json:"character"`
+	ProfilePath        string `json:"profile_path"`
+	KnownForDepartment string `json:"known_for_department"`
+	CreditID           string `json:"credit_id"`
+	Order              int    `json:"order"`
+}
+
+// Crew is a member of the crew of a movie.
+type Crew struct {
+	ID                 int    `json:"id"`
+	Name               string `json:"name"`
+	Job                string `json:"job"`
+	Department         string `json:"department"`
+	ProfilePath        string `json:"profile_path"`
+	KnownForDepartment string `json:"known_for_department"`
+	CreditID           string `json:"credit_id"`
+}
+
+// Credits stores the cast and crew of a movie or tv show.
+type Credits struct {
+	ID   int    `json:"id"`
+	Cast []Cast `json:"cast"`
+	Crew []Crew `json:"crew"`
+}
+
+// Directors returns the crew members with the Director job.
+func (c *Credits) Directors() []Crew {
+	var out []Crew
+	for _, cr := range c.Crew {
+		if cr.Job == "Director" {
+			out = append(out, cr)
+		}
+	}
+	return out
+}
+
+// GetCredits returns the cast and crew of the media with
+// type mediaType and ID id.
+func (c *Client) GetCredits(mediaType string, id int) (*Credits, error) {
+	path := "/" + mediaType + "/" + strconv.Itoa(id) + "/credits"
+	resp, err := c.getV3(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	credits := new(Credits)
+	err = decodeResponse(credits, resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return credits, nil
+}
+
+// Person stores the details of a person.
+type Person struct {
+	ID                 int     `json:"id"`
+	ImdbID             string  `json:"imdb_id"`
+	Name               string  `json:"name"`
+	Biography          string  `json:"biography"`
+	Birthday           string  `json:"birthday"`
+	Deathday           string  `json:"deathday"`
+	PlaceOfBirth       string  `json:"place_of_birth"`
+	ProfilePath        string  `json:"profile_path"`
+	KnownForDepartment string  `json:"known_for_department"`
+	Popularity         float64 `json:"popularity"`
+}
+
+// GetPerson get the details of the person with ID id.
+func (c *Client) GetPerson(id int) (*Person, error) {
+	path := "/person/" + strconv.Itoa(id)
+	resp, err := c.getV3(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	person := new(Person)
+	err = decodeResponse(person, resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if person.ID == 0 {
+		return nil, fmt.Errorf("person %d not found", id)
+	}
+	return person, nil
+}
+
+// PersonCredit is a movie in which a person worked,
+// as cast (Character) or as crew (Job and Department).
+type PersonCredit struct {
+	Result
+	Character  string `json:"character"`
+	Job        string `json:"job"`
+	Department string `json:"department"`
+	CreditID   string `json:"credit_id"`
+}
+
+// PersonMovieCredits stores the movies in which a person worked.
+type PersonMovieCredits struct {
+	ID   int            `json:"id"`
+	Cast []PersonCredit `json:"cast"`
+	Crew []PersonCredit `json:"crew"`
+}
+
+// GetPersonMovieCredits returns the movies in which the person with
+// ID personID worked.
+func (c *Client) GetPersonMovieCredits(personID int) (*PersonMovieCredits, error) {
+	path := "/person/" + strconv.Itoa(personID) + "/movie_credits"
+	resp, err := c.getV3(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	credits := new(PersonMovieCredits)
+	err = decodeResponse(credits, resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return credits, nil
+}
+
+// PersonResult is a person search result.
+type PersonResult struct {
+	ID                 int     `json:"id"`
+	Name               string  `json:"name"`
+	ProfilePath        string  `json:"profile_path"`
+	KnownForDepartment string  `json:"known_for_department"`
+	Adult              bool    `json:"adult"`
+	Popularity         float64 `json:"popularity"`
+}
+
+type SearchPersonResp struct {
+	Page         int            `json:"page"`
+	Results      []PersonResult `json:"results"`
+	TotalResults int            `json:"total_results"`
+	TotalPages   int            `json:"total_pages"`
+}
+
+// SearchPerson seachs a person by a term an return the results.
+func (c *Client) SearchPerson(query string) ([]PersonResult, error) {
+	const path = "/search/person"
+	params := make(url.Values)
+	params.Set("query", query)
+	resp, err := c.getV3(path, params)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	personResp := new(SearchPersonResp)
+	err = decodeResponse(personResp, resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	results := personResp.Results
+	if results == nil {
+		return nil, fmt.Errorf("invalid results")
+	}
+	return results, nil
+}
@@ -0,0 +1,108 @@
+package client
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// Provider is a streaming, rent or buy provider.
+type Provider struct {
+	ProviderID      int    `json:"provider_id"`
+	ProviderName    string `json:"provider_name"`
+	LogoPath        string `json:"logo_path"`
+	DisplayPriority int    `json:"display_priority"`
+}
+
+// CountryProviders are the providers of a media in a country.
+type CountryProviders struct {
+	Link     string     `json:"link"`
+	Flatrate []Provider `json:"flatrate"`
+	Free     []Provider `json:"free"`
+	Ads      []Provider `json:"ads"`
+	Rent     []Provider `json:"rent"`
+	Buy      []Provider `json:"buy"`
+}
+
+// Streaming returns the providers that stream the media without
+// renting or buying it: subscription, free and with ads.
+func (cp *CountryProviders) Streaming() []Provider {
+	out := make([]Provider, 0, len(cp.Flatrate)+len(cp.Free)+len(cp.Ads))
+	out = append(out, cp.Flatrate...)
+	out = append(out, cp.Free...)
+	out = append(out, cp.Ads...)
+	return out
+}
+
+type watchProvidersResp struct {
+	ID      int                         `json:"id"`
+	Results map[string]CountryProviders `json:"results"`
+}
+
+// GetWatchProviders returns where the media with type mediaType and
+// ID id is available, the map key is the ISO 3166-1 code of the country.
+func (c *Client) GetWatchProviders(mediaType string, id int) (map[string]CountryProviders, error) {
+	path := "/" + mediaType + "/" + strconv.Itoa(id) + "/watch/providers"
+	resp, err := c.getV3(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	wpResp := new(watchProvidersResp)
+	err = decodeResponse(wpResp, resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return wpResp.Results, nil
+}
+
+// Region is a country with watch providers.
+type Region struct {
+	ISO3166     string `json:"iso_3166_1"`
+	EnglishName string `json:"english_name"`
+	NativeName  string `json:"native_name"`
+}
+
+type regionsResp struct {
+	Results []Region `json:"results"`
+}
+
+// GetWatchRegions returns the countries with watch providers.
+func (c *Client) GetWatchRegions() ([]Region, error) {
+	const path = "/watch/providers/regions"
+	resp, err := c.getV3(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	rResp := new(regionsResp)
+	err = decodeResponse(rResp, resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return rResp.Results, nil
+}
+
+type providersResp struct {
+	Results []Provider `json:"results"`
+}
+
+// GetProviders returns the watch providers of media type
+// mediaType available in the country region.
+func (c *Client) GetProviders(mediaType, region string) ([]Provider, error) {
+	path := "/watch/providers/" + mediaType
+	params := make(url.Values)
+	if region != "" {
+		params.Set("watch_region", region)
+	}
+	resp, err := c.getV3(path, params)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	pResp := new(providersResp)
+	err = decodeResponse(pResp, resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return pResp.Results, nil
+}
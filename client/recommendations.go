@@ -0,0 +1,41 @@
+package client
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// GetRecommendations returns the page page of the movies TMDB
+// recommends to who watched the movie with ID id.
+func (c *Client) GetRecommendations(id, page int) (*SearchMovieResp, error) {
+	return c.movieResults("/movie/"+strconv.Itoa(id)+"/recommendations", page)
+}
+
+// GetSimilar returns the page page of the movies similar to the
+// movie with ID id, the ones with the same genres and keywords.
+func (c *Client) GetSimilar(id, page int) (*SearchMovieResp, error) {
+	return c.movieResults("/movie/"+strconv.Itoa(id)+"/similar", page)
+}
+
+// movieResults requests the page page of the movie results in path.
+func (c *Client) movieResults(path string, page int) (*SearchMovieResp, error) {
+	params := make(url.Values)
+	if page > 1 {
+		params.Set("page", strconv.Itoa(page))
+	}
+	resp, err := c.getV3(path, params)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	movieResp := new(SearchMovieResp)
+	err = decodeResponse(movieResp, resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if movieResp.Results == nil {
+		return nil, fmt.Errorf("invalid results")
+	}
+	return movieResp, nil
+}
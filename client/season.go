@@ -0,0 +1,58 @@
+package client
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Season stores the details of a season of a tv show.
+type Season struct {
+	ID           int       `json:"id"`
+	Name         string    `json:"name"`
+	Overview     string    `json:"overview"`
+	AirDate      string    `json:"air_date"`
+	SeasonNumber int       `json:"season_number"`
+	PosterPath   string    `json:"poster_path"`
+	Episodes     []Episode `json:"episodes"`
+}
+
+// GetSeason get the details of the season with number season
+// of the tv show with ID tvID.
+func (c *Client) GetSeason(tvID, season int) (*Season, error) {
+	path := "/tv/" + strconv.Itoa(tvID) + "/season/" + strconv.Itoa(season)
+	resp, err := c.getV3(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	s := new(Season)
+	err = decodeResponse(s, resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if s.ID == 0 {
+		return nil, fmt.Errorf("season %d of tv show %d not found", season, tvID)
+	}
+	return s, nil
+}
+
+// GetEpisode get the details of the episode with number episode
+// of season season of the tv show with ID tvID.
+func (c *Client) GetEpisode(tvID, season, episode int) (*Episode, error) {
+	path := "/tv/" + strconv.Itoa(tvID) + "/season/" + strconv.Itoa(season) +
+		"/episode/" + strconv.Itoa(episode)
+	resp, err := c.getV3(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	e := new(Episode)
+	err = decodeResponse(e, resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if e.ID == 0 {
+		return nil, fmt.Errorf("episode %d of season %d of tv show %d not found", episode, season, tvID)
+	}
+	return e, nil
+}
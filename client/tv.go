@@ -0,0 +1,180 @@
+package client
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Episode is an episode of a tv show.
+type Episode struct {
+	ID            int     `json:"id"`
+	Name          string  `json:"name"`
+	Overview      string  `json:"overview"`
+	AirDate       string  `json:"air_date"`
+	EpisodeNumber int     `json:"episode_number"`
+	SeasonNumber  int     `json:"season_number"`
+	Runtime       int     `json:"runtime"`
+	StillPath     string  `json:"still_path"`
+	VoteCount     int     `json:"vote_count"`
+	VoteAverage   float64 `json:"vote_average"`
+}
+
+// SeasonSummary is a season of a tv show without its episodes.
+type SeasonSummary struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	Overview     string `json:"overview"`
+	AirDate      string `json:"air_date"`
+	SeasonNumber int    `json:"season_number"`
+	EpisodeCount int    `json:"episode_count"`
+	PosterPath   string `json:"poster_path"`
+}
+
+// Network is a tv network.
+type Network struct {
+	ID            int    `json:"id"`
+	Name          string `json:"name"`
+	LogoPath      string `json:"logo_path"`
+	OriginCountry string `json:"origin_country"`
+}
+
+// TVShow stores the details of a tv show.
+type TVShow struct {
+	ID               int             `json:"id"`
+	Name             string          `json:"name"`
+	OriginalName     string          `json:"original_name"`
+	OriginalLanguage string          `json:"original_language"`
+	OriginCountry    []string        `json:"origin_country"`
+	Tagline          string          `json:"tagline"`
+	Overview         string          `json:"overview"`
+	PosterPath       string          `json:"poster_path"`
+	BackdropPath     string          `json:"backdrop_path"`
+	FirstAirDate     string          `json:"first_air_date"`
+	LastAirDate      string          `json:"last_air_date"`
+	Status           string          `json:"status"`
+	InProduction     bool            `json:"in_production"`
+	Homepage         string          `json:"homepage"`
+	NumberOfSeasons  int             `json:"number_of_seasons"`
+	NumberOfEpisodes int             `json:"number_of_episodes"`
+	EpisodeRunTime   []int           `json:"episode_run_time"`
+	Genres           []Genre         `json:"genres"`
+	Networks         []Network       `json:"networks"`
+	Seasons          []SeasonSummary `json:"seasons"`
+	LastEpisodeToAir *Episode        `json:"last_episode_to_air"`
+	NextEpisodeToAir *Episode        `json:"next_episode_to_air"`
+	Popularity       float64         `json:"popularity"`
+	VoteCount        int             `json:"vote_count"`
+	VoteAverage      float64         `json:"vote_average"`
+}
+
+// DisplayName returns the name of the tv show, or the
+// original name if it has no translation.
+func (t *TVShow) DisplayName() string {
+	return fallback(t.Name, t.OriginalName)
+}
+
+// GetTV get the details of the tv show with ID id.
+func (c *Client) GetTV(id int) (*TVShow, error) {
+	path := "/tv/" + strconv.Itoa(id)
+	resp, err := c.getV3(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	show := new(TVShow)
+	err = decodeResponse(show, resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if show.ID == 0 {
+		return nil, fmt.Errorf("tv show %d not found", id)
+	}
+	return show, nil
+}
+
+// SearchTV seachs tv shows with the options opts and returns
+// the requested page of results. Year filters by the first
+// air date and Region is ignored.
+func (c *Client) SearchTV(opts *SearchOptions) (*SearchMovieResp, error) {
+	const path = "/search/tv"
+	if opts.Query == "" {
+		return nil, fmt.Errorf("empty query")
+	}
+	params := opts.Values()
+	if year := params.Get("year"); year != "" {
+		params.Del("year")
+		params.Set("first_air_date_year", year)
+	}
+	params.Del("region")
+	resp, err := c.getV3(path, params)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	tvResp := new(SearchMovieResp)
+	err = decodeResponse(tvResp, resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if tvResp.Results == nil {
+		return nil, fmt.Errorf("invalid results")
+	}
+	setMediaType(tvResp.Results, MediaTV)
+	return tvResp, nil
+}
+
+// DiscoverTV searchs for tv shows with the options opts and
+// returns the requested page of results. The release filters
+// and sort orders are applied to the first air date, Cast,
+// Crew and People are not supported for tv shows.
+func (c *Client) DiscoverTV(opts *DiscoverOptions) (*SearchMovieResp, error) {
+	const path = "/discover/tv"
+	if len(opts.WatchProviders) > 0 && opts.WatchRegion == "" {
+		return nil, fmt.Errorf("watch providers require a watch region")
+	}
+	if len(opts.Cast) > 0 || len(opts.Crew) > 0 || len(opts.People) > 0 {
+		return nil, fmt.Errorf("cast and crew filters are not supported for tv shows")
+	}
+	params := opts.Values()
+	// Translate the movie params to tv params.
+	renames := map[string]string{
+		"primary_release_year":     "first_air_date_year",
+		"primary_release_date.gte": "first_air_date.gte",
+		"primary_release_date.lte": "first_air_date.lte",
+	}
+	for from, to := range renames {
+		if v := params.Get(from); v != "" {
+			params.Del(from)
+			params.Set(to, v)
+		}
+	}
+	if sortBy := params.Get("sort_by"); sortBy != "" {
+		sortBy = strings.Replace(sortBy, "primary_release_date", "first_air_date", 1)
+		sortBy = strings.Replace(sortBy, "original_title", "original_name", 1)
+		params.Set("sort_by", sortBy)
+	}
+	resp, err := c.getV3(path, params)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	tvResp := new(SearchMovieResp)
+	err = decodeResponse(tvResp, resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if tvResp.Results == nil {
+		return nil, fmt.Errorf("invalid results")
+	}
+	setMediaType(tvResp.Results, MediaTV)
+	return tvResp, nil
+}
+
+// setMediaType sets the media type of results, TMDB
+// does not send it in search and discover responses.
+func setMediaType(results []Result, mediaType string) {
+	for i := range results {
+		results[i].MediaType = mediaType
+	}
+}
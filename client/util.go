@@ -16,26 +16,27 @@ func decodeResponse(dst interface{}, r io.Reader) error {
 	return err
 }
 
-func marshalItems(items []int) ([]byte, error) {
-	body := new(toChangeList)
-	body.Items = make([]listItem, len(items))
-	for i, item := range items {
-		body.Items[i] = listItem{MediaType: "movie", MediaID: item}
+func marshalItems(items []Item) ([]byte, error) {
+	body := &toChangeList{Items: items}
+	return json.Marshal(body)
+}
+
+func marshalUpdates(items []Item) ([]byte, error) {
+	body := &toUpdateList{Items: make([]updateItem, len(items))}
+	for i, it := range items {
+		body.Items[i] = updateItem{MediaType: it.MediaType, MediaID: it.MediaID, Comment: it.Comment}
 	}
 	return json.Marshal(body)
 }
 
-// intsToString convert []int to a string comma separeted.
-func intsToString(slice []int) string {
+// joinInts convert []int to a string separated by sep.
+func joinInts(slice []int, sep string) string {
 	buf := new(bytes.Buffer)
-	for _, val := range slice {
+	for i, val := range slice {
+		if i > 0 {
+			buf.WriteString(sep)
+		}
 		buf.WriteString(strconv.Itoa(val))
-		buf.WriteByte(',')
-	}
-	s := buf.String()
-	// remove last comma.
-	if len(slice) > 0 {
-		s = s[:len(s)-1]
 	}
-	return s
+	return buf.String()
 }
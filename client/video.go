@@ -0,0 +1,122 @@
+package client
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// Video is a video (trailer, teaser, clip...) of a movie.
+type Video struct {
+	ID          string `json:"id"`
+	ISO639      string `json:"iso_639_1"`
+	ISO3166     string `json:"iso_3166_1"`
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	Site        string `json:"site"`
+	Size        int    `json:"size"`
+	Type        string `json:"type"`
+	Official    bool   `json:"official"`
+	PublishedAt string `json:"published_at"`
+}
+
+// URL returns the link to watch the video or
+// an empty string if the site is unknown.
+func (v *Video) URL() string {
+	switch v.Site {
+	case "YouTube":
+		return "https://www.youtube.com/watch?v=" + v.Key
+	case "Vimeo":
+		return "https://vimeo.com/" + v.Key
+	}
+	return ""
+}
+
+// EmbedURL returns the link to embed the video in
+// a page or an empty string if the site is unknown.
+func (v *Video) EmbedURL() string {
+	switch v.Site {
+	case "YouTube":
+		return "https://www.youtube.com/embed/" + v.Key
+	case "Vimeo":
+		return "https://player.vimeo.com/video/" + v.Key
+	}
+	return ""
+}
+
+type videosResp struct {
+	ID      int     `json:"id"`
+	Results []Video `json:"results"`
+}
+
+// GetVideos returns the videos of the media with type
+// mediaType and ID id. With a client language, the videos
+// in that language, in english and without language are
+// returned, so BestTrailer can fall back to english.
+func (c *Client) GetVideos(mediaType string, id int) ([]Video, error) {
+	path := "/" + mediaType + "/" + strconv.Itoa(id) + "/videos"
+	var params url.Values
+	if c.language != "" {
+		params = url.Values{"include_video_language": {c.LanguageCode() + ",en,null"}}
+	}
+	resp, err := c.getV3(path, params)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	vResp := new(videosResp)
+	err = decodeResponse(vResp, resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return vResp.Results, nil
+}
+
+// BestTrailers returns the best trailer of each language,
+// the map key is the iso_639_1 of the language.
+func BestTrailers(videos []Video) map[string]*Video {
+	best := make(map[string]*Video)
+	for i := range videos {
+		v := &videos[i]
+		if v.Type != "Trailer" || v.URL() == "" {
+			continue
+		}
+		if cur, ok := best[v.ISO639]; !ok || betterTrailer(v, cur) {
+			best[v.ISO639] = v
+		}
+	}
+	return best
+}
+
+// BestTrailer returns the best trailer in language lang,
+// if there is no trailer in lang it fallbacks to english
+// and then to any language. It returns nil if there
+// is no trailer at all.
+func BestTrailer(videos []Video, lang string) *Video {
+	best := BestTrailers(videos)
+	if v, ok := best[lang]; ok {
+		return v
+	}
+	if v, ok := best["en"]; ok {
+		return v
+	}
+	var out *Video
+	for _, v := range best {
+		if out == nil || betterTrailer(v, out) {
+			out = v
+		}
+	}
+	return out
+}
+
+// betterTrailer reports if a is a better trailer than b.
+// Official trailers are preferred, then the highest
+// resolution and then the most recent.
+func betterTrailer(a, b *Video) bool {
+	if a.Official != b.Official {
+		return a.Official
+	}
+	if a.Size != b.Size {
+		return a.Size > b.Size
+	}
+	return a.PublishedAt > b.PublishedAt
+}
@@ -0,0 +1,257 @@
+// Package diary logs when each profile watched movies and tv
+// shows, with an optional note. Watching an item again is
+// logged as a rewatch.
+package diary
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rschio/movieApp/client"
+	"github.com/rschio/movieApp/store"
+)
+
+// bucket is the store bucket of the diary, the keys are
+// profile keys and the values are the profile's entries.
+const bucket = "diary"
+
+// MaxNoteLen is the max length of the note of an entry.
+const MaxNoteLen = 500
+
+// ErrNoteTooLong is returned when the note of an
+// entry is longer than MaxNoteLen.
+var ErrNoteTooLong = errors.New("diary: note too long")
+
+// Entry is a watch of a movie or tv show.
+type Entry struct {
+	// ID identifies the entry in the profile's diary.
+	ID   int
+	Item client.Item
+	// WatchedAt is the day the item was watched, it is zero
+	// when the day is unknown, see Backfill.
+	WatchedAt time.Time
+	Note      string
+	// Rewatch is set when the item was watched before.
+	Rewatch bool
+}
+
+// Diary stores the watch logs of the profiles.
+type Diary struct {
+	db *store.Store
+}
+
+// New creates a diary that persists the logs in db.
+func New(db *store.Store) *Diary {
+	return &Diary{db: db}
+}
+
+// Log logs that profile watched item on the day of watchedAt, with
+// note. The entries of the item, including the older ones, are
+// marked as rewatches if the item was watched before them.
+func (d *Diary) Log(profile string, item client.Item, watchedAt time.Time, note string) (*Entry, error) {
+	note = strings.TrimSpace(note)
+	if len(note) > MaxNoteLen {
+		return nil, ErrNoteTooLong
+	}
+	item.Comment = ""
+	e := &Entry{
+		Item:      item,
+		WatchedAt: Day(watchedAt),
+		Note:      note,
+	}
+	var entries []*Entry
+	err := d.db.Update(bucket, profile, &entries, func() error {
+		for _, old := range entries {
+			if old.ID >= e.ID {
+				e.ID = old.ID + 1
+			}
+		}
+		entries = append(entries, e)
+		markRewatches(entries)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Import logs the watches of profile in entries, only their items,
+// days and notes are used. The items already logged on the same day
+// are skipped, so the same history can be imported again. It returns
+// the number of entries logged.
+func (d *Diary) Import(profile string, entries []Entry) (int, error) {
+	for _, e := range entries {
+		if len(strings.TrimSpace(e.Note)) > MaxNoteLen {
+			return 0, ErrNoteTooLong
+		}
+	}
+	var (
+		logged []*Entry
+		n      int
+	)
+	err := d.db.Update(bucket, profile, &logged, func() error {
+		n = 0
+		id := 0
+		seen := make(map[string]bool)
+		for _, e := range logged {
+			if e.ID >= id {
+				id = e.ID + 1
+			}
+			seen[e.Item.Key()+"/"+e.WatchedAt.Format("2006-01-02")] = true
+		}
+		for _, e := range entries {
+			e.Item.Comment = ""
+			e.WatchedAt = Day(e.WatchedAt)
+			key := e.Item.Key() + "/" + e.WatchedAt.Format("2006-01-02")
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			logged = append(logged, &Entry{
+				ID:        id,
+				Item:      e.Item,
+				WatchedAt: e.WatchedAt,
+				Note:      strings.TrimSpace(e.Note),
+			})
+			id++
+			n++
+		}
+		markRewatches(logged)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// Backfill logs an undated entry for each of items without entries
+// in the diary of profile, the items watched before it was kept. It
+// returns the number of entries logged.
+func (d *Diary) Backfill(profile string, items []client.Item) (int, error) {
+	var (
+		logged []*Entry
+		n      int
+	)
+	err := d.db.Update(bucket, profile, &logged, func() error {
+		n = 0
+		id := 0
+		seen := make(map[string]bool)
+		for _, e := range logged {
+			if e.ID >= id {
+				id = e.ID + 1
+			}
+			seen[e.Item.Key()] = true
+		}
+		for _, item := range items {
+			if seen[item.Key()] {
+				continue
+			}
+			seen[item.Key()] = true
+			item.Comment = ""
+			logged = append(logged, &Entry{ID: id, Item: item})
+			id++
+			n++
+		}
+		markRewatches(logged)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// Delete deletes the entry with ID id of profile.
+func (d *Diary) Delete(profile string, id int) error {
+	var entries []*Entry
+	return d.db.Update(bucket, profile, &entries, func() error {
+		for i, e := range entries {
+			if e.ID == id {
+				entries = append(entries[:i], entries[i+1:]...)
+				break
+			}
+		}
+		markRewatches(entries)
+		return nil
+	})
+}
+
+// Entries returns the entries of profile watched in year and month,
+// the most recently watched first. A zero month returns the entries
+// of the whole year and a zero year the entries of the month of all
+// the years, both zero return all the entries, the undated last.
+func (d *Diary) Entries(profile string, year int, month time.Month) ([]*Entry, error) {
+	var entries []*Entry
+	if _, err := d.db.Get(bucket, profile, &entries); err != nil {
+		return nil, err
+	}
+	out := entries[:0]
+	for _, e := range entries {
+		if (year != 0 || month != 0) && e.WatchedAt.IsZero() {
+			continue
+		}
+		if year != 0 && e.WatchedAt.Year() != year {
+			continue
+		}
+		if month != 0 && e.WatchedAt.Month() != month {
+			continue
+		}
+		out = append(out, e)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].WatchedAt.After(out[j].WatchedAt) ||
+			(out[i].WatchedAt.Equal(out[j].WatchedAt) && out[i].ID > out[j].ID)
+	})
+	return out, nil
+}
+
+// Years returns the years with dated entries of profile, the most
+// recent first.
+func (d *Diary) Years(profile string) ([]int, error) {
+	entries, err := d.Entries(profile, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	var years []int
+	for _, e := range entries {
+		if e.WatchedAt.IsZero() {
+			continue
+		}
+		y := e.WatchedAt.Year()
+		if len(years) == 0 || years[len(years)-1] != y {
+			years = append(years, y)
+		}
+	}
+	return years, nil
+}
+
+// Profiles returns the keys of the profiles with a diary.
+func (d *Diary) Profiles() []string {
+	return d.db.Keys(bucket)
+}
+
+// markRewatches marks the entries of each item, except
+// the first watched, as rewatches.
+func markRewatches(entries []*Entry) {
+	first := make(map[string]*Entry)
+	for _, e := range entries {
+		f, ok := first[e.Item.Key()]
+		if !ok || e.WatchedAt.Before(f.WatchedAt) ||
+			(e.WatchedAt.Equal(f.WatchedAt) && e.ID < f.ID) {
+			first[e.Item.Key()] = e
+		}
+	}
+	for _, e := range entries {
+		e.Rewatch = first[e.Item.Key()] != e
+	}
+}
+
+// Day returns the day of t, midnight in UTC of t's date.
+func Day(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
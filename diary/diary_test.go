@@ -0,0 +1,142 @@
+package diary
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rschio/movieApp/client"
+	"github.com/rschio/movieApp/store"
+)
+
+func newDiary(t *testing.T) *Diary {
+	db, err := store.Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return New(db)
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 21, 30, 0, 0, time.UTC)
+}
+
+func TestLog(t *testing.T) {
+	d := newDiary(t)
+	const profile = "a@b.com/0"
+	godfather, fightClub := client.MovieItem(238), client.MovieItem(550)
+	logs := []struct {
+		item client.Item
+		at   time.Time
+	}{
+		{godfather, date(2026, 3, 10)},
+		{fightClub, date(2026, 3, 12)},
+		{godfather, date(2025, 12, 24)},
+	}
+	for _, l := range logs {
+		if _, err := d.Log(profile, l.item, l.at, " note "); err != nil {
+			t.Fatal(err)
+		}
+	}
+	all, err := d.Entries(profile, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 || all[0].Item != fightClub || all[2].WatchedAt != time.Date(2025, 12, 24, 0, 0, 0, 0, time.UTC) {
+		t.Fatalf("got entries %+v", all)
+	}
+	// The backdated entry is the first watch of The Godfather.
+	if all[0].Rewatch || !all[1].Rewatch || all[2].Rewatch || all[1].Note != "note" {
+		t.Errorf("got rewatches %v %v %v", all[0].Rewatch, all[1].Rewatch, all[2].Rewatch)
+	}
+	march, err := d.Entries(profile, 2026, time.March)
+	if err != nil || len(march) != 2 {
+		t.Errorf("got %d entries of March, %v, want 2", len(march), err)
+	}
+	december, err := d.Entries(profile, 0, time.December)
+	if err != nil || len(december) != 1 || december[0].WatchedAt.Year() != 2025 {
+		t.Errorf("got entries of December of all years %+v, %v, want 1", december, err)
+	}
+	years, err := d.Years(profile)
+	if err != nil || len(years) != 2 || years[0] != 2026 || years[1] != 2025 {
+		t.Errorf("Years = %v, %v, want [2026 2025]", years, err)
+	}
+	// Deleting the first watch makes the next the first.
+	if err := d.Delete(profile, all[2].ID); err != nil {
+		t.Fatal(err)
+	}
+	all, err = d.Entries(profile, 0, 0)
+	if err != nil || len(all) != 2 || all[1].Rewatch {
+		t.Errorf("got entries %+v, %v, want 2 first watches", all, err)
+	}
+	long := make([]byte, MaxNoteLen+1)
+	if _, err := d.Log(profile, godfather, time.Now(), string(long)); err != ErrNoteTooLong {
+		t.Errorf("got error %v, want ErrNoteTooLong", err)
+	}
+}
+
+func TestImport(t *testing.T) {
+	d := newDiary(t)
+	const profile = "a@b.com/0"
+	godfather, fightClub := client.MovieItem(238), client.MovieItem(550)
+	if _, err := d.Log(profile, godfather, date(2026, 3, 10), "note"); err != nil {
+		t.Fatal(err)
+	}
+	entries := []Entry{
+		{Item: godfather, WatchedAt: date(2026, 3, 10)},
+		{Item: godfather, WatchedAt: date(2024, 5, 1)},
+		{Item: fightClub, WatchedAt: date(2025, 1, 2)},
+	}
+	n, err := d.Import(profile, entries)
+	if err != nil || n != 2 {
+		t.Fatalf("Import = %d, %v, want 2", n, err)
+	}
+	// The same history is not imported twice.
+	if n, err := d.Import(profile, entries); err != nil || n != 0 {
+		t.Errorf("Import again = %d, %v, want 0", n, err)
+	}
+	all, err := d.Entries(profile, 0, 0)
+	if err != nil || len(all) != 3 {
+		t.Fatalf("got entries %+v, %v, want 3", all, err)
+	}
+	// The imported watch is the first of The Godfather.
+	if !all[0].Rewatch || all[0].Note != "note" || all[2].Rewatch || all[2].Item != godfather {
+		t.Errorf("got entries %+v", all)
+	}
+}
+
+func TestBackfill(t *testing.T) {
+	d := newDiary(t)
+	const profile = "a@b.com/0"
+	godfather, fightClub := client.MovieItem(238), client.MovieItem(550)
+	if _, err := d.Log(profile, godfather, date(2026, 3, 10), ""); err != nil {
+		t.Fatal(err)
+	}
+	n, err := d.Backfill(profile, []client.Item{godfather, fightClub})
+	if err != nil || n != 1 {
+		t.Fatalf("Backfill = %d, %v, want 1", n, err)
+	}
+	all, err := d.Entries(profile, 0, 0)
+	if err != nil || len(all) != 2 || all[1].Item != fightClub || !all[1].WatchedAt.IsZero() {
+		t.Fatalf("got entries %+v, %v, want Fight Club undated last", all, err)
+	}
+	// The undated entries are in no year or month.
+	if jan, err := d.Entries(profile, 0, time.January); err != nil || len(jan) != 0 {
+		t.Errorf("got entries of January %+v, %v, want none", jan, err)
+	}
+	if years, err := d.Years(profile); err != nil || len(years) != 1 {
+		t.Errorf("Years = %v, %v, want [2026]", years, err)
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	d := newDiary(t)
+	if p := d.Profiles(); len(p) != 0 {
+		t.Fatalf("got profiles %v in an empty diary", p)
+	}
+	if _, err := d.Log("a@b.com/1", client.MovieItem(238), date(2026, 3, 10), ""); err != nil {
+		t.Fatal(err)
+	}
+	if p := d.Profiles(); len(p) != 1 || p[0] != "a@b.com/1" {
+		t.Errorf("got profiles %v, want [a@b.com/1]", p)
+	}
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rschio/movieApp/account"
+	"github.com/rschio/movieApp/client"
+	"github.com/rschio/movieApp/diary"
+)
+
+const (
+	// diaryPageSize is the number of entries in a diary page.
+	diaryPageSize = 50
+	// diaryBackfillsBucket keeps the profile keys of the
+	// profiles whose WatchedList was backfilled in the diary.
+	diaryBackfillsBucket = "diarybackfills"
+	// titlesTTL is how long the titles of the
+	// movies and tv shows of the diary are cached.
+	titlesTTL = 7 * 24 * time.Hour
+	// maxTitlesRequests is the maximum number of concurrent
+	// requests of the titles of the diary.
+	maxTitlesRequests = 8
+)
+
+type titleEntry struct {
+	title   string
+	fetched time.Time
+}
+
+// titlesCache caches the titles of movies and tv shows by language
+// and item key, a diary page would request them for every entry.
+type titlesCache struct {
+	mu      sync.Mutex
+	entries map[string]*titleEntry
+}
+
+func newTitlesCache() *titlesCache {
+	return &titlesCache{entries: make(map[string]*titleEntry)}
+}
+
+func (tc *titlesCache) get(key string) (string, bool) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	e, ok := tc.entries[key]
+	if !ok || time.Since(e.fetched) > titlesTTL {
+		return "", false
+	}
+	return e.title, true
+}
+
+func (tc *titlesCache) set(key, title string) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	// Drop the expired entries, so the cache does not grow forever.
+	for k, e := range tc.entries {
+		if time.Since(e.fetched) > titlesTTL {
+			delete(tc.entries, k)
+		}
+	}
+	tc.entries[key] = &titleEntry{title: title, fetched: time.Now()}
+}
+
+// diaryEntry is a diary entry with the title of the item.
+type diaryEntry struct {
+	*diary.Entry
+	// Title is the title of the movie or the name of the
+	// tv show, empty if the details failed to load.
+	Title string
+}
+
+// diaryPage is the data used to render diary.html.
+type diaryPage struct {
+	Entries []*diaryEntry
+	// Year and Month filter the entries, zero if not filtered.
+	Year   int
+	Month  int
+	Years  []formOption
+	Months []formOption
+	// Prev and Next are the numbers of the previous
+	// and next pages, zero if there are none.
+	Prev int
+	Next int
+}
+
+// showDiary displays the profile's watch history, the most recently
+// watched first. The params year and month filter the entries and
+// the param page is the page of the entries.
+func (s *server) showDiary(w http.ResponseWriter, r *http.Request, acc *account.Account) {
+	id, err := account.ProfileFromRequest(r, acc)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	profile := acc.ProfileKey(id)
+	params := r.URL.Query()
+	year := intParam(params, "year")
+	month := intParam(params, "month")
+	if month < 0 || month > 12 {
+		month = 0
+	}
+	page := pageParam(params, "page")
+	if page < 1 {
+		page = 1
+	}
+	c := s.clientFor(acc)
+	s.backfillDiary(c, profile, acc.Profiles[id].WatchedListID)
+	entries, err := s.diary.Entries(profile, year, time.Month(month))
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	years, err := s.diary.Years(profile)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	toShow := &diaryPage{Year: year, Month: month}
+	for _, y := range years {
+		v := strconv.Itoa(y)
+		toShow.Years = append(toShow.Years, formOption{Value: v, Label: v, Selected: y == year})
+	}
+	for m := time.January; m <= time.December; m++ {
+		toShow.Months = append(toShow.Months, formOption{
+			Value:    strconv.Itoa(int(m)),
+			Label:    m.String(),
+			Selected: int(m) == month,
+		})
+	}
+	start := (page - 1) * diaryPageSize
+	if start > len(entries) {
+		start = len(entries)
+	}
+	end := start + diaryPageSize
+	if end < len(entries) {
+		toShow.Next = page + 1
+	} else {
+		end = len(entries)
+	}
+	if page > 1 {
+		toShow.Prev = page - 1
+	}
+	toShow.Entries = s.diaryTitles(c, entries[start:end])
+	s.tmpl(r, acc).ExecuteTemplate(w, "diary.html", toShow)
+}
+
+// backfillDiary logs the items of the list watchedListID, watched
+// before the diary was kept, as undated entries in the diary of the
+// profile with key profile. It is done once for each profile, best
+// effort, so it is done again while it fails.
+func (s *server) backfillDiary(c *client.Client, profile string, watchedListID int) {
+	var done bool
+	if _, err := s.store.Get(diaryBackfillsBucket, profile, &done); err != nil || done {
+		if err != nil {
+			log.Println(err)
+		}
+		return
+	}
+	watched, err := c.GetAllItems(watchedListID)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	items := make([]client.Item, len(watched))
+	for i, r := range watched {
+		items[i] = r.Item()
+	}
+	if _, err := s.diary.Backfill(profile, items); err != nil {
+		log.Println(err)
+		return
+	}
+	if err := s.store.Put(diaryBackfillsBucket, profile, true); err != nil {
+		log.Println(err)
+	}
+}
+
+// diaryTitles returns the entries with the titles of their items,
+// requested with c by maxTitlesRequests workers, once for each item
+// not in cache. The titles that fail to load are left empty.
+func (s *server) diaryTitles(c *client.Client, entries []*diary.Entry) []*diaryEntry {
+	var items []client.Item
+	index := make(map[string]int)
+	for _, e := range entries {
+		if _, ok := index[e.Item.Key()]; !ok {
+			index[e.Item.Key()] = len(items)
+			items = append(items, e.Item)
+		}
+	}
+	titles := make([]string, len(items))
+	var (
+		wg      sync.WaitGroup
+		pending = make(chan int)
+	)
+	workers := maxTitlesRequests
+	if len(items) < workers {
+		workers = len(items)
+	}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range pending {
+				title, err := s.title(c, items[i])
+				if err != nil {
+					log.Println(err)
+					continue
+				}
+				titles[i] = title
+			}
+		}()
+	}
+	for i := range items {
+		pending <- i
+	}
+	close(pending)
+	wg.Wait()
+	out := make([]*diaryEntry, len(entries))
+	for i, e := range entries {
+		out[i] = &diaryEntry{Entry: e, Title: titles[index[e.Item.Key()]]}
+	}
+	return out
+}
+
+// title returns the title of the movie or the name of the tv show
+// item, in the language of c, from cache if possible.
+func (s *server) title(c *client.Client, item client.Item) (string, error) {
+	key := c.LanguageCode() + "/" + item.Key()
+	if title, ok := s.titles.get(key); ok {
+		return title, nil
+	}
+	var title string
+	if item.MediaType == client.MediaTV {
+		tv, err := c.GetTV(item.MediaID)
+		if err != nil {
+			return "", err
+		}
+		title = tv.DisplayName()
+	} else {
+		m, err := c.GetMovie(item.MediaID)
+		if err != nil {
+			return "", err
+		}
+		title = m.DisplayTitle()
+	}
+	s.titles.set(key, title)
+	return title, nil
+}
+
+// deleteDiaryEntry deletes the entry with the ID in the
+// param id from the profile's diary.
+func (s *server) deleteDiaryEntry(w http.ResponseWriter, r *http.Request, acc *account.Account) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	id, err := account.ProfileFromRequest(r, acc)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	entryID, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	if err := s.diary.Delete(acc.ProfileKey(id), entryID); err != nil {
+		log.Println(err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	s.stats.invalidate(acc.ProfileKey(id))
+	http.Redirect(w, r, "/diary", http.StatusFound)
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/rschio/movieApp/account"
+	"github.com/rschio/movieApp/client"
+)
+
+// sortOrders are the sort orders displayed in discover page.
+var sortOrders = []struct {
+	Value string
+	Label string
+}{
+	{client.SortPopularityDesc, "Most popular"},
+	{client.SortPopularityAsc, "Least popular"},
+	{client.SortVoteAverageDesc, "Best rated"},
+	{client.SortVoteAverageAsc, "Worst rated"},
+	{client.SortVoteCountDesc, "Most voted"},
+	{client.SortReleaseDateDesc, "Newest"},
+	{client.SortReleaseDateAsc, "Oldest"},
+	{client.SortRevenueDesc, "Highest revenue"},
+	{client.SortTitleAsc, "Title (A-Z)"},
+	{client.SortTitleDesc, "Title (Z-A)"},
+}
+
+// formOption is a value of a select or checkbox in a form.
+type formOption struct {
+	Value    string
+	Label    string
+	Selected bool
+}
+
+// discoverPage is the data used to render discover.html.
+type discoverPage struct {
+	// MediaType is the type of media discovered,
+	// client.MediaMovie or client.MediaTV.
+	MediaType  string
+	Options    *client.DiscoverOptions
+	SortOrders []formOption
+	Genres     []formOption
+	Results    *ListPage
+	PrevURL    string
+	NextURL    string
+}
+
+// discover displays a form with discover filters and
+// the requested page of discovered movies or tv shows.
+func (s *server) discover(w http.ResponseWriter, r *http.Request, acc *account.Account) {
+	if err := r.ParseForm(); err != nil {
+		log.Println(err)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	opts := discoverOptions(r.Form)
+	mediaType := mediaTypeParam(r.Form, "type")
+	c := s.clientFor(acc)
+	discover := c.Discover
+	if mediaType == client.MediaTV {
+		discover = c.DiscoverTV
+	}
+	// Request the genres and the movies concurrently.
+	var (
+		genres []client.Genre
+		errs   = make(chan error, 1)
+	)
+	go func() {
+		var err error
+		genres, err = c.GetGenres(mediaType)
+		errs <- err
+	}()
+	movies, err := discover(opts)
+	if e := <-errs; e != nil {
+		// The form is still usable without genres.
+		log.Println(e)
+	}
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	page := &discoverPage{
+		MediaType: mediaType,
+		Options:   opts,
+		Results:   resultsPage(movies),
+	}
+	for _, so := range sortOrders {
+		page.SortOrders = append(page.SortOrders, formOption{
+			Value:    so.Value,
+			Label:    so.Label,
+			Selected: so.Value == opts.SortBy,
+		})
+	}
+	selected := make(map[int]bool)
+	for _, id := range opts.Genres {
+		selected[id] = true
+	}
+	for _, g := range genres {
+		page.Genres = append(page.Genres, formOption{
+			Value:    strconv.Itoa(g.ID),
+			Label:    g.Name,
+			Selected: selected[g.ID],
+		})
+	}
+	// Links to previous and next pages keep the filters.
+	params := opts.Values()
+	params.Set("type", mediaType)
+	if p := page.Results.Prev; p > 0 {
+		page.PrevURL = pageURL("/discover", params, p)
+	}
+	if n := page.Results.Next; n > 0 {
+		page.NextURL = pageURL("/discover", params, n)
+	}
+	s.tmpl(r, acc).ExecuteTemplate(w, "discover.html", page)
+}
+
+// discoverOptions parses the discover options from form,
+// the form fields have the same names of TMDB params.
+// Invalid values are ignored.
+func discoverOptions(form url.Values) *client.DiscoverOptions {
+	return &client.DiscoverOptions{
+		SortBy:           form.Get("sort_by"),
+		Page:             pageParam(form, "page"),
+		Language:         form.Get("language"),
+		Region:           form.Get("region"),
+		IncludeAdult:     form.Get("include_adult") != "",
+		Year:             intParam(form, "primary_release_year"),
+		ReleaseDateGTE:   form.Get("primary_release_date.gte"),
+		ReleaseDateLTE:   form.Get("primary_release_date.lte"),
+		VoteAverageGTE:   floatParam(form, "vote_average.gte"),
+		VoteAverageLTE:   floatParam(form, "vote_average.lte"),
+		VoteCountGTE:     intParam(form, "vote_count.gte"),
+		VoteCountLTE:     intParam(form, "vote_count.lte"),
+		RuntimeGTE:       intParam(form, "with_runtime.gte"),
+		RuntimeLTE:       intParam(form, "with_runtime.lte"),
+		OriginalLanguage: form.Get("with_original_language"),
+		Genres:           intsParam(form, "with_genres"),
+		WithoutGenres:    intsParam(form, "without_genres"),
+		Keywords:         intsParam(form, "with_keywords"),
+		Cast:             intsParam(form, "with_cast"),
+		Crew:             intsParam(form, "with_crew"),
+		People:           intsParam(form, "with_people"),
+		WatchProviders:   intsParam(form, "with_watch_providers"),
+		WatchRegion:      form.Get("watch_region"),
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/csv"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/rschio/movieApp/account"
+	"github.com/rschio/movieApp/client"
+)
+
+// exportHeader is the header of the exported CSV.
+var exportHeader = []string{"list", "media_type", "id", "title", "original_title", "date"}
+
+// export downloads all the items of the profile's lists as CSV,
+// one row per item with the name of its list.
+func (s *server) export(w http.ResponseWriter, r *http.Request, acc *account.Account) {
+	id, err := account.ProfileFromRequest(r, acc)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	profile := acc.Profiles[id]
+	c := s.clientFor(acc)
+	names := []string{"watchlist", "watched", "suggestions"}
+	listIDs := []int{profile.WatchListID, profile.WatchedListID, profile.SujestionsListID}
+	// Request all the items of the 3 lists concurrently.
+	items := make([][]client.Result, len(listIDs))
+	errs := make(chan error, 1)
+	for i, listID := range listIDs {
+		go func(i, listID int) {
+			var err error
+			items[i], err = c.GetAllItems(listID)
+			errs <- err
+		}(i, listID)
+	}
+	for range listIDs {
+		if e := <-errs; e != nil {
+			err = e
+		}
+	}
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	disposition := mime.FormatMediaType("attachment", map[string]string{
+		"filename": profile.Name + ".csv",
+	})
+	w.Header().Set("Content-Disposition", disposition)
+	cw := csv.NewWriter(w)
+	cw.Write(exportHeader)
+	for i, results := range items {
+		for _, r := range results {
+			original := r.OriginalTitle
+			if r.Type() == client.MediaTV {
+				original = r.OriginalName
+			}
+			cw.Write([]string{
+				names[i], r.Type(), strconv.Itoa(r.ID),
+				r.DisplayTitle(), original, r.Date(),
+			})
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		log.Println(err)
+	}
+}
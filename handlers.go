@@ -2,24 +2,83 @@ package main
 
 import (
 	"container/heap"
+	"html/template"
 	"log"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
-	"firebase.google.com/go/auth"
 	"github.com/rschio/movieApp/account"
+	"github.com/rschio/movieApp/client"
+	"github.com/rschio/movieApp/diary"
+	"github.com/rschio/movieApp/review"
 )
 
 // index serves a profile choose page.
 func (s *server) index(w http.ResponseWriter, r *http.Request, acc *account.Account) {
-	s.tmpl.ExecuteTemplate(w, "index.html", acc)
+	s.tmpl(r, acc).ExecuteTemplate(w, "index.html", acc)
+}
+
+// listSortOrders are the sort orders of the lists in browse page,
+// the first is the default.
+var listSortOrders = []struct {
+	Value string
+	Label string
+}{
+	{client.ListSortOriginalAsc, "Date added (oldest)"},
+	{client.ListSortOriginalDesc, "Date added (newest)"},
+	{client.ListSortTitleAsc, "Title (A-Z)"},
+	{client.ListSortVoteAverageDesc, "Best rated"},
+	{client.ListSortReleaseDateDesc, "Newest release"},
+	{client.ListSortReleaseDateAsc, "Oldest release"},
+}
+
+// listSortParam returns the list sort order of param with name
+// name, or the default sort order if it is not valid.
+func listSortParam(params url.Values, name string) string {
+	sortBy := params.Get(name)
+	for _, so := range listSortOrders {
+		if so.Value == sortBy {
+			return sortBy
+		}
+	}
+	return listSortOrders[0].Value
+}
+
+// browsePage is the data used to render browse.html.
+type browsePage struct {
+	// Lists are the WatchList, WatchedList and
+	// SujestionsList, in this order.
+	Lists []*ListPage
+	// Continue are the tv shows in progress.
+	Continue []*continueItem
+	// Available maps the item key to the subscribed
+	// providers that stream it.
+	Available map[string][]client.Provider
+	// StreamOnly is set when only the items available
+	// in subscribed providers are displayed.
+	StreamOnly bool
+	// Linked is set when the account is linked to TMDB,
+	// so movies can be rated when watched.
+	Linked bool
+	// Reviews maps the item keys to the
+	// profile's reviews.
+	Reviews map[string]*review.Review
+	// Sort is the sort order of the lists.
+	Sort       string
+	SortOrders []formOption
+	// Query are the params, besides the pages, that
+	// must be kept in pagination links.
+	Query template.URL
 }
 
 // browse is the core handler.
 // It displays a field to seach movie by term.
 // It displays all the profile's lists with pagination.
 // The WatchList displays the actions to send movie to WatchedList or ScheduleMovie.
+// It displays the tv shows in progress with the next episode to watch.
 func (s *server) browse(w http.ResponseWriter, r *http.Request, acc *account.Account) {
 	// Get the page param of each list.
 	params := r.URL.Query()
@@ -27,6 +86,8 @@ func (s *server) browse(w http.ResponseWriter, r *http.Request, acc *account.Acc
 		watchPage      = pageParam(params, "w")
 		watchedPage    = pageParam(params, "d")
 		sujestionsPage = pageParam(params, "s")
+		streamOnly     = params.Get("stream") != ""
+		sortBy         = listSortParam(params, "sort")
 	)
 	// Get the user profile.
 	id, err := account.ProfileFromRequest(r, acc)
@@ -36,12 +97,23 @@ func (s *server) browse(w http.ResponseWriter, r *http.Request, acc *account.Acc
 		return
 	}
 	profile := acc.Profiles[id]
-	// Request all the 3 lists from TMDB API, concurrently.
-	lists, err := s.client.GetLists(
+	idPage := []int{
 		profile.WatchListID, watchPage,
 		profile.WatchedListID, watchedPage,
 		profile.SujestionsListID, sujestionsPage,
+	}
+	// Request all the 3 lists from TMDB API, concurrently. To
+	// display only what can be streamed the lists are filtered
+	// as a whole and then paginated, so each page is full.
+	var (
+		lists     []*client.List
+		available map[string][]client.Provider
 	)
+	if streamOnly {
+		lists, available, err = s.availableLists(s.clientFor(acc), acc.Settings, sortBy, idPage...)
+	} else {
+		lists, err = s.clientFor(acc).GetSortedLists(sortBy, idPage...)
+	}
 	if err != nil {
 		log.Println(err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
@@ -51,39 +123,125 @@ func (s *server) browse(w http.ResponseWriter, r *http.Request, acc *account.Acc
 	// next page and if has set the correct page to
 	// prev and next to template can render the actions
 	// at end.
-	toShow := []*ListPage{
-		paginate(lists[0]),
-		paginate(lists[1]),
-		paginate(lists[2]),
-	}
-	genreID := preferredGenre(lists[0].Results, lists[1].Results)
-	// Try to suggest one movie to next browse.
-	go s.suggestMovie(profile.SujestionsListID, []int{genreID})
+	toShow := &browsePage{
+		Lists: []*ListPage{
+			paginate(lists[0]),
+			paginate(lists[1]),
+			paginate(lists[2]),
+		},
+		StreamOnly: streamOnly,
+		Linked:     acc.Settings.Linked(),
+		Sort:       sortBy,
+	}
+	for _, so := range listSortOrders {
+		toShow.SortOrders = append(toShow.SortOrders, formOption{
+			Value:    so.Value,
+			Label:    so.Label,
+			Selected: so.Value == sortBy,
+		})
+	}
+	// Check where the items of the pages can be streamed.
+	if available == nil {
+		var items []client.Item
+		for _, l := range lists {
+			for _, r := range l.Results {
+				items = append(items, r.Item())
+			}
+		}
+		available = s.availability(items, acc.Settings)
+	}
+	toShow.Available = available
+	query := make(url.Values)
+	if sortBy != listSortOrders[0].Value {
+		query.Set("sort", sortBy)
+	}
+	if streamOnly {
+		query.Set("stream", "1")
+	}
+	toShow.Query = template.URL(query.Encode())
+	toShow.Reviews, err = s.reviews.All(acc.ProfileKey(id))
+	if err != nil {
+		log.Println(err)
+	}
+	// Best effort, the lists are still useful without
+	// the continue watching row.
+	toShow.Continue, err = s.continueWatching(s.clientFor(acc), acc.ProfileKey(id))
+	if err != nil {
+		log.Println(err)
+	}
+	// The suggestions are refreshed in background, browse only
+	// queues the profiles never refreshed or with a new language.
+	s.queueSuggestions(r, acc, id, false)
 	// Execute the template with toShow data, this template
 	// does a bunch of work.
-	s.tmpl.ExecuteTemplate(w, "browse.html", toShow)
+	s.tmpl(r, acc).ExecuteTemplate(w, "browse.html", toShow)
 }
 
-// searchMovie search a movie with specified query and display the results,
-// the query must be sent as a POST request.
+// searchPage is the data used to render searchmovie.html.
+type searchPage struct {
+	// MediaType is the type of media searched,
+	// client.MediaMovie or client.MediaTV.
+	MediaType string
+	Options   *client.SearchOptions
+	Results   *ListPage
+	PrevURL   string
+	NextURL   string
+}
+
+// searchMovie search a movie or tv show with specified query and filters
+// and display the requested page of results. Without a query it displays
+// only the search form.
 func (s *server) searchMovie(w http.ResponseWriter, r *http.Request, acc *account.Account) {
-	if r.Method != "POST" {
+	if r.Method != "GET" && r.Method != "POST" {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	query := r.FormValue("query")
-	if query == "" {
-		http.Error(w, "Invalid query", http.StatusBadRequest)
+	if err := r.ParseForm(); err != nil {
+		log.Println(err)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	// Ignore invalid numbers, they are just not used as filter.
+	year, _ := strconv.Atoi(r.FormValue("year"))
+	opts := &client.SearchOptions{
+		Query:        r.FormValue("query"),
+		Page:         pageParam(r.Form, "page"),
+		Year:         year,
+		Language:     r.FormValue("language"),
+		Region:       r.FormValue("region"),
+		IncludeAdult: r.FormValue("include_adult") != "",
+	}
+	page := &searchPage{
+		MediaType: mediaTypeParam(r.Form, "type"),
+		Options:   opts,
+	}
+	if opts.Query == "" {
+		s.tmpl(r, acc).ExecuteTemplate(w, "searchmovie.html", page)
 		return
 	}
-	movies, err := s.client.SearchMovie(query)
+	c := s.clientFor(acc)
+	search := c.SearchMovies
+	if page.MediaType == client.MediaTV {
+		search = c.SearchTV
+	}
+	movies, err := search(opts)
 	if err != nil {
 		log.Println(err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
-	// Display the found movies.
-	s.tmpl.ExecuteTemplate(w, "searchmovie.html", movies)
+	// Display the found movies with links to the
+	// previous and next pages keeping the filters.
+	page.Results = resultsPage(movies)
+	params := opts.Values()
+	params.Set("type", page.MediaType)
+	if p := page.Results.Prev; p > 0 {
+		page.PrevURL = pageURL("/searchmovie", params, p)
+	}
+	if n := page.Results.Next; n > 0 {
+		page.NextURL = pageURL("/searchmovie", params, n)
+	}
+	s.tmpl(r, acc).ExecuteTemplate(w, "searchmovie.html", page)
 }
 
 // addProfile creates a new profile with name profileName and updates the user
@@ -99,39 +257,59 @@ func (s *server) addProfile(w http.ResponseWriter, r *http.Request, acc *account
 		return
 	}
 	// Creates a new profile.
-	err := acc.NewProfile(name, s.client)
+	err := acc.NewProfile(name, s.clientFor(acc))
 	if err != nil {
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
-	record, err := s.auther.GetUserByEmail(r.Context(), acc.Email)
-	if err != nil {
+	// Update the user with new profile to firebase.
+	if err := s.updateProfiles(r.Context(), acc); err != nil {
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
-	// Update the user with new profile to firebase.
-	update := new(auth.UserToUpdate)
-	update.CustomClaims(map[string]interface{}{
-		"birthday": acc.Birthday,
-		"profiles": acc.Profiles,
-	})
-	_, err = s.auther.UpdateUser(r.Context(), record.UID, update)
+	// Logout to get a updated token with new profile.
+	http.Redirect(w, r, "/logout", http.StatusFound)
+}
+
+// addItem add a movie or tv show to WatchList.
+func (s *server) addItem(w http.ResponseWriter, r *http.Request, acc *account.Account) {
+	const path = "/add/"
+	item, err := itemFromPath(path, r)
 	if err != nil {
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Println(err)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
 		return
 	}
-	// Revoke tokens (and logout) to get a updated token with new profile.
-	if err = s.auther.RevokeRefreshTokens(r.Context(), record.UID); err != nil {
+	id, err := account.ProfileFromRequest(r, acc)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	profile := acc.Profiles[id]
+	// Add item to WatchList.
+	_, err = s.clientFor(acc).AddItems(profile.WatchListID, item)
+	if err != nil {
+		log.Println(err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
-	http.Redirect(w, r, "/logout", http.StatusFound)
+	s.queueSuggestions(r, acc, id, true)
+	http.Redirect(w, r, "/browse", http.StatusFound)
 }
 
-// addMovie add a movie to WatchList.
-func (s *server) addMovie(w http.ResponseWriter, r *http.Request, acc *account.Account) {
-	const path = "/addmovie/"
-	movieID, err := idFromPath(path, r)
+// maxCommentLen is the max length of a list item comment.
+const maxCommentLen = 200
+
+// commentItem sets the comment of a movie or tv show in WatchList,
+// like "recommended by Ana", an empty comment removes it.
+func (s *server) commentItem(w http.ResponseWriter, r *http.Request, acc *account.Account) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	const path = "/comment/"
+	item, err := itemFromPath(path, r)
 	if err != nil {
 		log.Println(err)
 		http.Error(w, "Bad Request", http.StatusBadRequest)
@@ -143,21 +321,33 @@ func (s *server) addMovie(w http.ResponseWriter, r *http.Request, acc *account.A
 		http.Error(w, "Bad Request", http.StatusBadRequest)
 		return
 	}
+	item.Comment = strings.TrimSpace(r.FormValue("comment"))
+	if len(item.Comment) > maxCommentLen {
+		http.Error(w, "Comment too long", http.StatusBadRequest)
+		return
+	}
 	profile := acc.Profiles[id]
-	// Add movieID to WatchList.
-	_, err = s.client.AddItems(profile.WatchListID, movieID)
+	_, err = s.clientFor(acc).UpdateItems(profile.WatchListID, item)
 	if err != nil {
 		log.Println(err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
 	http.Redirect(w, r, "/browse", http.StatusFound)
 }
 
-// watchMovie deletes a movie from WatchList and add to WatchedList.
-func (s *server) watchMovie(w http.ResponseWriter, r *http.Request, acc *account.Account) {
-	const path = "/watchmovie/"
-	movieID, err := idFromPath(path, r)
+// watchItem deletes a movie or tv show from WatchList and add to WatchedList.
+// If the account is linked to TMDB, the param rating rates the movie in TMDB.
+// The watch is logged in the profile's diary, on the day in the param date,
+// in the format YYYY-MM-DD, or today, with the param note. Watching an item
+// already watched logs a rewatch.
+func (s *server) watchItem(w http.ResponseWriter, r *http.Request, acc *account.Account) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	const path = "/watch/"
+	item, err := itemFromPath(path, r)
 	if err != nil {
 		log.Println(err)
 		http.Error(w, "Bad Request", http.StatusBadRequest)
@@ -169,19 +359,52 @@ func (s *server) watchMovie(w http.ResponseWriter, r *http.Request, acc *account
 		http.Error(w, "Bad Request", http.StatusBadRequest)
 		return
 	}
+	watchedAt := time.Now()
+	if d := r.FormValue("date"); d != "" {
+		watchedAt, err = time.Parse("2006-01-02", d)
+		if err != nil || watchedAt.After(time.Now()) {
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+			return
+		}
+	}
+	note := strings.TrimSpace(r.FormValue("note"))
+	if len(note) > diary.MaxNoteLen {
+		http.Error(w, "Note too long", http.StatusBadRequest)
+		return
+	}
 	profile := acc.Profiles[id]
-	_, err = s.client.DeleteItems(profile.WatchListID, movieID)
+	c := s.clientFor(acc)
+	_, err = c.DeleteItems(profile.WatchListID, item)
 	if err != nil {
 		log.Println(err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
-	_, err = s.client.AddItems(profile.WatchedListID, movieID)
+	_, err = c.AddItems(profile.WatchedListID, item)
 	if err != nil {
 		log.Println(err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
+	// Push the rating to the linked TMDB account, if asked.
+	// Best effort, the movie is already watched.
+	rating := r.FormValue("rating")
+	if rating != "" && acc.Settings.Linked() && item.MediaType == client.MediaMovie {
+		value, err := strconv.ParseFloat(rating, 64)
+		if err == nil {
+			err = c.RateMovie(item.MediaID, value)
+		}
+		if err != nil {
+			log.Println(err)
+		}
+	}
+	// Best effort too, the diary is only a history.
+	if _, err := s.diary.Log(acc.ProfileKey(id), item, watchedAt, note); err != nil {
+		log.Println(err)
+	}
+	s.rememberProfile(r, acc, id)
+	s.stats.invalidate(acc.ProfileKey(id))
+	s.queueSuggestions(r, acc, id, true)
 	http.Redirect(w, r, "/browse", http.StatusFound)
 }
 
@@ -205,19 +428,19 @@ func (s *server) chooseProfile(w http.ResponseWriter, r *http.Request, acc *acco
 	http.Redirect(w, r, "/browse", http.StatusFound)
 }
 
-// showScheduler display the page to schedule a movie.
+// showScheduler display the page to schedule a movie or tv show.
 func (s *server) showScheduler(w http.ResponseWriter, r *http.Request, acc *account.Account) {
 	const path = "/showscheduler/"
-	movieID, err := idFromPath(path, r)
+	item, err := itemFromPath(path, r)
 	if err != nil {
 		log.Println(err)
 		http.Error(w, "Bad Request", http.StatusBadRequest)
 		return
 	}
-	s.tmpl.ExecuteTemplate(w, "schedulemovie.html", movieID)
+	s.tmpl(r, acc).ExecuteTemplate(w, "schedulemovie.html", item)
 }
 
-// scheduleMovie add a movie to scheduleList.
+// scheduleMovie add a movie or tv show to scheduleList.
 func (s *server) scheduleMovie(w http.ResponseWriter, r *http.Request, acc *account.Account) {
 	if r.Method != "POST" {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -225,7 +448,8 @@ func (s *server) scheduleMovie(w http.ResponseWriter, r *http.Request, acc *acco
 	}
 	d := r.FormValue("date-schedule")
 	t := r.FormValue("time-schedule")
-	idStr := r.FormValue("movie-id")
+	idStr := r.FormValue("media-id")
+	mediaType := mediaTypeParam(r.Form, "media-type")
 
 	date, err := time.Parse("2006-01-02-15:04", d+"-"+t)
 	if err != nil {
@@ -241,10 +465,12 @@ func (s *server) scheduleMovie(w http.ResponseWriter, r *http.Request, acc *acco
 		return
 	}
 	register := &ScheduledMovie{
-		Time:     date,
-		MovieID:  id,
-		UserName: acc.Name,
-		Email:    acc.Email,
+		Time:      date,
+		MediaType: mediaType,
+		MovieID:   id,
+		UserName:  acc.Name,
+		Email:     acc.Email,
+		Language:  uiLanguage(r, acc),
 	}
 	// Add register to scheduleList.
 	s.mu.Lock()
@@ -253,3 +479,85 @@ func (s *server) scheduleMovie(w http.ResponseWriter, r *http.Request, acc *acco
 
 	http.Redirect(w, r, "/browse", http.StatusFound)
 }
+
+// moviePage is the data used to render movie.html.
+type moviePage struct {
+	Movie     *client.Movie
+	Trailer   *client.Video
+	Directors []client.Crew
+	Cast      []client.Cast
+	// Review is the profile's review of the
+	// movie, nil if it was not reviewed.
+	Review *review.Review
+	Stars  []formOption
+}
+
+// maxCast is the number of actors displayed in movie page.
+const maxCast = 10
+
+// movie displays the details of a movie with its trailer and credits.
+func (s *server) movie(w http.ResponseWriter, r *http.Request, acc *account.Account) {
+	const path = "/movie/"
+	movieID, err := idFromPath(path, r)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	id, err := account.ProfileFromRequest(r, acc)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	c := s.clientFor(acc)
+	// Request the details, the videos and the credits concurrently.
+	var (
+		videos  []client.Video
+		credits = new(client.Credits)
+		errs    = make(chan error, 1)
+	)
+	go func() {
+		var err error
+		videos, err = c.GetVideos(client.MediaMovie, movieID)
+		errs <- err
+	}()
+	go func() {
+		cr, err := c.GetCredits(client.MediaMovie, movieID)
+		if err == nil {
+			credits = cr
+		}
+		errs <- err
+	}()
+	movie, err := c.GetMovie(movieID)
+	for i := 0; i < 2; i++ {
+		// Movie without trailer or credits is still a movie.
+		if e := <-errs; e != nil {
+			log.Println(e)
+		}
+	}
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+	rev, err := s.reviews.Get(acc.ProfileKey(id), client.MovieItem(movieID))
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	cast := credits.Cast
+	if len(cast) > maxCast {
+		cast = cast[:maxCast]
+	}
+	page := &moviePage{
+		Movie:     movie,
+		Trailer:   client.BestTrailer(videos, c.LanguageCode()),
+		Directors: credits.Directors(),
+		Cast:      cast,
+		Review:    rev,
+		Stars:     starOptions(rev),
+	}
+	s.tmpl(r, acc).ExecuteTemplate(w, "movie.html", page)
+}
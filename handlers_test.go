@@ -0,0 +1,1115 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rschio/movieApp/account"
+	"github.com/rschio/movieApp/client"
+	"github.com/rschio/movieApp/client/clienttest"
+	"github.com/rschio/movieApp/importer"
+	"github.com/rschio/movieApp/mail"
+	"github.com/rschio/movieApp/stats"
+)
+
+// newTestServer returns a server backed by a fake TMDB API
+// and an account with one profile.
+func newTestServer(t *testing.T) (*server, *clienttest.Server, *account.Account) {
+	fake := clienttest.NewServer()
+	t.Cleanup(fake.Close)
+	s := newServer(&serverConfig{
+		templatePath:   "templates",
+		clientBaseURL:  fake.URL,
+		clientAPIToken: clienttest.Token,
+	})
+	birthday := time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)
+	acc := account.New("a@b.com", "secret", "Ana", birthday, s.client)
+	if len(acc.Profiles) != 1 {
+		t.Fatal("failed to create account profile")
+	}
+	acc.Settings = &account.Settings{}
+	return s, fake, acc
+}
+
+// newRequest returns a request with the first profile selected.
+func newRequest(method, target string) *http.Request {
+	r := httptest.NewRequest(method, target, nil)
+	r.AddCookie(&http.Cookie{Name: "profile", Value: "0"})
+	return r
+}
+
+// newForm returns a POST request to target with the params form.
+func newForm(target string, form url.Values) *http.Request {
+	r := newRequest("POST", target)
+	r.Form = form
+	return r
+}
+
+type handler func(http.ResponseWriter, *http.Request, *account.Account)
+
+func do(h handler, r *http.Request, acc *account.Account) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h(w, r, acc)
+	return w
+}
+
+func TestAddAndWatchItem(t *testing.T) {
+	s, fake, acc := newTestServer(t)
+	p := acc.Profiles[0]
+
+	w := do(s.addItem, newRequest("GET", "/add/movie/238"), acc)
+	if w.Code != http.StatusFound {
+		t.Fatalf("add: got status %d, want %d", w.Code, http.StatusFound)
+	}
+	if l, _ := fake.List(p.WatchListID); len(l.Items) != 1 || l.Items[0] != "movie/238" {
+		t.Fatalf("WatchList items = %v, want [movie/238]", l.Items)
+	}
+
+	w = do(s.watchItem, newForm("/watch/movie/238", nil), acc)
+	if w.Code != http.StatusFound {
+		t.Fatalf("watch: got status %d, want %d", w.Code, http.StatusFound)
+	}
+	if l, _ := fake.List(p.WatchListID); len(l.Items) != 0 {
+		t.Errorf("WatchList items = %v, want none", l.Items)
+	}
+	if l, _ := fake.List(p.WatchedListID); len(l.Items) != 1 || l.Items[0] != "movie/238" {
+		t.Errorf("WatchedList items = %v, want [movie/238]", l.Items)
+	}
+
+	w = do(s.watchItem, newRequest("GET", "/watch/movie/238"), acc)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET watch: got status %d, want %d", w.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestAddItemBadPath(t *testing.T) {
+	s, _, acc := newTestServer(t)
+	w := do(s.addItem, newRequest("GET", "/add/book/1"), acc)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("got status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestBrowse(t *testing.T) {
+	s, _, acc := newTestServer(t)
+	do(s.addItem, newRequest("GET", "/add/movie/238"), acc)
+	do(s.addItem, newRequest("GET", "/add/tv/1396"), acc)
+
+	w := do(s.browse, newRequest("GET", "/browse"), acc)
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+	}
+	body := w.Body.String()
+	for _, title := range []string{"The Godfather", "Breaking Bad"} {
+		if !strings.Contains(body, title) {
+			t.Errorf("browse page does not contain %q", title)
+		}
+	}
+}
+
+func TestBrowseStreamOnly(t *testing.T) {
+	s, fake, acc := newTestServer(t)
+	// Without the filter only The Godfather is in the first page.
+	fake.PageSize = 1
+	for _, target := range []string{"/add/movie/238", "/add/movie/240", "/add/movie/278"} {
+		do(s.addItem, newRequest("GET", target), acc)
+	}
+	acc.Settings.Country = "BR"
+	acc.Settings.Providers = []int{8}
+	streamed := map[string]client.CountryProviders{
+		"BR": {Flatrate: []client.Provider{{ProviderID: 8, ProviderName: "Netflix"}}},
+	}
+	s.providers.set("movie/238", map[string]client.CountryProviders{})
+	s.providers.set("movie/240", streamed)
+	s.providers.set("movie/278", streamed)
+
+	body := do(s.browse, newRequest("GET", "/browse?stream=1"), acc).Body.String()
+	for _, key := range []string{"movie/240", "movie/278"} {
+		if !strings.Contains(body, `href="/`+key+`"`) {
+			t.Errorf("browse page does not contain the available %s", key)
+		}
+	}
+	if strings.Contains(body, `href="/movie/238"`) {
+		t.Error("browse page contains movie/238, not available")
+	}
+	if strings.Contains(body, ">Next<") {
+		t.Error("browse page has a next page")
+	}
+}
+
+func TestListPage(t *testing.T) {
+	whole := &client.List{ID: 1, Page: 1, TotalPages: 1}
+	for id := 0; id < listPageSize+5; id++ {
+		whole.Results = append(whole.Results, client.Result{ID: id})
+	}
+	tests := []struct {
+		page, first, n int
+	}{
+		{1, 0, listPageSize},
+		{2, listPageSize, 5},
+		{3, 0, 0},
+	}
+	for _, tt := range tests {
+		l := listPage(whole, tt.page)
+		if l.Page != tt.page || l.TotalPages != 2 || l.TotalResults != listPageSize+5 || len(l.Results) != tt.n {
+			t.Errorf("page %d: got page %d of %d with %d results", tt.page, l.Page, l.TotalPages, len(l.Results))
+			continue
+		}
+		if tt.n > 0 && l.Results[0].ID != tt.first {
+			t.Errorf("page %d: first result %d, want %d", tt.page, l.Results[0].ID, tt.first)
+		}
+	}
+}
+
+func TestBrowseWithoutProfile(t *testing.T) {
+	s, _, acc := newTestServer(t)
+	r := httptest.NewRequest("GET", "/browse", nil)
+	w := do(s.browse, r, acc)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("got status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestSearchMovieHandler(t *testing.T) {
+	s, _, acc := newTestServer(t)
+	w := do(s.searchMovie, newRequest("GET", "/searchmovie?query=godfather"), acc)
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, "The Godfather") {
+		t.Error("results do not contain The Godfather")
+	}
+	if strings.Contains(body, "Fight Club") {
+		t.Error("results contain Fight Club")
+	}
+}
+
+func TestMovieHandler(t *testing.T) {
+	s, _, acc := newTestServer(t)
+	w := do(s.movie, newRequest("GET", "/movie/550"), acc)
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+	}
+	if !strings.Contains(w.Body.String(), "Fight Club") {
+		t.Error("movie page does not contain Fight Club")
+	}
+
+	w = do(s.movie, newRequest("GET", "/movie/999999"), acc)
+	if w.Code == http.StatusOK {
+		t.Error("got status OK for unknown movie")
+	}
+}
+
+func TestExport(t *testing.T) {
+	s, fake, acc := newTestServer(t)
+	fake.PageSize = 1
+	do(s.addItem, newRequest("GET", "/add/movie/238"), acc)
+	do(s.addItem, newRequest("GET", "/add/tv/1396"), acc)
+	do(s.watchItem, newForm("/watch/movie/238", nil), acc)
+
+	w := do(s.export, newRequest("GET", "/export"), acc)
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+	}
+	want := "list,media_type,id,title,original_title,date\n" +
+		"watchlist,tv,1396,Breaking Bad,Breaking Bad,2008-01-20\n" +
+		"watched,movie,238,The Godfather,The Godfather,1972-03-14\n"
+	if got := w.Body.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	s, fake, acc := newTestServer(t)
+	p := acc.Profiles[0]
+	do(s.addItem, newRequest("GET", "/add/movie/238"), acc)
+	do(s.watchItem, newForm("/watch/movie/238", nil), acc)
+	do(s.addItem, newRequest("GET", "/add/movie/13"), acc)
+	// The Godfather is now watched, it is not a suggestion anymore.
+	if _, err := s.client.AddItems(p.SujestionsListID, client.MovieItem(278), client.MovieItem(238)); err != nil {
+		t.Fatal(err)
+	}
+	st := &suggestions{Profile: p, Language: "en"}
+	now := time.Now()
+	if err := s.suggest(s.client, st, nil, now); err != nil {
+		t.Fatal(err)
+	}
+	l, _ := fake.List(p.SujestionsListID)
+	if len(l.Items) == 0 || len(l.Items) > maxSuggestions {
+		t.Fatalf("got %d suggestions, want 1 to %d", len(l.Items), maxSuggestions)
+	}
+	if l.Items[0] != "movie/278" {
+		t.Errorf("first suggestion = %s, want the kept movie/278", l.Items[0])
+	}
+	// The sequel matches all the features of the watched movie.
+	if len(l.Items) < 2 || l.Items[1] != "movie/240" {
+		t.Errorf("best new suggestion is not movie/240: %v", l.Items)
+	}
+	if got := l.Comments["movie/240"]; got != "Because you watched The Godfather" {
+		t.Errorf("movie/240 comment = %q", got)
+	}
+	seen := make(map[string]bool)
+	for _, key := range l.Items {
+		if key == "movie/238" || key == "movie/13" || seen[key] {
+			t.Errorf("suggested known or repeated movie %s", key)
+		}
+		seen[key] = true
+	}
+	if len(st.Added) != len(l.Items) || !st.LastRefresh.Equal(now) {
+		t.Errorf("got %d added at %v, want %d at %v", len(st.Added), st.LastRefresh, len(l.Items), now)
+	}
+
+	// A week later the suggestions are rotated out,
+	// and they are not suggested again.
+	later := now.Add(suggestionTTL)
+	if err := s.suggest(s.client, st, nil, later); err != nil {
+		t.Fatal(err)
+	}
+	l, _ = fake.List(p.SujestionsListID)
+	for _, key := range l.Items {
+		if seen[key] {
+			t.Errorf("rotated out %s was suggested again", key)
+		}
+		if !st.Added[key].Equal(later) {
+			t.Errorf("%s added at %v, want %v", key, st.Added[key], later)
+		}
+	}
+	if !st.Rotated["movie/278"].Equal(later) {
+		t.Error("movie/278 was not rotated out")
+	}
+	// After the cooldown they can be suggested again.
+	if err := s.suggest(s.client, st, nil, later.Add(rotatedCooldown)); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := st.Rotated["movie/278"]; ok {
+		t.Error("movie/278 is still rotated out after the cooldown")
+	}
+}
+
+func TestSuggestTrim(t *testing.T) {
+	s, fake, acc := newTestServer(t)
+	p := acc.Profiles[0]
+	// A list filled before the suggestions were tracked.
+	var items []client.Item
+	for id := 1000; id < 1000+maxSuggestions+2; id++ {
+		fake.AddMedia(clienttest.Media{MediaType: client.MediaMovie, ID: id, Title: "Movie " + strconv.Itoa(id)})
+		items = append(items, client.MovieItem(id))
+	}
+	if _, err := s.client.AddItems(p.SujestionsListID, items...); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	st := &suggestions{Profile: p, Language: "en", Added: map[string]time.Time{"movie/1000": now.Add(-time.Hour)}}
+	if err := s.suggest(s.client, st, nil, now); err != nil {
+		t.Fatal(err)
+	}
+	l, _ := fake.List(p.SujestionsListID)
+	if len(l.Items) != maxSuggestions || len(st.Added) != maxSuggestions {
+		t.Fatalf("got %d suggestions, %d added, want %d", len(l.Items), len(st.Added), maxSuggestions)
+	}
+	// The untracked ones are rotated out first.
+	if _, ok := st.Added["movie/1000"]; !ok {
+		t.Error("the tracked suggestion was rotated out")
+	}
+	if len(st.Rotated) != 2 {
+		t.Errorf("got %d rotated out, want 2", len(st.Rotated))
+	}
+	for key := range st.Rotated {
+		if _, ok := st.Added[key]; ok {
+			t.Errorf("%s is rotated out and suggested", key)
+		}
+	}
+}
+
+func TestQueueSuggestions(t *testing.T) {
+	s, fake, acc := newTestServer(t)
+	key := acc.ProfileKey(0)
+	queued := func() int { return len(s.suggestions.keys) }
+
+	// Browse queues the profile once, the next ones do not
+	// request TMDB to suggest movies.
+	do(s.browse, newRequest("GET", "/browse"), acc)
+	do(s.browse, newRequest("GET", "/browse"), acc)
+	if queued() != 1 {
+		t.Fatalf("got %d queued, want 1", queued())
+	}
+	// The queued profile is not queued twice.
+	do(s.addItem, newRequest("GET", "/add/movie/238"), acc)
+	if queued() != 1 {
+		t.Fatalf("got %d queued after add, want 1", queued())
+	}
+	s.suggestions.pop(<-s.suggestions.keys)
+	if err := s.refreshSuggestions(key); err != nil {
+		t.Fatal(err)
+	}
+	l, _ := fake.List(acc.Profiles[0].SujestionsListID)
+	if len(l.Items) == 0 {
+		t.Fatal("refresh did not suggest movies")
+	}
+	var st suggestions
+	if _, err := s.store.Get(suggestionsBucket, key, &st); err != nil {
+		t.Fatal(err)
+	}
+	if st.Email != acc.Email || len(st.Added) != len(l.Items) || st.LastRefresh.IsZero() {
+		t.Errorf("got state %+v after refresh", st)
+	}
+	do(s.browse, newRequest("GET", "/browse"), acc)
+	if queued() != 0 {
+		t.Error("browse queued the refreshed profile")
+	}
+	// Changes to the lists and language queue it again.
+	do(s.watchItem, newForm("/watch/movie/238", nil), acc)
+	if queued() != 1 {
+		t.Errorf("got %d queued after watch, want 1", queued())
+	}
+	s.suggestions.pop(<-s.suggestions.keys)
+	r := newRequest("GET", "/browse")
+	r.Header.Set("Accept-Language", "pt-BR")
+	do(s.browse, r, acc)
+	if queued() != 1 {
+		t.Errorf("got %d queued after language change, want 1", queued())
+	}
+	// Only the stale profiles are queued periodically.
+	s.suggestions.pop(<-s.suggestions.keys)
+	s.queueStaleSuggestions(time.Hour)
+	if queued() != 0 {
+		t.Error("queued the profile refreshed less than an hour ago")
+	}
+	s.queueStaleSuggestions(0)
+	if queued() != 1 {
+		t.Error("did not queue the stale profile")
+	}
+}
+
+func TestSuggestionFeedback(t *testing.T) {
+	s, fake, acc := newTestServer(t)
+	p := acc.Profiles[0]
+	if _, err := s.client.AddItems(p.SujestionsListID,
+		client.MovieItem(240), client.MovieItem(278), client.MovieItem(550)); err != nil {
+		t.Fatal(err)
+	}
+	body := do(s.browse, newRequest("GET", "/browse"), acc).Body.String()
+	if !strings.Contains(body, `action="/suggestion/movie/278"`) {
+		t.Error("browse page does not have the suggestion actions")
+	}
+	feedback := func(target, action string) int {
+		r := newRequest("POST", target)
+		r.Form = url.Values{"action": {action}}
+		return do(s.suggestionFeedback, r, acc).Code
+	}
+	if code := feedback("/suggestion/movie/240", suggestionWatch); code != http.StatusFound {
+		t.Fatalf("watch got status %d, want %d", code, http.StatusFound)
+	}
+	if code := feedback("/suggestion/movie/550", suggestionSeen); code != http.StatusFound {
+		t.Fatalf("seen got status %d, want %d", code, http.StatusFound)
+	}
+	if code := feedback("/suggestion/movie/278", suggestionDismiss); code != http.StatusFound {
+		t.Fatalf("dismiss got status %d, want %d", code, http.StatusFound)
+	}
+	if code := feedback("/suggestion/movie/278", "hate"); code != http.StatusBadRequest {
+		t.Errorf("invalid action got status %d, want %d", code, http.StatusBadRequest)
+	}
+	if l, _ := fake.List(p.WatchListID); len(l.Items) != 1 || l.Items[0] != "movie/240" {
+		t.Errorf("watch list = %v, want movie/240", l.Items)
+	}
+	if l, _ := fake.List(p.WatchedListID); len(l.Items) != 1 || l.Items[0] != "movie/550" {
+		t.Errorf("watched list = %v, want movie/550", l.Items)
+	}
+	if l, _ := fake.List(p.SujestionsListID); len(l.Items) != 0 {
+		t.Errorf("suggestions = %v, want none", l.Items)
+	}
+	if entries, _ := s.diary.Entries(acc.ProfileKey(0), 0, 0); len(entries) != 1 || entries[0].Item.Key() != "movie/550" {
+		t.Errorf("diary entries = %+v, want movie/550", entries)
+	}
+
+	// The dismissed movie is not suggested again.
+	s.suggestions.pop(<-s.suggestions.keys)
+	if err := s.refreshSuggestions(acc.ProfileKey(0)); err != nil {
+		t.Fatal(err)
+	}
+	l, _ := fake.List(p.SujestionsListID)
+	if len(l.Items) == 0 {
+		t.Fatal("refresh did not suggest movies")
+	}
+	for _, key := range l.Items {
+		if key == "movie/278" {
+			t.Error("suggested the dismissed movie")
+		}
+	}
+}
+
+func TestReviewItem(t *testing.T) {
+	s, _, acc := newTestServer(t)
+	post := func(target string, form url.Values) *httptest.ResponseRecorder {
+		r := newRequest("POST", target)
+		r.Form = form
+		return do(s.reviewItem, r, acc)
+	}
+	w := post("/review/movie/238", url.Values{"stars": {"4.5"}, "review": {" Better than the book. "}})
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "/movie/238" {
+		t.Fatalf("got status %d to %q, want %d to /movie/238", w.Code, w.Header().Get("Location"), http.StatusFound)
+	}
+	body := do(s.movie, newRequest("GET", "/movie/238"), acc).Body.String()
+	if !strings.Contains(body, "Better than the book.") || !strings.Contains(body, `value="4.5" selected`) {
+		t.Error("movie page does not contain the review")
+	}
+	do(s.addItem, newRequest("GET", "/add/movie/238"), acc)
+	do(s.watchItem, newForm("/watch/movie/238", nil), acc)
+	body = do(s.browse, newRequest("GET", "/browse"), acc).Body.String()
+	if !strings.Contains(body, "Better than the book.") || !strings.Contains(body, "star_half") {
+		t.Error("browse page does not contain the review")
+	}
+
+	w = post("/review/tv/1396", url.Values{"stars": {"3"}, "back": {"browse"}})
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "/browse" {
+		t.Errorf("got status %d to %q, want %d to /browse", w.Code, w.Header().Get("Location"), http.StatusFound)
+	}
+	if rev, _ := s.reviews.Get(acc.ProfileKey(0), client.Item{MediaType: client.MediaTV, MediaID: 1396}); rev == nil || rev.Stars != 3 {
+		t.Errorf("tv show review = %+v, want 3 stars", rev)
+	}
+	for _, stars := range []string{"4.25", "6", "five"} {
+		if w := post("/review/movie/238", url.Values{"stars": {stars}}); w.Code != http.StatusBadRequest {
+			t.Errorf("stars %s got status %d, want %d", stars, w.Code, http.StatusBadRequest)
+		}
+	}
+	if w := do(s.reviewItem, newRequest("GET", "/review/movie/238"), acc); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET got status %d, want %d", w.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestDiary(t *testing.T) {
+	s, fake, acc := newTestServer(t)
+	do(s.addItem, newRequest("GET", "/add/movie/238"), acc)
+	do(s.watchItem, newForm("/watch/movie/238", url.Values{"date": {"2025-12-24"}, "note": {"with family"}}), acc)
+	do(s.watchItem, newForm("/watch/movie/238", nil), acc)
+	do(s.watchItem, newForm("/watch/tv/1396", url.Values{"date": {"2025-06-01"}}), acc)
+	for _, date := range []string{"24/12/2025", "2999-01-01"} {
+		r := newForm("/watch/movie/550", url.Values{"date": {date}})
+		if w := do(s.watchItem, r, acc); w.Code != http.StatusBadRequest {
+			t.Errorf("date %s got status %d, want %d", date, w.Code, http.StatusBadRequest)
+		}
+	}
+	entries, err := s.diary.Entries(acc.ProfileKey(0), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 || !entries[0].Rewatch || entries[1].Note != "with family" {
+		t.Fatalf("got entries %+v", entries)
+	}
+	body := do(s.showDiary, newRequest("GET", "/diary"), acc).Body.String()
+	for _, want := range []string{"The Godfather", "Breaking Bad", "with family", "2025-12-24", "Rewatch"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("diary page does not contain %q", want)
+		}
+	}
+	body = do(s.showDiary, newRequest("GET", "/diary?year=2025&month=6"), acc).Body.String()
+	if !strings.Contains(body, "Breaking Bad") || strings.Contains(body, "The Godfather") {
+		t.Error("diary of June 2025 does not have only Breaking Bad")
+	}
+	// The titles are cached.
+	fake.RemoveMedia(client.MediaMovie, 238)
+	if body := do(s.showDiary, newRequest("GET", "/diary"), acc).Body.String(); !strings.Contains(body, "The Godfather") {
+		t.Error("diary page does not contain the cached title")
+	}
+
+	r := newRequest("POST", "/diary/delete")
+	r.Form = url.Values{"id": {strconv.Itoa(entries[2].ID)}}
+	if w := do(s.deleteDiaryEntry, r, acc); w.Code != http.StatusFound {
+		t.Fatalf("delete got status %d, want %d", w.Code, http.StatusFound)
+	}
+	entries, _ = s.diary.Entries(acc.ProfileKey(0), 0, 0)
+	if len(entries) != 2 {
+		t.Errorf("got %d entries after delete, want 2", len(entries))
+	}
+}
+
+func TestDiaryBackfill(t *testing.T) {
+	s, _, acc := newTestServer(t)
+	p := acc.Profiles[0]
+	// Fight Club was watched before the diary was kept.
+	if _, err := s.client.AddItems(p.WatchedListID, client.MovieItem(550)); err != nil {
+		t.Fatal(err)
+	}
+	do(s.watchItem, newForm("/watch/movie/238", url.Values{"date": {"2025-12-24"}}), acc)
+	body := do(s.showDiary, newRequest("GET", "/diary"), acc).Body.String()
+	if !strings.Contains(body, "Fight Club") || !strings.Contains(body, "Undated") {
+		t.Error("diary page does not contain the undated Fight Club")
+	}
+	// The backfill is done once, the deleted entries stay deleted.
+	entries, _ := s.diary.Entries(acc.ProfileKey(0), 0, 0)
+	if len(entries) != 2 || !entries[1].WatchedAt.IsZero() {
+		t.Fatalf("got entries %+v, want Fight Club undated", entries)
+	}
+	s.diary.Delete(acc.ProfileKey(0), entries[1].ID)
+	do(s.showDiary, newRequest("GET", "/diary"), acc)
+	if entries, _ := s.diary.Entries(acc.ProfileKey(0), 0, 0); len(entries) != 1 {
+		t.Errorf("got %d entries, want the undated deleted", len(entries))
+	}
+	body = do(s.browse, newRequest("GET", "/browse"), acc).Body.String()
+	if !strings.Contains(body, "Watched again") {
+		t.Error("browse page has no rewatch of the watched list")
+	}
+}
+
+func TestStatsCache(t *testing.T) {
+	sc := newStatsCache()
+	en, pt := &stats.Stats{Watches: 1}, &stats.Stats{Watches: 2}
+	sc.set("a@b.com/0", "en", en)
+	sc.set("a@b.com/0", "pt", pt)
+	if st, ok := sc.get("a@b.com/0", "pt"); !ok || st != pt {
+		t.Errorf("get pt = %v, %v, want the pt stats", st, ok)
+	}
+	if _, ok := sc.get("a@b.com/0", "es"); ok {
+		t.Error("got stats of a language not computed")
+	}
+	sc.invalidate("a@b.com/0")
+	for _, lang := range []string{"en", "pt"} {
+		if _, ok := sc.get("a@b.com/0", lang); ok {
+			t.Errorf("got %s stats after invalidate", lang)
+		}
+	}
+}
+
+func TestStats(t *testing.T) {
+	s, _, acc := newTestServer(t)
+	p := acc.Profiles[0]
+	if _, err := s.client.AddItems(p.WatchedListID, client.MovieItem(550), client.Item{MediaType: client.MediaTV, MediaID: 1396}); err != nil {
+		t.Fatal(err)
+	}
+	do(s.watchItem, newForm("/watch/movie/238", nil), acc)
+	r := newRequest("POST", "/review/movie/238")
+	r.Form = url.Values{"stars": {"5"}}
+	do(s.reviewItem, r, acc)
+
+	body := do(s.showStats, newRequest("GET", "/stats"), acc).Body.String()
+	for _, want := range []string{"Movies: 2", "Watches: 1", "Current streak (days): 1", "Drama", "1970s", "is 10.0"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("stats page does not contain %q", want)
+		}
+	}
+	// The stats are cached until the profile watches again.
+	st, err := s.profileStats(s.client, acc, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cached, _ := s.profileStats(s.client, acc, 0); cached != st {
+		t.Error("stats were not cached")
+	}
+	do(s.watchItem, newForm("/watch/movie/238", nil), acc)
+	st, err = s.profileStats(s.client, acc, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Watches != 2 || st.Movies != 2 {
+		t.Errorf("got %d watches of %d movies after rewatch, want 2 of 2", st.Watches, st.Movies)
+	}
+}
+
+func TestYearInReview(t *testing.T) {
+	s, _, acc := newTestServer(t)
+	do(s.watchItem, newForm("/watch/movie/238", url.Values{"date": {"2025-03-01"}}), acc)
+	do(s.watchItem, newForm("/watch/movie/550", url.Values{"date": {"2025-11-20"}}), acc)
+	r := newRequest("POST", "/review/movie/238")
+	r.Form = url.Values{"stars": {"4.5"}}
+	do(s.reviewItem, r, acc)
+
+	body := do(s.yearInReview, newRequest("GET", "/yearinreview?year=2025"), acc).Body.String()
+	for _, want := range []string{"You watched 2 movies, 2 times", "Top rated", "The Godfather", "Last of the year: Fight Club", "/year/"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("year in review page does not contain %q", want)
+		}
+	}
+	yr := new(yearReview)
+	if _, err := s.store.Get(yearReviewsBucket, yearReviewKey(acc.ProfileKey(0), 2025), yr); err != nil || yr.Token == "" {
+		t.Fatalf("got stored review %+v, %v", yr, err)
+	}
+	// A watch logged later in the year is reviewed.
+	do(s.watchItem, newForm("/watch/movie/680", url.Values{"date": {"2025-12-01"}}), acc)
+	body = do(s.yearInReview, newRequest("GET", "/yearinreview?year=2025"), acc).Body.String()
+	if !strings.Contains(body, "You watched 3 movies, 3 times") {
+		t.Error("the review was not computed again after a new watch")
+	}
+	// The unchanged review is served from the store.
+	stored := new(yearReview)
+	s.store.Get(yearReviewsBucket, yearReviewKey(acc.ProfileKey(0), 2025), stored)
+	stored.Review.Movies = 99
+	s.store.Put(yearReviewsBucket, yearReviewKey(acc.ProfileKey(0), 2025), stored)
+	body = do(s.yearInReview, newRequest("GET", "/yearinreview?year=2025"), acc).Body.String()
+	if !strings.Contains(body, "You watched 99 movies") {
+		t.Error("the unchanged review was computed again")
+	}
+	// The shared page does not need login.
+	w := httptest.NewRecorder()
+	s.sharedYearReview(w, httptest.NewRequest("GET", "/year/"+yr.Token, nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "The Godfather") {
+		t.Errorf("shared page got status %d without the review", w.Code)
+	}
+	w = httptest.NewRecorder()
+	s.sharedYearReview(w, httptest.NewRequest("GET", "/year/invalid", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("invalid token got status %d, want %d", w.Code, http.StatusNotFound)
+	}
+
+	// The profiles without watches in the year are skipped.
+	do(s.browse, newRequest("GET", "/browse"), acc)
+	if !s.sendYearReviews(2024) {
+		t.Error("sendYearReviews of 2024 failed")
+	}
+	if found, err := s.store.Get(yearReviewsBucket, yearReviewKey(acc.ProfileKey(0), 2024), new(yearReview)); err != nil || found {
+		t.Errorf("got review of 2024 stored %v, %v, want none", found, err)
+	}
+	// The watches keep the profile to email the review.
+	p, err := s.reviewProfile(acc.ProfileKey(0))
+	if err != nil || p.Email != acc.Email || p.Profile.Name != acc.Profiles[0].Name {
+		t.Errorf("got review profile %+v, %v", p, err)
+	}
+	if _, err := s.reviewProfile("unknown"); err == nil {
+		t.Error("got review profile of an unknown profile")
+	}
+	// The years sent are not sent again.
+	s.store.Put(yearReviewRunsBucket, "2025", &yearReviewRun{Done: true})
+	s.checkYearReviews(time.Date(2026, 1, 2, 10, 30, 0, 0, time.UTC))
+	if len(s.yearReviews) != 0 {
+		t.Error("the reviews of a year sent were sent again")
+	}
+	if got := reviewYear(time.Date(2027, 1, 2, 0, 0, 0, 0, time.UTC)); got != 2026 {
+		t.Errorf("reviewYear in January = %d, want 2026", got)
+	}
+}
+
+func TestYearInReviewMail(t *testing.T) {
+	godfather := &stats.Highlight{ID: 238, Title: "The Godfather", Runtime: 175}
+	rev := &stats.YearReview{
+		Year: 2025, Movies: 1, Watches: 2, Minutes: 350,
+		TopGenre: stats.Count{Name: "Drama"},
+		Longest:  godfather, First: godfather, Last: godfather,
+	}
+	want := &mail.YearInReview{
+		Year: 2025, Movies: 1, Watches: 2, Hours: 350.0 / 60,
+		Longest: "The Godfather", LongestRuntime: 175, First: "The Godfather", Last: "The Godfather",
+	}
+	if got := yearInReviewMail(rev); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	rev.TopRated = []stats.Highlight{*godfather}
+	rev.TopGenre.Count = 1
+	want.TopRated, want.TopGenre = "The Godfather", "Drama"
+	if got := yearInReviewMail(rev); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestBrowseSort(t *testing.T) {
+	s, _, acc := newTestServer(t)
+	do(s.addItem, newRequest("GET", "/add/movie/13"), acc)
+	do(s.addItem, newRequest("GET", "/add/movie/550"), acc)
+
+	fightClubFirst := func(target string) bool {
+		body := do(s.browse, newRequest("GET", target), acc).Body.String()
+		return strings.Index(body, ">Fight Club<") < strings.Index(body, ">Forrest Gump<")
+	}
+	if fightClubFirst("/browse") {
+		t.Error("default order is not the date added")
+	}
+	if !fightClubFirst("/browse?sort=title.asc") {
+		t.Error("sort by title did not put Fight Club first")
+	}
+	if fightClubFirst("/browse?sort=invalid") {
+		t.Error("invalid sort did not use the default order")
+	}
+}
+
+func TestCommentItem(t *testing.T) {
+	s, fake, acc := newTestServer(t)
+	do(s.addItem, newRequest("GET", "/add/movie/550"), acc)
+
+	r := newRequest("POST", "/comment/movie/550")
+	r.Form = url.Values{"comment": {" recommended by Ana "}}
+	w := do(s.commentItem, r, acc)
+	if w.Code != http.StatusFound {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusFound)
+	}
+	l, _ := fake.List(acc.Profiles[0].WatchListID)
+	if got := l.Comments["movie/550"]; got != "recommended by Ana" {
+		t.Errorf("comment = %q, want %q", got, "recommended by Ana")
+	}
+	body := do(s.browse, newRequest("GET", "/browse"), acc).Body.String()
+	if !strings.Contains(body, "recommended by Ana") {
+		t.Error("browse page does not contain the comment")
+	}
+
+	w = do(s.commentItem, newRequest("GET", "/comment/movie/550"), acc)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET: got status %d, want %d", w.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestLinkTMDB(t *testing.T) {
+	s, _, acc := newTestServer(t)
+	w := do(s.linkTMDB, newRequest("POST", "/tmdb/link"), acc)
+	if w.Code != http.StatusFound {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusFound)
+	}
+	settings, err := account.LoadSettings(s.store, acc.Email)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings.TMDBRequestToken == "" {
+		t.Fatal("request token not saved")
+	}
+	want := client.ApproveURL(settings.TMDBRequestToken)
+	if got := w.Header().Get("Location"); got != want {
+		t.Errorf("redirected to %q, want %q", got, want)
+	}
+}
+
+func TestTMDBCallbackWithoutLink(t *testing.T) {
+	s, _, acc := newTestServer(t)
+	w := do(s.tmdbCallback, newRequest("GET", "/tmdb/callback"), acc)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("got status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestClientFor(t *testing.T) {
+	s, _, acc := newTestServer(t)
+	if s.clientFor(acc) != s.client {
+		t.Error("account not linked does not use the app client")
+	}
+	acc.Settings.TMDBAccessToken = "user-token"
+	if s.clientFor(acc) == s.client {
+		t.Error("linked account uses the app client")
+	}
+	acc.Settings.Language = "pt-BR"
+	if code := s.clientFor(acc).LanguageCode(); code != "pt" {
+		t.Errorf("LanguageCode = %q, want pt", code)
+	}
+}
+
+// linkAccount links acc to a new TMDB account of the fake and
+// moves the profiles' lists to it, it returns the account ID.
+func linkAccount(t *testing.T, s *server, acc *account.Account) string {
+	requestToken, err := s.client.CreateRequestToken("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := s.client.CreateAccessToken(requestToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	profiles, err := acc.CopyLists(s.client, s.client.WithToken(token.AccessToken))
+	if err != nil {
+		t.Fatal(err)
+	}
+	acc.Profiles = profiles
+	acc.Settings.TMDBAccessToken = token.AccessToken
+	acc.Settings.TMDBAccountID = token.AccountID
+	if err := acc.Settings.Save(s.store, acc.Email); err != nil {
+		t.Fatal(err)
+	}
+	return token.AccountID
+}
+
+func TestTMDBSync(t *testing.T) {
+	s, fake, acc := newTestServer(t)
+	accountID := linkAccount(t, s, acc)
+	fake.AddAccountMovie(accountID, client.AccountRated, 550, 8)
+	fake.AddAccountMovie(accountID, client.AccountFavorites, 238, 0)
+	fake.AddAccountMovie(accountID, client.AccountWatchlist, 13, 0)
+	fake.AddAccountMovie(accountID, client.AccountWatchlist, 550, 0)
+
+	w := do(s.tmdbSync, newRequest("POST", "/tmdb/sync"), acc)
+	if w.Code != http.StatusFound {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusFound)
+	}
+	p := acc.Profiles[0]
+	watch, _ := fake.List(p.WatchListID)
+	watched, _ := fake.List(p.WatchedListID)
+	if strings.Join(watch.Items, ",") != "movie/13" {
+		t.Errorf("WatchList items = %v, want [movie/13]", watch.Items)
+	}
+	if strings.Join(watched.Items, ",") != "movie/550,movie/238" {
+		t.Errorf("WatchedList items = %v, want [movie/550 movie/238]", watched.Items)
+	}
+	settings, _ := account.LoadSettings(s.store, acc.Email)
+	if settings.Sync == nil || settings.Sync.WatchListID != p.WatchListID || settings.Sync.LastSync.IsZero() {
+		t.Fatalf("sync not saved: %+v", settings.Sync)
+	}
+	// The movies watched are logged in the diary, the
+	// rated ones on the day they were rated, the newest first.
+	entries, _ := s.diary.Entries(acc.ProfileKey(0), 0, 0)
+	var logged []string
+	for _, e := range entries {
+		logged = append(logged, e.Item.Key()+" "+e.WatchedAt.Format("2006"))
+	}
+	if strings.Join(logged, ",") != "movie/238 "+time.Now().Format("2006")+",movie/550 2020" {
+		t.Errorf("diary entries = %v, want movie/238 today and movie/550 in 2020", logged)
+	}
+	// The ratings are imported as stars.
+	if rev, _ := s.reviews.Get(acc.ProfileKey(0), client.MovieItem(550)); rev == nil || rev.Stars != 4 {
+		t.Errorf("review of movie/550 = %+v, want 4 stars", rev)
+	}
+
+	// The periodic sync adds only the new movies.
+	if _, err := s.profileStats(s.clientFor(acc), acc, 0); err != nil {
+		t.Fatal(err)
+	}
+	fake.AddAccountMovie(accountID, client.AccountFavorites, 680, 0)
+	s.syncAll()
+	watched, _ = fake.List(p.WatchedListID)
+	if strings.Join(watched.Items, ",") != "movie/550,movie/238,movie/680" {
+		t.Errorf("WatchedList items = %v", watched.Items)
+	}
+	// The cached stats are dropped.
+	st, err := s.profileStats(s.clientFor(acc), acc, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Movies != 3 {
+		t.Errorf("got %d movies after sync, want 3", st.Movies)
+	}
+
+	r := newRequest("POST", "/tmdb/sync")
+	r.Form = url.Values{"stop": {"1"}}
+	do(s.tmdbSync, r, acc)
+	if settings, _ := account.LoadSettings(s.store, acc.Email); settings.Sync != nil {
+		t.Error("sync not stopped")
+	}
+}
+
+func TestWatchItemRating(t *testing.T) {
+	s, fake, acc := newTestServer(t)
+	accountID := linkAccount(t, s, acc)
+	do(s.addItem, newRequest("GET", "/add/movie/680"), acc)
+	w := do(s.watchItem, newForm("/watch/movie/680", url.Values{"rating": {"7.5"}}), acc)
+	if w.Code != http.StatusFound {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusFound)
+	}
+	if v, ok := fake.Rating(accountID, 680); !ok || v != 7.5 {
+		t.Errorf("rating = %v, %v, want 7.5", v, ok)
+	}
+	if l, _ := fake.List(acc.Profiles[0].WatchedListID); len(l.Items) != 1 {
+		t.Errorf("WatchedList items = %v, want [movie/680]", l.Items)
+	}
+}
+
+func TestSettingsPageLinked(t *testing.T) {
+	s, _, acc := newTestServer(t)
+	w := do(s.settings, newRequest("GET", "/settings"), acc)
+	if !strings.Contains(w.Body.String(), "Link TMDB account") {
+		t.Error("settings page does not offer to link TMDB")
+	}
+	linkAccount(t, s, acc)
+	acc.Settings.Sync = &account.Sync{Profile: "Ana", LastSync: time.Now()}
+	w = do(s.settings, newRequest("GET", "/settings"), acc)
+	body := w.Body.String()
+	if !strings.Contains(body, "Unlink TMDB account") || !strings.Contains(body, "Last sync:") {
+		t.Errorf("settings page does not show the linked account:\n%s", body)
+	}
+}
+
+func TestSettingsLanguage(t *testing.T) {
+	s, _, acc := newTestServer(t)
+	r := newRequest("POST", "/settings")
+	r.Form = url.Values{"language": {"pt-BR"}}
+	if w := do(s.settings, r, acc); w.Code != http.StatusFound {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusFound)
+	}
+	settings, err := account.LoadSettings(s.store, acc.Email)
+	if err != nil || settings.Language != "pt-BR" {
+		t.Fatalf("language not saved: %+v, %v", settings, err)
+	}
+	body := do(s.movie, newRequest("GET", "/movie/238"), acc).Body.String()
+	if !strings.Contains(body, "O Poderoso Chefão") {
+		t.Error("movie page is not translated")
+	}
+	// Without translation the original title is displayed.
+	body = do(s.movie, newRequest("GET", "/movie/550"), acc).Body.String()
+	if !strings.Contains(body, "Fight Club") {
+		t.Error("movie page without translation does not contain the original title")
+	}
+
+	r = newRequest("POST", "/settings")
+	r.Form = url.Values{"language": {"xx-XX"}}
+	do(s.settings, r, acc)
+	if acc.Settings.Language != "" {
+		t.Errorf("unknown language %q was saved", acc.Settings.Language)
+	}
+}
+
+func TestSettingsKeepSync(t *testing.T) {
+	s, _, acc := newTestServer(t)
+	// A sync stored after the account settings were loaded.
+	lastSync := time.Now().Truncate(time.Second)
+	err := account.UpdateSettings(s.store, acc.Email, func(st *account.Settings) error {
+		st.Sync = &account.Sync{Profile: "Ana", LastSync: lastSync}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newRequest("POST", "/settings")
+	r.Form = url.Values{"country": {"BR"}}
+	do(s.settings, r, acc)
+	settings, _ := account.LoadSettings(s.store, acc.Email)
+	if settings.Country != "BR" || settings.Sync == nil || !settings.Sync.LastSync.Equal(lastSync) {
+		t.Errorf("got settings %+v, want the country saved and the sync kept", settings)
+	}
+}
+
+func TestUILanguage(t *testing.T) {
+	s, _, acc := newTestServer(t)
+	r := newRequest("GET", "/browse")
+	r.Header.Set("Accept-Language", "pt-BR,pt;q=0.9,en;q=0.8")
+	body := do(s.browse, r, acc).Body.String()
+	if !strings.Contains(body, `<html lang="pt">`) || !strings.Contains(body, "Para assistir") {
+		t.Error("browse page is not in the Accept-Language language")
+	}
+	body = do(s.browse, newRequest("GET", "/browse"), acc).Body.String()
+	if !strings.Contains(body, `<html lang="en">`) || !strings.Contains(body, "Watch List") {
+		t.Error("browse page without Accept-Language is not in English")
+	}
+	// The account language wins over Accept-Language.
+	acc.Settings.Language = "pt-BR"
+	r = newRequest("GET", "/settings")
+	r.Header.Set("Accept-Language", "en")
+	body = do(s.settings, r, acc).Body.String()
+	if !strings.Contains(body, "Onde assistir") {
+		t.Error("settings page is not in the account language")
+	}
+}
+
+func TestImport(t *testing.T) {
+	s, fake, acc := newTestServer(t)
+	p := acc.Profiles[0]
+	do(s.addItem, newRequest("GET", "/add/movie/238"), acc)
+
+	var zipped bytes.Buffer
+	zw := zip.NewWriter(&zipped)
+	for name, content := range map[string]string{
+		"watched.csv": "Date,Name,Year,Letterboxd URI\n" +
+			"2024-05-01,The Godfather,1972,https://boxd.it/a\n" +
+			"2024-05-02,Nowhere Movie,2001,https://boxd.it/b\n",
+		"diary.csv": "Date,Name,Year,Letterboxd URI,Rating,Rewatch,Tags,Watched Date\n" +
+			"2024-05-01,The Godfather,1972,https://boxd.it/c,4.5,,,2024-04-30\n",
+		"watchlist.csv": "Date,Name,Year,Letterboxd URI\n" +
+			"2024-05-03,Pulp Fiction,1994,https://boxd.it/d\n",
+	} {
+		f, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(content))
+	}
+	zw.Close()
+	upload := func(source string) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		mw.WriteField("source", source)
+		f, _ := mw.CreateFormFile("file", "letterboxd.zip")
+		f.Write(zipped.Bytes())
+		mw.Close()
+		r := httptest.NewRequest("POST", "/import", &body)
+		r.Header.Set("Content-Type", mw.FormDataContentType())
+		r.AddCookie(&http.Cookie{Name: "profile", Value: "0"})
+		return do(s.importHistory, r, acc)
+	}
+	if w := upload(importer.IMDb); w.Code != http.StatusBadRequest {
+		t.Errorf("import with wrong source got status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	if w := upload(importer.Letterboxd); w.Code != http.StatusFound {
+		t.Fatalf("import got status %d, want %d", w.Code, http.StatusFound)
+	}
+	// The watched movie leaves the watch list.
+	if l, _ := fake.List(p.WatchListID); len(l.Items) != 1 || l.Items[0] != "movie/680" {
+		t.Errorf("WatchList items = %v, want [movie/680]", l.Items)
+	}
+	if l, _ := fake.List(p.WatchedListID); len(l.Items) != 1 || l.Items[0] != "movie/238" {
+		t.Errorf("WatchedList items = %v, want [movie/238]", l.Items)
+	}
+	key := acc.ProfileKey(0)
+	entries, err := s.diary.Entries(key, 2024, time.April)
+	if err != nil || len(entries) != 1 || entries[0].Item != client.MovieItem(238) {
+		t.Errorf("got diary entries %+v, %v", entries, err)
+	}
+	if rev, err := s.reviews.Get(key, client.MovieItem(238)); err != nil || rev == nil || rev.Stars != 4.5 {
+		t.Errorf("got review %+v, %v, want 4.5 stars", rev, err)
+	}
+	body := do(s.importHistory, newRequest("GET", "/import"), acc).Body.String()
+	for _, want := range []string{"1 watched, 1 on the watch list, 1 diary entries and 1 ratings added, 1 titles not found", "Nowhere Movie"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("import page does not contain %q", want)
+		}
+	}
+
+	// The unmatched row is resolved by hand, a wrong ID keeps it waiting.
+	resolve := func(v string) {
+		r := newRequest("POST", "/import/resolve")
+		r.Form = url.Values{"tmdb-0": {v}}
+		if w := do(s.resolveImport, r, acc); w.Code != http.StatusFound {
+			t.Fatalf("resolve got status %d, want %d", w.Code, http.StatusFound)
+		}
+	}
+	resolve("999999")
+	var st imports
+	if _, err := s.store.Get(importsBucket, key, &st); err != nil || len(st.Unmatched) != 1 {
+		t.Fatalf("got unmatched %+v, %v, want Nowhere Movie", st.Unmatched, err)
+	}
+	resolve("https://www.themoviedb.org/movie/550-fight-club")
+	if l, _ := fake.List(p.WatchedListID); len(l.Items) != 2 || l.Items[1] != "movie/550" {
+		t.Errorf("WatchedList items = %v, want [movie/238 movie/550]", l.Items)
+	}
+	st = imports{}
+	if _, err := s.store.Get(importsBucket, key, &st); err != nil || len(st.Unmatched) != 0 || st.Last.Watched != 1 {
+		t.Errorf("got imports %+v, %v, want all resolved", st, err)
+	}
+}
+
+func TestTMDBItem(t *testing.T) {
+	tests := []struct {
+		v    string
+		want client.Item
+		ok   bool
+	}{
+		{" 238 ", client.MovieItem(238), true},
+		{"https://www.themoviedb.org/tv/1396-breaking-bad?language=pt", client.TVItem(1396), true},
+		{"https://www.themoviedb.org/movie/550", client.MovieItem(550), true},
+		{"themoviedb.org/person/31", client.Item{}, false},
+		{"", client.Item{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := tmdbItem(tt.v, client.MediaMovie)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("tmdbItem(%q) = %v, %v, want %v, %v", tt.v, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestImportMatchesFailedAdd(t *testing.T) {
+	s, fake, acc := newTestServer(t)
+	p := acc.Profiles[0]
+	do(s.addItem, newRequest("GET", "/add/movie/238"), acc)
+	// TMDB fails to add the movie to WatchedList.
+	fake.RemoveMedia(client.MediaMovie, 238)
+	row := importer.Row{Title: "The Godfather", MediaType: client.MediaMovie, Watched: true}
+	result, unmatched, err := s.importMatches(acc, 0, []importer.Match{{Row: row, Item: client.MovieItem(238)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Watched != 0 || len(unmatched) != 1 || unmatched[0].Title != row.Title {
+		t.Errorf("got result %+v and unmatched %+v, want the movie unmatched", result, unmatched)
+	}
+	if l, _ := fake.List(p.WatchListID); len(l.Items) != 1 || l.Items[0] != "movie/238" {
+		t.Errorf("WatchList items = %v, want [movie/238]", l.Items)
+	}
+}
@@ -0,0 +1,111 @@
+// Package i18n translates the messages of the web pages and the
+// emails. Messages are keyed by their English text, each language
+// has its catalog in its own file, e.g. pt.go, and messages missing
+// from a catalog are displayed in English.
+package i18n
+
+import (
+	"fmt"
+	"html/template"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLanguage is the language of the messages keys.
+const DefaultLanguage = "en"
+
+// Catalog maps the English messages to their translation.
+type Catalog map[string]string
+
+// catalogs maps the ISO 639-1 code of the supported languages
+// to their catalog.
+var catalogs = map[string]Catalog{
+	DefaultLanguage: {},
+	"pt":            pt,
+}
+
+// Languages returns the ISO 639-1 codes of the supported languages.
+func Languages() []string {
+	langs := make([]string, 0, len(catalogs))
+	for lang := range catalogs {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// T translates msg to lang, if msg has formatting verbs they are
+// replaced by args like in fmt.Sprintf.
+func T(lang, msg string, args ...interface{}) string {
+	if tr, ok := catalogs[lang][msg]; ok {
+		msg = tr
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// FuncMap returns the template functions of lang: t translates a
+// message like T and lang returns the language code, e.g. for the
+// lang attribute of html.
+func FuncMap(lang string) template.FuncMap {
+	return template.FuncMap{
+		"t": func(msg string, args ...interface{}) string {
+			return T(lang, msg, args...)
+		},
+		"lang": func() string { return lang },
+	}
+}
+
+// Match returns the first supported language of tags, e.g. "pt"
+// for "pt-BR", or DefaultLanguage if none is supported.
+func Match(tags ...string) string {
+	for _, tag := range tags {
+		lang := strings.ToLower(strings.SplitN(tag, "-", 2)[0])
+		if _, ok := catalogs[lang]; ok {
+			return lang
+		}
+	}
+	return DefaultLanguage
+}
+
+// ParseAcceptLanguage returns the language tags of an Accept-Language
+// header, e.g. "pt-BR,pt;q=0.9,en;q=0.8", from the most to the least
+// preferred. Tags with q=0 and the wildcard are ignored.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		tag := strings.TrimSpace(fields[0])
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		for _, f := range fields[1:] {
+			f = strings.TrimSpace(f)
+			if strings.HasPrefix(f, "q=") {
+				v, err := strconv.ParseFloat(f[2:], 64)
+				if err != nil {
+					v = 0
+				}
+				q = v
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		tags = append(tags, weighted{tag, q})
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+	out := make([]string, len(tags))
+	for i, t := range tags {
+		out[i] = t.tag
+	}
+	return out
+}
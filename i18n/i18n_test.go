@@ -0,0 +1,55 @@
+package i18n
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		header string
+		want   []string
+	}{
+		{"", []string{}},
+		{"pt-BR", []string{"pt-BR"}},
+		{"en;q=0.5, pt-BR,pt;q=0.9", []string{"pt-BR", "pt", "en"}},
+		{"fr;q=0, *;q=0.1, es", []string{"es"}},
+	}
+	for _, tt := range tests {
+		if got := ParseAcceptLanguage(tt.header); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseAcceptLanguage(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		tags []string
+		want string
+	}{
+		{nil, DefaultLanguage},
+		{[]string{"pt-BR"}, "pt"},
+		{[]string{"es-ES", "PT"}, "pt"},
+		{[]string{"es-ES", "fr"}, DefaultLanguage},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.tags...); got != tt.want {
+			t.Errorf("Match(%q) = %q, want %q", tt.tags, got, tt.want)
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	if got := T("pt", "Browse"); got != "Navegar" {
+		t.Errorf("T(pt, Browse) = %q", got)
+	}
+	if got := T("pt", "%d votes", 3); got != "3 votos" {
+		t.Errorf("T(pt, %%d votes) = %q", got)
+	}
+	if got := T("pt", "not translated"); got != "not translated" {
+		t.Errorf("missing translation = %q, want the English message", got)
+	}
+	if got := T("xx", "%d votes", 3); got != "3 votes" {
+		t.Errorf("unknown language = %q, want the English message", got)
+	}
+}
@@ -0,0 +1,218 @@
+package i18n
+
+// pt is the Portuguese catalog.
+var pt = Catalog{
+	// Navigation and common.
+	"Browse":                "Navegar",
+	"Discover":              "Descobrir",
+	"Export lists":          "Exportar listas",
+	"Login":                 "Entrar",
+	"Logout":                "Sair",
+	"Next":                  "Próxima",
+	"Prev":                  "Anterior",
+	"Profiles":              "Perfis",
+	"Save":                  "Salvar",
+	"Settings":              "Configurações",
+	"Submit":                "Enviar",
+	"%d min":                "%d min",
+	"%d votes":              "%d votos",
+	"Add to list":           "Adicionar à lista",
+	"Cast":                  "Elenco",
+	"Move to watched list":  "Mover para assistidos",
+	"No trailer available.": "Nenhum trailer disponível.",
+	"Schedule":              "Agendar",
+	"Schedule movie":        "Agendar filme",
+	"as %s":                 "como %s",
+
+	// Login and profiles.
+	"Birthday":    "Data de nascimento",
+	"Email":       "E-mail",
+	"Name":        "Nome",
+	"New Profile": "Novo perfil",
+	"Password":    "Senha",
+	"Sign-in":     "Entrar",
+	"Sign-out":    "Sair",
+
+	// Browse.
+	"Actor or director":          "Ator ou diretor",
+	"Add a comment":              "Adicione um comentário",
+	"Continue watching":          "Continue assistindo",
+	"Movie":                      "Filme",
+	"Only what I can stream now": "Só o que posso assistir agora",
+	"Rate on TMDB":               "Avaliar no TMDB",
+	"Search Movie":               "Buscar filme",
+	"Search Person":              "Buscar pessoa",
+	"Show all":                   "Mostrar tudo",
+	"Sort":                       "Ordenar",
+	"Sort by":                    "Ordenar por",
+	"Sujestions List":            "Sugestões",
+	"TV shows":                   "Séries",
+	"Watch List":                 "Para assistir",
+	"Watched":                    "Assistido",
+	"Watched List":               "Assistidos",
+	"Watched again":              "Assisti de novo",
+	"Because you watched %s":     "Porque você assistiu %s",
+	"Already seen":               "Já assisti",
+	"Not interested":             "Não tenho interesse",
+	"Date added (oldest)":        "Adicionados (mais antigos)",
+	"Date added (newest)":        "Adicionados (mais recentes)",
+	"Title (A-Z)":                "Título (A-Z)",
+	"Title (Z-A)":                "Título (Z-A)",
+	"Best rated":                 "Melhor avaliados",
+	"Newest release":             "Lançamentos mais recentes",
+	"Oldest release":             "Lançamentos mais antigos",
+
+	// Search and discover.
+	"%d results, page %d of %d": "%d resultados, página %d de %d",
+	"Crew":                      "Equipe",
+	"Genres":                    "Gêneros",
+	"IDs separated by comma. Cast and crew are only available for movies.": "IDs separados por vírgula. Elenco e equipe só estão disponíveis para filmes.",
+	"Include adult":     "Incluir adulto",
+	"Keywords":          "Palavras-chave",
+	"Language":          "Idioma",
+	"Movies":            "Filmes",
+	"Original language": "Idioma original",
+	"Region":            "Região",
+	"Released from":     "Lançado de",
+	"Runtime from":      "Duração de",
+	"Search movie":      "Buscar filme",
+	"Search person":     "Buscar pessoa",
+	"Vote average from": "Nota média de",
+	"Vote count from":   "Número de votos de",
+	"Watch providers":   "Serviços de streaming",
+	"Watch region":      "Região de streaming",
+	"Year":              "Ano",
+	"minutes":           "minutos",
+	"to":                "até",
+	"Most popular":      "Mais populares",
+	"Least popular":     "Menos populares",
+	"Worst rated":       "Pior avaliados",
+	"Most voted":        "Mais votados",
+	"Newest":            "Mais recentes",
+	"Oldest":            "Mais antigos",
+	"Highest revenue":   "Maior bilheteria",
+
+	// Movie, person, tv and season.
+	"%d seasons, %d episodes": "%d temporadas, %d episódios",
+	"%d/%d episodes watched":  "%d/%d episódios assistidos",
+	"Directed by":             "Direção",
+	"Mark as watched":         "Marcar como assistido",
+	"Mark season as watched":  "Marcar temporada como assistida",
+	"Next episode: S%dE%d":    "Próximo episódio: T%dE%d",
+	"On watch list":           "Na lista para assistir",
+	"Seasons":                 "Temporadas",
+	"Unmark":                  "Desmarcar",
+	"Unmark season":           "Desmarcar temporada",
+	"You are up to date.":     "Você está em dia.",
+	"%s stars":                "%s estrelas",
+	"My review":               "Minha crítica",
+	"No rating":               "Sem nota",
+	"Write a private review":  "Escreva uma crítica privada",
+
+	// Diary.
+	"All months":  "Todos os meses",
+	"All years":   "Todos os anos",
+	"Delete":      "Excluir",
+	"Diary":       "Diário",
+	"Filter":      "Filtrar",
+	"Log":         "Registrar",
+	"Log a watch": "Registrar sessão",
+	"No movies or tv shows watched in this period.": "Nenhum filme ou série assistido neste período.",
+	"Note":      "Nota",
+	"Rewatch":   "Revisto",
+	"Undated":   "Sem data",
+	"January":   "Janeiro",
+	"February":  "Fevereiro",
+	"March":     "Março",
+	"April":     "Abril",
+	"May":       "Maio",
+	"June":      "Junho",
+	"July":      "Julho",
+	"August":    "Agosto",
+	"September": "Setembro",
+	"October":   "Outubro",
+	"November":  "Novembro",
+	"December":  "Dezembro",
+
+	// Stats.
+	"Movies: %d":                "Filmes: %d",
+	"Watches: %d":               "Sessões: %d",
+	"Hours: %s":                 "Horas: %s",
+	"Current streak (days): %d": "Sequência atual (dias): %d",
+	"Hours":                     "Horas",
+	"Longest streak (days): %d": "Maior sequência (dias): %d",
+	"Month":                     "Mês",
+	"Per month":                 "Por mês",
+	"Stats":                     "Estatísticas",
+	"Top actors":                "Atores mais vistos",
+	"Top decades":               "Décadas mais vistas",
+	"Top directors":             "Diretores mais vistos",
+	"Top genres":                "Gêneros mais vistos",
+	"Top languages":             "Idiomas mais vistos",
+	"Watches":                   "Sessões",
+	"Your average rating of %d movies is %s, their TMDB average is %s.": "Sua nota média de %d filmes é %s, a média deles no TMDB é %s.",
+
+	// Year in review.
+	"%s's %d in movies":          "O %[2]d de %[1]s em filmes",
+	"First of the year: %s":      "Primeiro do ano: %s",
+	"Last of the year: %s":       "Último do ano: %s",
+	"Longest movie: %s (%d min)": "Filme mais longo: %s (%d min)",
+	"Most watched genre: %s":     "Gênero mais assistido: %s",
+	"No movies watched in %d.":   "Nenhum filme assistido em %d.",
+	"Share it:":                  "Compartilhe:",
+	"Show":                       "Mostrar",
+	"Top rated":                  "Melhor avaliados",
+	"Year in review":             "Retrospectiva do ano",
+	"You watched %d movies, %d times, in %s hours.": "Você assistiu %d filmes, %d vezes, em %s horas.",
+
+	// Import.
+	"IMDb ratings (CSV)":  "Avaliações do IMDb (CSV)",
+	"Import":              "Importar",
+	"Import history":      "Importar histórico",
+	"Letterboxd (zip)":    "Letterboxd (zip)",
+	"Skip":                "Ignorar",
+	"Title":               "Título",
+	"Titles not found":    "Títulos não encontrados",
+	"TMDB ID or URL":      "ID ou URL do TMDB",
+	"Trakt (JSON or zip)": "Trakt (JSON ou zip)",
+	"Import the movies and tv shows you watched, rated and want to watch from the export of another site.":       "Importe os filmes e séries que você assistiu, avaliou e quer assistir da exportação de outro site.",
+	"Last import: %d watched, %d on the watch list, %d diary entries and %d ratings added, %d titles not found.": "Última importação: %d assistidos, %d para assistir, %d sessões no diário e %d avaliações adicionados, %d títulos não encontrados.",
+	"Search each title and paste its TMDB ID or page URL, or skip it.":                                           "Busque cada título e cole seu ID ou a URL da página do TMDB, ou ignore-o.",
+
+	// Schedule.
+	"Date":           "Data",
+	"Schedule Movie": "Agendar filme",
+	"Time":           "Hora",
+
+	// Settings.
+	"Choose a country and save to select your providers.": "Escolha um país e salve para selecionar seus serviços.",
+	"Country":             "País",
+	"Default":             "Padrão",
+	"Last sync: %s.":      "Última sincronização: %s.",
+	"Link TMDB account":   "Vincular conta do TMDB",
+	"None":                "Nenhum",
+	"Stop syncing":        "Parar de sincronizar",
+	"TMDB account":        "Conta do TMDB",
+	"Where to watch":      "Onde assistir",
+	"Unlink TMDB account": "Desvincular conta do TMDB",
+	"Link your TMDB account to keep your lists in your own TMDB profile. You will need to login again.":            "Vincule sua conta do TMDB para manter suas listas no seu próprio perfil do TMDB. Você precisará entrar novamente.",
+	"Pages, emails, titles, overviews and trailers in":                                                             "Páginas, e-mails, títulos, sinopses e trailers em",
+	"Providers you are subscribed to:":                                                                             "Serviços que você assina:",
+	"Sync this profile now":                                                                                        "Sincronizar este perfil agora",
+	"Sync this profile with my TMDB ratings, favorites and watchlist":                                              "Sincronizar este perfil com minhas avaliações, favoritos e lista do TMDB",
+	"The country below is also used as the region of release dates.":                                               "O país abaixo também é usado como região das datas de lançamento.",
+	"Your TMDB rated and favorite movies go to the watched list of %s, and your TMDB watchlist to its watch list.": "Seus filmes avaliados e favoritos do TMDB vão para os assistidos de %s, e sua lista do TMDB para a lista para assistir.",
+	"Your lists are in your own TMDB account.":                                                                     "Suas listas estão na sua própria conta do TMDB.",
+
+	// Emails.
+	"App list of movies verification.":     "Verificação do App lista de filmes.",
+	"Click here to verify your email: %s":  "Clique aqui para verificar seu e-mail: %s",
+	"Watch your movie.":                    "Assista seu filme.",
+	"Watch your tv show.":                  "Assista sua série.",
+	"It's time, watch movie with ID: %d":   "Chegou a hora, assista o filme com ID: %d",
+	"It's time, watch tv show with ID: %d": "Chegou a hora, assista a série com ID: %d",
+	"Watch the trailer: %s":                "Assista o trailer: %s",
+	"Your %d in movies":                    "Seu %d em filmes",
+	"Top rated: %s":                        "Melhor avaliado: %s",
+	"See and share it: %s":                 "Veja e compartilhe: %s",
+}
@@ -0,0 +1,353 @@
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/rschio/movieApp/account"
+	"github.com/rschio/movieApp/client"
+	"github.com/rschio/movieApp/diary"
+	"github.com/rschio/movieApp/importer"
+	"github.com/rschio/movieApp/review"
+)
+
+const (
+	// importsBucket is the store bucket of the imports
+	// of the profiles, the keys are profile keys.
+	importsBucket = "imports"
+	// maxImportSize is the max size of an uploaded export.
+	maxImportSize = 32 << 20
+	// maxUnmatched is the number of rows kept waiting to be
+	// resolved for each profile, the oldest are forgotten.
+	maxUnmatched = 500
+)
+
+// importSources are the sources offered in the import form.
+var importSources = []formOption{
+	{Value: importer.Letterboxd, Label: "Letterboxd (zip)"},
+	{Value: importer.IMDb, Label: "IMDb ratings (CSV)"},
+	{Value: importer.Trakt, Label: "Trakt (JSON or zip)"},
+}
+
+// importResult counts the changes of an import.
+type importResult struct {
+	// Watched and Watchlist are the number of items
+	// added to WatchedList and WatchList.
+	Watched   int
+	Watchlist int
+	// Diary is the number of watches logged in the diary.
+	Diary int
+	// Ratings is the number of ratings set.
+	Ratings int
+	// Unmatched is the number of rows that matched no title.
+	Unmatched int
+}
+
+// imports is the state of the imports of a profile.
+type imports struct {
+	// Last is the result of the last import or
+	// resolution, nil if there was none.
+	Last *importResult
+	// Unmatched are the rows of the imports that matched
+	// no title, waiting to be resolved, the oldest first.
+	Unmatched []importer.Row
+}
+
+// importPage is the data used to render import.html.
+type importPage struct {
+	Sources []formOption
+	imports
+}
+
+// rowKey identifies the title of a row, to not keep the
+// same unmatched row twice.
+func rowKey(r importer.Row) string {
+	return r.MediaType + "/" + r.IMDbID + "/" + strings.ToLower(r.Title) + "/" + strconv.Itoa(r.Year)
+}
+
+// addUnmatched returns unmatched with the rows, the ones already
+// in unmatched are moved to the end, up to maxUnmatched rows.
+func addUnmatched(unmatched []importer.Row, rows ...importer.Row) []importer.Row {
+	added := make(map[string]bool)
+	for _, r := range rows {
+		added[rowKey(r)] = true
+	}
+	var out []importer.Row
+	for _, r := range unmatched {
+		if !added[rowKey(r)] {
+			out = append(out, r)
+		}
+	}
+	out = append(out, rows...)
+	if len(out) > maxUnmatched {
+		out = out[len(out)-maxUnmatched:]
+	}
+	return out
+}
+
+// importHistory displays the import form, the result of the last
+// import and the rows waiting to be resolved. A POST request imports
+// the uploaded file, with the param source, one of importer.Sources,
+// into the lists, diary and ratings of the profile.
+func (s *server) importHistory(w http.ResponseWriter, r *http.Request, acc *account.Account) {
+	id, err := account.ProfileFromRequest(r, acc)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	key := acc.ProfileKey(id)
+	if r.Method != "POST" {
+		page := &importPage{Sources: importSources}
+		if _, err := s.store.Get(importsBucket, key, &page.imports); err != nil {
+			log.Println(err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		s.tmpl(r, acc).ExecuteTemplate(w, "import.html", page)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	rows, err := importer.Parse(r.FormValue("source"), data)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Invalid export file", http.StatusBadRequest)
+		return
+	}
+	// The titles of the exports are in English.
+	matches, unmatched := importer.NewMatcher(s.client).MatchAll(rows)
+	result, failed, err := s.importMatches(acc, id, matches)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	s.rememberProfile(r, acc, id)
+	unmatched = append(unmatched, failed...)
+	result.Unmatched = len(unmatched)
+	var st imports
+	err = s.store.Update(importsBucket, key, &st, func() error {
+		st.Last = result
+		st.Unmatched = addUnmatched(st.Unmatched, unmatched...)
+		return nil
+	})
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	s.stats.invalidate(key)
+	s.queueSuggestions(r, acc, id, true)
+	http.Redirect(w, r, "/import", http.StatusFound)
+}
+
+// resolveImport imports the unmatched rows of the profile resolved
+// by hand. The param tmdb-{i} is the TMDB ID, or the TMDB page URL,
+// of the i-th unmatched row, and the param skip-{i} forgets the row
+// without importing it. The rows not resolved keep waiting.
+func (s *server) resolveImport(w http.ResponseWriter, r *http.Request, acc *account.Account) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	id, err := account.ProfileFromRequest(r, acc)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	key := acc.ProfileKey(id)
+	var st imports
+	if _, err := s.store.Get(importsBucket, key, &st); err != nil {
+		log.Println(err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	var matches []importer.Match
+	done := make(map[string]bool)
+	for i, row := range st.Unmatched {
+		n := strconv.Itoa(i)
+		if r.FormValue("skip-"+n) != "" {
+			done[rowKey(row)] = true
+			continue
+		}
+		item, ok := tmdbItem(r.FormValue("tmdb-"+n), row.MediaType)
+		if !ok {
+			continue
+		}
+		done[rowKey(row)] = true
+		matches = append(matches, importer.Match{Row: row, Item: item})
+	}
+	result, failed, err := s.importMatches(acc, id, matches)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	s.rememberProfile(r, acc, id)
+	err = s.store.Update(importsBucket, key, &st, func() error {
+		// Rows may have been added meanwhile.
+		var rest []importer.Row
+		for _, row := range st.Unmatched {
+			if !done[rowKey(row)] {
+				rest = append(rest, row)
+			}
+		}
+		st.Unmatched = addUnmatched(rest, failed...)
+		result.Unmatched = len(st.Unmatched)
+		st.Last = result
+		return nil
+	})
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if len(matches) > 0 {
+		s.stats.invalidate(key)
+		s.queueSuggestions(r, acc, id, true)
+	}
+	http.Redirect(w, r, "/import", http.StatusFound)
+}
+
+// tmdbItem returns the item of v, a TMDB ID of a media of
+// mediaType or the URL of a TMDB movie or tv show page, e.g.
+// https://www.themoviedb.org/movie/238-the-godfather.
+func tmdbItem(v, mediaType string) (client.Item, bool) {
+	v = strings.TrimSpace(v)
+	if id, err := strconv.Atoi(v); err == nil && id > 0 {
+		return client.Item{MediaType: mediaType, MediaID: id}, true
+	}
+	parts := strings.Split(v, "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if parts[i] != client.MediaMovie && parts[i] != client.MediaTV {
+			continue
+		}
+		slug := strings.SplitN(parts[i+1], "-", 2)[0]
+		if id, err := strconv.Atoi(slug); err == nil && id > 0 {
+			return client.Item{MediaType: parts[i], MediaID: id}, true
+		}
+	}
+	return client.Item{}, false
+}
+
+// importMatches adds the watched matches to WatchedList, removing
+// them from WatchList, and the other ones to WatchList, unless they
+// are already watched, of the profile with ID id. The watches of the
+// matches are logged in the diary and their ratings are set, unless
+// the items are already rated. The matches whose items TMDB failed
+// to add, e.g. wrong IDs resolved by hand, are returned as unmatched.
+func (s *server) importMatches(acc *account.Account, id int, matches []importer.Match) (*importResult, []importer.Row, error) {
+	result := new(importResult)
+	if len(matches) == 0 {
+		return result, nil, nil
+	}
+	profile := acc.Profiles[id]
+	c := s.clientFor(acc)
+	var (
+		watch map[string]bool
+		errs  = make(chan error, 1)
+	)
+	go func() {
+		var err error
+		watch, err = listItemKeys(c, profile.WatchListID)
+		errs <- err
+	}()
+	watched, err := listItemKeys(c, profile.WatchedListID)
+	if e := <-errs; e != nil {
+		err = e
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	var toWatched, toWatch, unwatch []client.Item
+	for _, m := range matches {
+		key := m.Item.Key()
+		if !m.Watched || watched[key] {
+			continue
+		}
+		watched[key] = true
+		toWatched = append(toWatched, m.Item)
+		if watch[key] {
+			unwatch = append(unwatch, m.Item)
+		}
+	}
+	for _, m := range matches {
+		key := m.Item.Key()
+		if m.Watched || watched[key] || watch[key] {
+			continue
+		}
+		watch[key] = true
+		toWatch = append(toWatch, m.Item)
+	}
+	failed, err := addItems(c, profile.WatchedListID, toWatched)
+	if err != nil {
+		return nil, nil, err
+	}
+	result.Watched = len(toWatched) - len(failed)
+	// The items not added to WatchedList stay on WatchList.
+	added := unwatch[:0]
+	for _, item := range unwatch {
+		if !failed[item.Key()] {
+			added = append(added, item)
+		}
+	}
+	unwatch = added
+	failedWatch, err := addItems(c, profile.WatchListID, toWatch)
+	if err != nil {
+		return nil, nil, err
+	}
+	result.Watchlist = len(toWatch) - len(failedWatch)
+	for key := range failedWatch {
+		failed[key] = true
+	}
+	for _, batch := range client.Batches(unwatch, listBatchSize) {
+		if _, err := c.DeleteItems(profile.WatchListID, batch...); err != nil {
+			return nil, nil, err
+		}
+	}
+	var (
+		entries   []diary.Entry
+		ratings   []review.Review
+		unmatched []importer.Row
+	)
+	for _, m := range matches {
+		if failed[m.Item.Key()] {
+			unmatched = append(unmatched, m.Row)
+			continue
+		}
+		if !m.Watched {
+			continue
+		}
+		for _, d := range m.Watches {
+			entries = append(entries, diary.Entry{Item: m.Item, WatchedAt: d})
+		}
+		if review.ValidStars(m.Stars) && m.Stars > 0 {
+			ratings = append(ratings, review.Review{Item: m.Item, Stars: m.Stars})
+		}
+	}
+	key := acc.ProfileKey(id)
+	if result.Diary, err = s.diary.Import(key, entries); err != nil {
+		return nil, nil, err
+	}
+	if result.Ratings, err = s.reviews.Import(key, ratings); err != nil {
+		return nil, nil, err
+	}
+	return result, unmatched, nil
+}
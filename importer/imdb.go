@@ -0,0 +1,48 @@
+package importer
+
+import (
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/rschio/movieApp/client"
+)
+
+// ErrNotIMDbRatings is returned when a CSV
+// is not the IMDb ratings export.
+var ErrNotIMDbRatings = errors.New("importer: not an IMDb ratings export")
+
+// ParseIMDb parses the ratings CSV exported by IMDb. The titles are
+// watched, with the rating from 1 to 10 halved into stars, and the
+// days are unknown, the date rated is not the day watched. The
+// episodes are skipped, only their series can be on the lists.
+func ParseIMDb(r io.Reader) ([]Row, error) {
+	t, err := readTable(r)
+	if err != nil {
+		return nil, err
+	}
+	if !t.has("Const", "Your Rating") {
+		return nil, ErrNotIMDbRatings
+	}
+	set := newRowSet()
+	for _, rec := range t.records {
+		mediaType := client.MediaMovie
+		// The types are "Movie", "TV Series", "TV Episode"...,
+		// or "movie", "tvSeries", "tvEpisode"... in old exports.
+		switch strings.ReplaceAll(strings.ToLower(t.get(rec, "Title Type")), " ", "") {
+		case "tvseries", "tvminiseries":
+			mediaType = client.MediaTV
+		case "tvepisode", "podcastepisode":
+			continue
+		}
+		set.add(Row{
+			Title:     t.get(rec, "Title"),
+			Year:      t.getInt(rec, "Year"),
+			IMDbID:    t.get(rec, "Const"),
+			MediaType: mediaType,
+			Watched:   true,
+			Stars:     float64(t.getInt(rec, "Your Rating")) / 2,
+		})
+	}
+	return set.list(), nil
+}
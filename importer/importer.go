@@ -0,0 +1,256 @@
+// Package importer reads the watch history exported by Letterboxd,
+// IMDb and Trakt and matches its titles to TMDB movies and tv shows,
+// by their IMDb IDs or by searching their titles and years.
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sources of the exports.
+const (
+	// Letterboxd is the zip of CSV files exported in
+	// the Letterboxd settings.
+	Letterboxd = "letterboxd"
+	// IMDb is the ratings CSV exported in the IMDb ratings page.
+	IMDb = "imdb"
+	// Trakt is a JSON file, or a zip of JSON files, of the
+	// Trakt export: history, watched, ratings and watchlist.
+	Trakt = "trakt"
+)
+
+// Sources are the supported sources.
+var Sources = []string{Letterboxd, IMDb, Trakt}
+
+// ErrUnknownSource is returned when the source of
+// an export is not one of Sources.
+var ErrUnknownSource = errors.New("importer: unknown source")
+
+// maxUnzippedSize is the max size of the files read from a zip, in
+// total, the exports compress well but a zip bomb would exhaust the
+// memory. It is a var so the tests can lower it.
+var maxUnzippedSize int64 = 64 << 20
+
+// ErrZipTooLarge is returned when the files of a
+// zip are larger than maxUnzippedSize.
+var ErrZipTooLarge = errors.New("importer: zip too large")
+
+// unzipper reads the files of a zip, up to left bytes in total.
+type unzipper struct {
+	left int64
+}
+
+func newUnzipper() *unzipper {
+	return &unzipper{left: maxUnzippedSize}
+}
+
+// read returns the content of f, it fails with ErrZipTooLarge
+// if the files read are larger than the limit.
+func (u *unzipper) read(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	b, err := ioutil.ReadAll(io.LimitReader(rc, u.left+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) > u.left {
+		return nil, ErrZipTooLarge
+	}
+	u.left -= int64(len(b))
+	return b, nil
+}
+
+// Row is a movie or tv show of an export.
+type Row struct {
+	Title string
+	// Year is the release year, zero if unknown.
+	Year int
+	// IMDbID is the IMDb ID, e.g. "tt0068646", empty if unknown.
+	IMDbID string
+	// TMDBID is the TMDB ID, zero if unknown.
+	TMDBID int
+	// MediaType is client.MediaMovie or client.MediaTV.
+	MediaType string
+	// Watched reports if the title was watched, otherwise
+	// it is only on the watchlist.
+	Watched bool
+	// Watches are the days the title was watched, the oldest
+	// first, empty if the days are unknown.
+	Watches []time.Time
+	// Stars is the rating, from 0.5 to 5, zero if not rated.
+	Stars float64
+}
+
+// Parse parses the export data of source. The rows of the same title
+// are merged, a title watched and on the watchlist is watched.
+func Parse(source string, data []byte) ([]Row, error) {
+	switch source {
+	case Letterboxd:
+		return ParseLetterboxd(data)
+	case IMDb:
+		return ParseIMDb(bytes.NewReader(data))
+	case Trakt:
+		return ParseTrakt(data)
+	}
+	return nil, ErrUnknownSource
+}
+
+// rowSet merges the rows of the same title, in the order they
+// are added, the rating of the last row rated is kept.
+type rowSet struct {
+	rows  []*Row
+	index map[string]*Row
+}
+
+func newRowSet() *rowSet {
+	return &rowSet{index: make(map[string]*Row)}
+}
+
+// key identifies the title of r, by its IDs or by its
+// media type, title and year.
+func (r *Row) key() string {
+	switch {
+	case r.IMDbID != "":
+		return r.IMDbID
+	case r.TMDBID > 0:
+		return r.MediaType + "/" + strconv.Itoa(r.TMDBID)
+	}
+	return r.MediaType + "/" + normalize(r.Title) + "/" + strconv.Itoa(r.Year)
+}
+
+func (s *rowSet) add(r Row) {
+	if r.Title == "" && r.IMDbID == "" && r.TMDBID == 0 {
+		return
+	}
+	old, ok := s.index[r.key()]
+	if !ok {
+		row := r
+		s.rows = append(s.rows, &row)
+		s.index[r.key()] = &row
+		return
+	}
+	old.Watched = old.Watched || r.Watched
+	old.Watches = append(old.Watches, r.Watches...)
+	if r.Stars > 0 {
+		old.Stars = r.Stars
+	}
+	if old.TMDBID == 0 {
+		old.TMDBID = r.TMDBID
+	}
+	if old.Year == 0 {
+		old.Year = r.Year
+	}
+}
+
+// list returns the merged rows, with their watches
+// sorted and once per day.
+func (s *rowSet) list() []Row {
+	out := make([]Row, len(s.rows))
+	for i, r := range s.rows {
+		sort.Slice(r.Watches, func(i, j int) bool { return r.Watches[i].Before(r.Watches[j]) })
+		var days []time.Time
+		for _, d := range r.Watches {
+			if len(days) == 0 || !days[len(days)-1].Equal(d) {
+				days = append(days, d)
+			}
+		}
+		r.Watches = days
+		out[i] = *r
+	}
+	return out
+}
+
+// normalize returns s in lower case with its
+// spaces collapsed, to compare titles.
+func normalize(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+// day returns the date of t at 00:00 UTC.
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// table is a CSV file with a header.
+type table struct {
+	cols    map[string]int
+	records [][]string
+}
+
+// readTable reads a CSV file whose first record is the header.
+func readTable(r io.Reader) (*table, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	t := &table{cols: make(map[string]int)}
+	if len(records) == 0 {
+		return t, nil
+	}
+	for i, name := range records[0] {
+		// Excel writes a byte order mark.
+		name = strings.TrimPrefix(name, "\ufeff")
+		t.cols[strings.TrimSpace(name)] = i
+	}
+	t.records = records[1:]
+	return t, nil
+}
+
+// has reports if the table has all the columns cols.
+func (t *table) has(cols ...string) bool {
+	for _, c := range cols {
+		if _, ok := t.cols[c]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// get returns the field of record in the column col,
+// empty if there is no such column.
+func (t *table) get(record []string, col string) string {
+	i, ok := t.cols[col]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// getInt returns the field of record in the column col
+// as an int, zero if it is not a number.
+func (t *table) getInt(record []string, col string) int {
+	n, _ := strconv.Atoi(t.get(record, col))
+	return n
+}
+
+// getFloat returns the field of record in the column col
+// as a float, zero if it is not a number.
+func (t *table) getFloat(record []string, col string) float64 {
+	f, _ := strconv.ParseFloat(t.get(record, col), 64)
+	return f
+}
+
+// getDay returns the day of the date, formatted as 2006-01-02, of
+// record in the column col, zero if it is not a valid date.
+func (t *table) getDay(record []string, col string) time.Time {
+	d, err := time.Parse("2006-01-02", t.get(record, col))
+	if err != nil {
+		return time.Time{}
+	}
+	return d
+}
@@ -0,0 +1,237 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rschio/movieApp/client"
+	"github.com/rschio/movieApp/client/clienttest"
+)
+
+// zipOf returns a zip with the files, keyed by name.
+func zipOf(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func date(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParseLetterboxd(t *testing.T) {
+	data := zipOf(t, map[string]string{
+		"watched.csv": "Date,Name,Year,Letterboxd URI\n" +
+			"2024-01-02,The Godfather,1972,https://boxd.it/a\n" +
+			"2024-01-03,Fight Club,1999,https://boxd.it/b\n",
+		"diary.csv": "Date,Name,Year,Letterboxd URI,Rating,Rewatch,Tags,Watched Date\n" +
+			"2024-01-02,The Godfather,1972,https://boxd.it/c,4,,,2023-12-31\n" +
+			"2024-02-10,The Godfather,1972,https://boxd.it/d,,Yes,,2024-02-09\n",
+		"ratings.csv": "Date,Name,Year,Letterboxd URI,Rating\n" +
+			"2024-02-10,The Godfather,1972,https://boxd.it/a,4.5\n",
+		"watchlist.csv": "Date,Name,Year,Letterboxd URI\n" +
+			"2024-01-05,\"Pulp Fiction\",1994,https://boxd.it/e\n" +
+			"2024-01-05,Fight Club,1999,https://boxd.it/b\n",
+		// The lists are not imported.
+		"lists/favorites.csv": "Date,Name,Year\n2024-01-01,Forrest Gump,1994\n",
+	})
+	rows, err := Parse(Letterboxd, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Row{
+		{Title: "The Godfather", Year: 1972, MediaType: client.MediaMovie, Watched: true,
+			Watches: []time.Time{date(2023, 12, 31), date(2024, 2, 9)}, Stars: 4.5},
+		{Title: "Fight Club", Year: 1999, MediaType: client.MediaMovie, Watched: true},
+		{Title: "Pulp Fiction", Year: 1994, MediaType: client.MediaMovie},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("got rows %+v, want %+v", rows, want)
+	}
+
+	if _, err := Parse(Letterboxd, zipOf(t, map[string]string{"a.csv": "x"})); err != ErrNoLetterboxdFiles {
+		t.Errorf("got error %v, want ErrNoLetterboxdFiles", err)
+	}
+	if _, err := Parse("netflix", data); err != ErrUnknownSource {
+		t.Errorf("got error %v, want ErrUnknownSource", err)
+	}
+}
+
+func TestZipTooLarge(t *testing.T) {
+	defer func(size int64) { maxUnzippedSize = size }(maxUnzippedSize)
+	maxUnzippedSize = 100
+	watched := "Date,Name,Year,Letterboxd URI\n" + strings.Repeat("2024-01-02,The Godfather,1972,https://boxd.it/a\n", 10)
+	if _, err := Parse(Letterboxd, zipOf(t, map[string]string{"watched.csv": watched})); err != ErrZipTooLarge {
+		t.Errorf("Letterboxd got error %v, want ErrZipTooLarge", err)
+	}
+	// The limit is of all the files together.
+	files := map[string]string{"a.json": strings.Repeat(" ", 60) + "[]", "b.json": strings.Repeat(" ", 60) + "[]"}
+	if _, err := Parse(Trakt, zipOf(t, files)); err != ErrZipTooLarge {
+		t.Errorf("Trakt got error %v, want ErrZipTooLarge", err)
+	}
+}
+
+func TestParseIMDb(t *testing.T) {
+	csv := "\ufeffConst,Your Rating,Date Rated,Title,URL,Title Type,IMDb Rating,Runtime (mins),Year\n" +
+		"tt0068646,9,2024-01-02,The Godfather,https://imdb.com/title/tt0068646/,Movie,9.2,175,1972\n" +
+		"tt0903747,10,2024-01-03,Breaking Bad,https://imdb.com/title/tt0903747/,TV Series,9.5,49,2008\n" +
+		"tt0959621,8,2024-01-04,Pilot,https://imdb.com/title/tt0959621/,TV Episode,9.0,58,2008\n"
+	rows, err := Parse(IMDb, []byte(csv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Row{
+		{Title: "The Godfather", Year: 1972, IMDbID: "tt0068646", MediaType: client.MediaMovie, Watched: true, Stars: 4.5},
+		{Title: "Breaking Bad", Year: 2008, IMDbID: "tt0903747", MediaType: client.MediaTV, Watched: true, Stars: 5},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("got rows %+v, want %+v", rows, want)
+	}
+	if _, err := ParseIMDb(strings.NewReader("Name,Year\nx,1\n")); err != ErrNotIMDbRatings {
+		t.Errorf("got error %v, want ErrNotIMDbRatings", err)
+	}
+}
+
+func TestParseTrakt(t *testing.T) {
+	history := `[
+		{"id":1,"watched_at":"2024-03-01T22:10:00.000Z","action":"watch","type":"movie",
+			"movie":{"title":"The Godfather","year":1972,"ids":{"trakt":1,"imdb":"tt0068646","tmdb":238}}},
+		{"id":2,"watched_at":"2024-03-02T21:00:00.000Z","action":"watch","type":"episode",
+			"episode":{"season":1,"number":1},
+			"show":{"title":"Breaking Bad","year":2008,"ids":{"trakt":2,"imdb":"tt0903747","tmdb":1396}}}
+	]`
+	ratings := `[
+		{"rated_at":"2024-03-03T10:00:00.000Z","rating":8,"type":"movie",
+			"movie":{"title":"The Godfather","year":1972,"ids":{"imdb":"tt0068646","tmdb":238}}},
+		{"rated_at":"2024-03-03T10:00:00.000Z","rating":10,"type":"episode",
+			"show":{"title":"Breaking Bad","year":2008,"ids":{"imdb":"tt0903747","tmdb":1396}}}
+	]`
+	watchlist := `[
+		{"listed_at":"2024-03-04T10:00:00.000Z","type":"movie",
+			"movie":{"title":"Fight Club","year":1999,"ids":{"imdb":"tt0137523"}}},
+		{"listed_at":"2024-03-04T10:00:00.000Z","type":"movie",
+			"movie":{"title":"The Godfather","year":1972,"ids":{"imdb":"tt0068646","tmdb":238}}}
+	]`
+	collection := `[{"collected_at":"2024-03-05T10:00:00.000Z",
+		"movie":{"title":"Pulp Fiction","year":1994,"ids":{"imdb":"tt0110912"}}}]`
+	// The files are merged in the order of their names.
+	want := []Row{
+		{Title: "Fight Club", Year: 1999, IMDbID: "tt0137523", MediaType: client.MediaMovie},
+		{Title: "The Godfather", Year: 1972, IMDbID: "tt0068646", TMDBID: 238, MediaType: client.MediaMovie,
+			Watched: true, Watches: []time.Time{date(2024, 3, 1)}, Stars: 4},
+		{Title: "Breaking Bad", Year: 2008, IMDbID: "tt0903747", TMDBID: 1396, MediaType: client.MediaTV, Watched: true},
+	}
+	rows, err := Parse(Trakt, zipOf(t, map[string]string{
+		"user/watched-history.json":   history,
+		"user/ratings-movies.json":    ratings,
+		"user/lists-watchlist.json":   watchlist,
+		"user/collection-movies.json": collection,
+		"user/user-profile.json":      `{"username":"a"}`,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("got rows %+v, want %+v", rows, want)
+	}
+
+	// A single file.
+	rows, err = Parse(Trakt, []byte(watchlist))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[0].Watched || rows[1].Watched {
+		t.Errorf("got rows %+v, want 2 on the watchlist", rows)
+	}
+}
+
+func TestMatchAll(t *testing.T) {
+	srv := clienttest.NewServer()
+	defer srv.Close()
+	m := NewMatcher(client.New(srv.URL, clienttest.Token, srv.Client()))
+	rows := []Row{
+		{Title: "Anything", TMDBID: 680, MediaType: client.MediaMovie},
+		{Title: "Wrong title", IMDbID: "tt0068646", MediaType: client.MediaMovie},
+		// TV movies on IMDb may be movies or tv shows on TMDB.
+		{Title: "Breaking Bad", IMDbID: "tt0903747", MediaType: client.MediaMovie},
+		// The year selects the movie among the results.
+		{Title: "The Godfather Part II", Year: 1974, MediaType: client.MediaMovie},
+		{Title: "the  godfather", Year: 1972, MediaType: client.MediaMovie},
+		// The release year differs by one.
+		{Title: "Fight Club", Year: 2000, MediaType: client.MediaMovie},
+		{Title: "Stranger Things", MediaType: client.MediaTV},
+		// Only the same title matches, a result of the same
+		// year with another title is not the movie.
+		{Title: "Godfather", MediaType: client.MediaMovie},
+		{Title: "Godfather", Year: 1972, MediaType: client.MediaMovie},
+		{Title: "Fight Club", Year: 2010, MediaType: client.MediaMovie},
+		{Title: "Unknown", IMDbID: "tt0000000", MediaType: client.MediaMovie},
+	}
+	matched, unmatched := m.MatchAll(rows)
+	var keys []string
+	for _, m := range matched {
+		keys = append(keys, m.Item.Key())
+	}
+	want := []string{"movie/680", "movie/238", "tv/1396", "movie/240", "movie/238", "movie/550", "tv/66732"}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("matched %v, want %v", keys, want)
+	}
+	if len(unmatched) != 4 || unmatched[0].Title != "Godfather" || unmatched[1].Year != 1972 || unmatched[3].Title != "Unknown" {
+		t.Errorf("got unmatched %+v", unmatched)
+	}
+}
+
+func TestMatchAllErrors(t *testing.T) {
+	srv := clienttest.NewServer()
+	defer srv.Close()
+	// The fake rejects the requests without token.
+	m := NewMatcher(client.New(srv.URL, "", srv.Client()))
+	rows := []Row{
+		{Title: "The Godfather", Year: 1972, MediaType: client.MediaMovie},
+		{Title: "Pulp Fiction", TMDBID: 680, MediaType: client.MediaMovie},
+		{Title: "Fight Club", IMDbID: "tt0137523", MediaType: client.MediaMovie},
+	}
+	matched, unmatched := m.MatchAll(rows)
+	// The rows that need no request are still matched.
+	if len(matched) != 1 || matched[0].Item != client.MovieItem(680) {
+		t.Errorf("got matched %+v, want only Pulp Fiction", matched)
+	}
+	if len(unmatched) != 2 || unmatched[0].Title != "The Godfather" || unmatched[1].Title != "Fight Club" {
+		t.Errorf("got unmatched %+v, want the rows whose requests failed", unmatched)
+	}
+}
+
+func TestSimilarTitle(t *testing.T) {
+	results := []client.Result{
+		{ID: 1, Title: "The Godfather Part II"},
+		{ID: 2, Title: "Amélie", OriginalTitle: "Le Fabuleux Destin d'Amélie Poulain"},
+	}
+	tests := []struct {
+		title string
+		id    int
+	}{
+		{"The Godfather: Part II", 1},
+		{"le fabuleux destin d’amélie poulain", 2},
+		{"The Godfather", 0},
+	}
+	for _, tt := range tests {
+		r, ok := similarTitle(results, tt.title)
+		if ok != (tt.id != 0) || r.ID != tt.id {
+			t.Errorf("similarTitle(%q) = %d, %v, want %d", tt.title, r.ID, ok, tt.id)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"path"
+	"strings"
+
+	"github.com/rschio/movieApp/client"
+)
+
+// letterboxdFiles are the files of the Letterboxd export that are
+// imported, in the order they are merged, so the ratings of
+// ratings.csv, the current ones, override the ratings of diary.csv.
+var letterboxdFiles = []string{"watched.csv", "diary.csv", "ratings.csv", "watchlist.csv"}
+
+// ErrNoLetterboxdFiles is returned when a zip
+// has none of the Letterboxd export files.
+var ErrNoLetterboxdFiles = errors.New("importer: no Letterboxd files in the zip")
+
+// ParseLetterboxd parses the zip exported by Letterboxd. The films of
+// watched.csv, diary.csv and ratings.csv are watched, with the days
+// of the diary entries, and the films of watchlist.csv are on the
+// watchlist. The lists, likes and deleted films are not imported.
+func ParseLetterboxd(data []byte) ([]Row, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	// The files may be in a folder, the ones closer
+	// to the root are the export ones.
+	files := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		name := path.Base(f.Name)
+		if old, ok := files[name]; ok && depth(old.Name) <= depth(f.Name) {
+			continue
+		}
+		files[name] = f
+	}
+	set := newRowSet()
+	u := newUnzipper()
+	found := false
+	for _, name := range letterboxdFiles {
+		f, ok := files[name]
+		if !ok {
+			continue
+		}
+		found = true
+		b, err := u.read(f)
+		if err != nil {
+			return nil, err
+		}
+		if err := parseLetterboxdFile(set, name, b); err != nil {
+			return nil, err
+		}
+	}
+	if !found {
+		return nil, ErrNoLetterboxdFiles
+	}
+	return set.list(), nil
+}
+
+func depth(name string) int {
+	return strings.Count(name, "/")
+}
+
+// parseLetterboxdFile adds the films of the file with name
+// name, one of letterboxdFiles, and content data to set.
+func parseLetterboxdFile(set *rowSet, name string, data []byte) error {
+	t, err := readTable(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	if !t.has("Name", "Year") {
+		return errors.New("importer: " + name + " is not a Letterboxd export")
+	}
+	for _, rec := range t.records {
+		r := Row{
+			Title:     t.get(rec, "Name"),
+			Year:      t.getInt(rec, "Year"),
+			MediaType: client.MediaMovie,
+			Watched:   name != "watchlist.csv",
+		}
+		switch name {
+		case "diary.csv":
+			d := t.getDay(rec, "Watched Date")
+			if d.IsZero() {
+				d = t.getDay(rec, "Date")
+			}
+			if !d.IsZero() {
+				r.Watches = append(r.Watches, d)
+			}
+			r.Stars = t.getFloat(rec, "Rating")
+		case "ratings.csv":
+			r.Stars = t.getFloat(rec, "Rating")
+		}
+		set.add(r)
+	}
+	return nil
+}
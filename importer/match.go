@@ -0,0 +1,196 @@
+package importer
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/rschio/movieApp/client"
+)
+
+// maxMatchRequests is the number of rows matched concurrently.
+const maxMatchRequests = 8
+
+// Match is a row matched to a TMDB movie or tv show.
+type Match struct {
+	Row
+	Item client.Item
+}
+
+// Matcher matches rows to TMDB movies and tv shows.
+type Matcher struct {
+	c *client.Client
+}
+
+// NewMatcher returns a Matcher that requests TMDB with c.
+func NewMatcher(c *client.Client) *Matcher {
+	return &Matcher{c: c}
+}
+
+// MatchAll matches rows, concurrently, and returns the rows matched
+// and the ones that match no title, both in the order of rows. The
+// rows whose requests failed are logged and returned as unmatched,
+// so they can be imported again or resolved by hand.
+func (m *Matcher) MatchAll(rows []Row) ([]Match, []Row) {
+	var (
+		items = make([]client.Item, len(rows))
+		found = make([]bool, len(rows))
+		wg    sync.WaitGroup
+		sem   = make(chan struct{}, maxMatchRequests)
+	)
+	for i := range rows {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			item, ok, err := m.Match(&rows[i])
+			<-sem
+			if err != nil {
+				log.Printf("failed to match %q (%d): %v", rows[i].Title, rows[i].Year, err)
+				return
+			}
+			items[i], found[i] = item, ok
+		}(i)
+	}
+	wg.Wait()
+	var (
+		matched   []Match
+		unmatched []Row
+	)
+	for i, r := range rows {
+		if found[i] {
+			matched = append(matched, Match{Row: r, Item: items[i]})
+		} else {
+			unmatched = append(unmatched, r)
+		}
+	}
+	return matched, unmatched
+}
+
+// Match returns the movie or tv show of row, ok is false if row
+// matches no title. The row is matched by its TMDB ID, by its IMDb
+// ID or by searching its title and year. A title searched only
+// matches results with the same title, ignoring the punctuation if
+// the year is the same, a wrong title is left to be resolved by hand.
+func (m *Matcher) Match(row *Row) (item client.Item, ok bool, err error) {
+	if row.TMDBID > 0 {
+		return client.Item{MediaType: row.MediaType, MediaID: row.TMDBID}, true, nil
+	}
+	if row.IMDbID != "" {
+		res, err := m.c.Find(row.IMDbID)
+		if err != nil {
+			return item, false, err
+		}
+		// Prefer the type of the row, but the types of the
+		// sources differ, e.g. a tv movie on IMDb is a movie
+		// on TMDB.
+		first, second := res.MovieResults, res.TVResults
+		if row.MediaType == client.MediaTV {
+			first, second = second, first
+		}
+		if len(first) > 0 {
+			return first[0].Item(), true, nil
+		}
+		if len(second) > 0 {
+			return second[0].Item(), true, nil
+		}
+	}
+	if row.Title == "" {
+		return item, false, nil
+	}
+	results, err := m.search(row.MediaType, row.Title, row.Year)
+	if err != nil {
+		return item, false, err
+	}
+	if r, ok := sameTitle(results, row.Title, 0); ok {
+		return r.Item(), true, nil
+	}
+	if row.Year == 0 {
+		return item, false, nil
+	}
+	// The search matched the year, but not the title, the sources
+	// may write it with other punctuation, e.g. "Se7en" or "Se7en.".
+	if r, ok := similarTitle(results, row.Title); ok {
+		return r.Item(), true, nil
+	}
+	// The sources may have a different release year,
+	// e.g. the year of the festival premiere.
+	results, err = m.search(row.MediaType, row.Title, 0)
+	if err != nil {
+		return item, false, err
+	}
+	for _, year := range []int{row.Year - 1, row.Year + 1} {
+		if r, ok := sameTitle(results, row.Title, year); ok {
+			return r.Item(), true, nil
+		}
+	}
+	return item, false, nil
+}
+
+// search returns the first page of the movies or tv shows, as
+// mediaType, matching title, released in year if it is not zero.
+func (m *Matcher) search(mediaType, title string, year int) ([]client.Result, error) {
+	query := strings.Join(strings.Fields(title), " ")
+	opts := &client.SearchOptions{Query: query, Year: year}
+	var (
+		resp *client.SearchMovieResp
+		err  error
+	)
+	if mediaType == client.MediaTV {
+		resp, err = m.c.SearchTV(opts)
+	} else {
+		resp, err = m.c.SearchMovies(opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resp.Results, nil
+}
+
+// sameTitle returns the first of results with title as its title or
+// original title, released in year if it is not zero.
+func sameTitle(results []client.Result, title string, year int) (client.Result, bool) {
+	title = normalize(title)
+	for _, r := range results {
+		if year > 0 && !hasYear(r.Date(), year) {
+			continue
+		}
+		for _, t := range []string{r.Title, r.OriginalTitle, r.Name, r.OriginalName} {
+			if t != "" && normalize(t) == title {
+				return r, true
+			}
+		}
+	}
+	return client.Result{}, false
+}
+
+// similarTitle returns the first of results with the same title or
+// original title as title, ignoring case, spaces and punctuation.
+func similarTitle(results []client.Result, title string) (client.Result, bool) {
+	title = alphanumeric(title)
+	for _, r := range results {
+		for _, t := range []string{r.Title, r.OriginalTitle, r.Name, r.OriginalName} {
+			if t != "" && alphanumeric(t) == title {
+				return r, true
+			}
+		}
+	}
+	return client.Result{}, false
+}
+
+// alphanumeric returns the letters and digits of s in lower case.
+func alphanumeric(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, s)
+}
+
+// hasYear reports if date, formatted as 2006-01-02, is in year.
+func hasYear(date string, year int) bool {
+	return len(date) >= 4 && date[:4] == strconv.Itoa(year)
+}
@@ -0,0 +1,129 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/rschio/movieApp/client"
+)
+
+// ErrNoTraktFiles is returned when a zip
+// has none of the Trakt export files.
+var ErrNoTraktFiles = errors.New("importer: no Trakt files in the zip")
+
+type traktIDs struct {
+	IMDb string `json:"imdb"`
+	TMDB int    `json:"tmdb"`
+}
+
+type traktMedia struct {
+	Title string   `json:"title"`
+	Year  int      `json:"year"`
+	IDs   traktIDs `json:"ids"`
+}
+
+// traktItem is an item of the Trakt export files, the history has
+// watched_at, watched has last_watched_at, ratings has rating and
+// the watchlist has listed_at. The collection, with collected_at,
+// is not imported, a title collected may not be watched.
+type traktItem struct {
+	// Type is "movie", "show", "season" or "episode",
+	// empty in the watched files.
+	Type          string      `json:"type"`
+	WatchedAt     string      `json:"watched_at"`
+	LastWatchedAt string      `json:"last_watched_at"`
+	ListedAt      string      `json:"listed_at"`
+	CollectedAt   string      `json:"collected_at"`
+	Rating        int         `json:"rating"`
+	Movie         *traktMedia `json:"movie"`
+	Show          *traktMedia `json:"show"`
+}
+
+// ParseTrakt parses a JSON file of the Trakt export, or a zip of the
+// export files. The titles of the history, watched and ratings files
+// are watched and the ones of the watchlist are on the watchlist.
+// The episodes and seasons count as their shows, the days watched
+// and the ratings, from 1 to 10 halved into stars, are only kept for
+// the movies and shows themselves.
+func ParseTrakt(data []byte) ([]Row, error) {
+	set := newRowSet()
+	if !bytes.HasPrefix(data, []byte("PK")) {
+		var items []traktItem
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, err
+		}
+		addTraktItems(set, items)
+		return set.list(), nil
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	files := make([]*zip.File, 0, len(zr.File))
+	for _, f := range zr.File {
+		if path.Ext(f.Name) == ".json" {
+			files = append(files, f)
+		}
+	}
+	// Merge the files in a stable order, the
+	// rating of the last file rated is kept.
+	sort.SliceStable(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+	u := newUnzipper()
+	found := false
+	for _, f := range files {
+		b, err := u.read(f)
+		if err != nil {
+			return nil, err
+		}
+		var items []traktItem
+		if err := json.Unmarshal(b, &items); err != nil {
+			// Not a list of items, e.g. the user profile.
+			continue
+		}
+		found = true
+		addTraktItems(set, items)
+	}
+	if !found {
+		return nil, ErrNoTraktFiles
+	}
+	return set.list(), nil
+}
+
+func addTraktItems(set *rowSet, items []traktItem) {
+	for _, it := range items {
+		if it.CollectedAt != "" {
+			continue
+		}
+		media, mediaType := it.Movie, client.MediaMovie
+		if media == nil {
+			media, mediaType = it.Show, client.MediaTV
+		}
+		if media == nil {
+			continue
+		}
+		r := Row{
+			Title:     media.Title,
+			Year:      media.Year,
+			IMDbID:    media.IDs.IMDb,
+			TMDBID:    media.IDs.TMDB,
+			MediaType: mediaType,
+			Watched:   it.ListedAt == "",
+		}
+		// Only the items of the title itself, not
+		// of its seasons or episodes.
+		if it.Type == "" || it.Type == "movie" || it.Type == "show" {
+			r.Stars = float64(it.Rating) / 2
+			for _, at := range []string{it.WatchedAt, it.LastWatchedAt} {
+				if t, err := time.Parse(time.RFC3339, at); err == nil {
+					r.Watches = append(r.Watches, day(t))
+				}
+			}
+		}
+		set.add(r)
+	}
+}
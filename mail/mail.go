@@ -2,7 +2,9 @@ package mail
 
 import (
 	"strconv"
+	"strings"
 
+	"github.com/rschio/movieApp/i18n"
 	"github.com/sendgrid/sendgrid-go"
 	"github.com/sendgrid/sendgrid-go/helpers/mail"
 )
@@ -12,20 +14,72 @@ type Mailer struct {
 	from   *mail.Email
 }
 
-// SendVerificationLink send a link to user email to verify account.
-func (m *Mailer) SendVerificationLink(toUser, toAddr, link string) error {
-	subject := "App list of movies verification."
-	text := "Click here to verify your email: " + link
+// SendVerificationLink send a link to user email to verify account,
+// the email is written in lang, see i18n.
+func (m *Mailer) SendVerificationLink(lang, toUser, toAddr, link string) error {
+	subject := i18n.T(lang, "App list of movies verification.")
+	text := i18n.T(lang, "Click here to verify your email: %s", link)
 	return m.send(toUser, toAddr, subject, text)
 }
 
-// SendScheduledMovie send a email to user to remeber of a scheduled movie.
-func (m *Mailer) SendScheduledMovie(toUser, toAddr string, movieID int) error {
-	subject := "Watch your movie."
-	text := "It's time, watch movie with ID: " + strconv.Itoa(movieID)
+// SendScheduledMovie send a email to user to remeber of a scheduled movie
+// or tv show, mediaType is "movie" or "tv". The email is written in lang.
+// If trailerURL is not empty a link to the trailer is sent too.
+func (m *Mailer) SendScheduledMovie(lang, toUser, toAddr, mediaType string, id int, trailerURL string) error {
+	subject := i18n.T(lang, "Watch your movie.")
+	text := i18n.T(lang, "It's time, watch movie with ID: %d", id)
+	if mediaType == "tv" {
+		subject = i18n.T(lang, "Watch your tv show.")
+		text = i18n.T(lang, "It's time, watch tv show with ID: %d", id)
+	}
+	if trailerURL != "" {
+		text += "\n" + i18n.T(lang, "Watch the trailer: %s", trailerURL)
+	}
 	return m.send(toUser, toAddr, subject, text)
 }
 
+// YearInReview is the review of the movies a user watched in a year.
+type YearInReview struct {
+	Year    int
+	Movies  int
+	Watches int
+	Hours   float64
+	// TopRated and TopGenre are empty if unknown.
+	TopRated string
+	TopGenre string
+	// Longest, with its runtime in minutes, First
+	// and Last are the titles of the movies.
+	Longest        string
+	LongestRuntime int
+	First          string
+	Last           string
+}
+
+// SendYearInReview send a email to user with rev, the review of the
+// movies the user watched in a year. The email is written in lang.
+// If link is not empty a link to the shareable page is sent too.
+func (m *Mailer) SendYearInReview(lang, toUser, toAddr string, rev *YearInReview, link string) error {
+	subject := i18n.T(lang, "Your %d in movies", rev.Year)
+	lines := []string{
+		i18n.T(lang, "You watched %d movies, %d times, in %s hours.", rev.Movies, rev.Watches, strconv.FormatFloat(rev.Hours, 'f', 1, 64)),
+	}
+	if rev.TopRated != "" {
+		lines = append(lines, i18n.T(lang, "Top rated: %s", rev.TopRated))
+	}
+	if rev.TopGenre != "" {
+		lines = append(lines, i18n.T(lang, "Most watched genre: %s", rev.TopGenre))
+	}
+	lines = append(lines,
+		i18n.T(lang, "Longest movie: %s (%d min)", rev.Longest, rev.LongestRuntime),
+		i18n.T(lang, "First of the year: %s", rev.First),
+		i18n.T(lang, "Last of the year: %s", rev.Last),
+	)
+	if link != "" {
+		lines = append(lines, i18n.T(lang, "See and share it: %s", link))
+	}
+	return m.send(toUser, toAddr, subject, strings.Join(lines, "\n"))
+}
+
 func (m *Mailer) send(toUser, toAddr, subject, text string) error {
 	to := mail.NewEmail(toUser, toAddr)
 	message := mail.NewSingleEmail(m.from, subject, to, text, text)
@@ -2,11 +2,17 @@ package main
 
 import (
 	"context"
+	"flag"
+	"log"
 	"net/http"
 	"os"
+
+	"github.com/rschio/movieApp/client/clienttest"
 )
 
 func main() {
+	fakeTMDB := flag.Bool("fake-tmdb", false, "use an in-process fake TMDB API instead of the real one")
+	flag.Parse()
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
@@ -18,11 +24,22 @@ func main() {
 		mailerAPIKey:    os.Getenv("SENDGRID_API_KEY"),
 		mailerName:      "no-reply",
 		mailerAddr:      os.Getenv("MAILERADDR"),
+		storePath:       os.Getenv("STOREPATH"),
+		baseURL:         os.Getenv("BASEURL"),
+	}
+	if *fakeTMDB {
+		fake := clienttest.NewServer()
+		defer fake.Close()
+		log.Printf("using fake TMDB API at %s", fake.URL)
+		srvCfg.clientBaseURL = fake.URL
+		srvCfg.clientAPIToken = clienttest.Token
 	}
 	s := NewServer(srvCfg)
 
 	ctx := context.Background()
 	go s.schedule(ctx)
+	go s.syncPeriodically(ctx, syncInterval)
+	go s.suggestWorker(ctx, suggestInterval)
 
 	static := http.FileServer(http.Dir("static"))
 	http.Handle("/scripts/", static)
@@ -32,10 +49,34 @@ func main() {
 	http.HandleFunc("/addprofile", s.Authorize(s.addProfile))
 	http.HandleFunc("/browse", s.Authorize(s.browse))
 	http.HandleFunc("/searchmovie", s.Authorize(s.searchMovie))
-	http.HandleFunc("/addmovie/", s.Authorize(s.addMovie))
-	http.HandleFunc("/watchmovie/", s.Authorize(s.watchMovie))
+	http.HandleFunc("/discover", s.Authorize(s.discover))
+	http.HandleFunc("/movie/", s.Authorize(s.movie))
+	http.HandleFunc("/tv/", s.Authorize(s.tv))
+	http.HandleFunc("/season/", s.Authorize(s.season))
+	http.HandleFunc("/progress/episode", s.Authorize(s.markEpisode))
+	http.HandleFunc("/progress/season", s.Authorize(s.markSeason))
+	http.HandleFunc("/person/", s.Authorize(s.person))
+	http.HandleFunc("/searchperson", s.Authorize(s.searchPerson))
+	http.HandleFunc("/add/", s.Authorize(s.addItem))
+	http.HandleFunc("/watch/", s.Authorize(s.watchItem))
+	http.HandleFunc("/comment/", s.Authorize(s.commentItem))
+	http.HandleFunc("/suggestion/", s.Authorize(s.suggestionFeedback))
+	http.HandleFunc("/review/", s.Authorize(s.reviewItem))
+	http.HandleFunc("/diary", s.Authorize(s.showDiary))
+	http.HandleFunc("/diary/delete", s.Authorize(s.deleteDiaryEntry))
+	http.HandleFunc("/stats", s.Authorize(s.showStats))
+	http.HandleFunc("/yearinreview", s.Authorize(s.yearInReview))
+	http.HandleFunc("/year/", s.sharedYearReview)
 	http.HandleFunc("/showscheduler/", s.Authorize(s.showScheduler))
 	http.HandleFunc("/schedulemovie", s.Authorize(s.scheduleMovie))
+	http.HandleFunc("/settings", s.Authorize(s.settings))
+	http.HandleFunc("/export", s.Authorize(s.export))
+	http.HandleFunc("/import", s.Authorize(s.importHistory))
+	http.HandleFunc("/import/resolve", s.Authorize(s.resolveImport))
+	http.HandleFunc("/tmdb/link", s.Authorize(s.linkTMDB))
+	http.HandleFunc("/tmdb/callback", s.Authorize(s.tmdbCallback))
+	http.HandleFunc("/tmdb/unlink", s.Authorize(s.unlinkTMDB))
+	http.HandleFunc("/tmdb/sync", s.Authorize(s.tmdbSync))
 	http.HandleFunc("/login", s.login)
 	http.HandleFunc("/logout", s.logout)
 	http.HandleFunc("/signup", s.signup)
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"sort"
+
+	"github.com/rschio/movieApp/account"
+	"github.com/rschio/movieApp/client"
+)
+
+// filmographyItem is a movie of a person filmography.
+type filmographyItem struct {
+	Movie client.Result
+	// Roles are the characters and jobs of
+	// the person in the movie.
+	Roles []string
+	// InWatchList and Watched mark if the movie
+	// is already on the profile's lists.
+	InWatchList bool
+	Watched     bool
+}
+
+// personPage is the data used to render person.html.
+type personPage struct {
+	Person      *client.Person
+	Filmography []*filmographyItem
+}
+
+// person displays the details of a person and the person's
+// filmography marking the movies already on profile's lists.
+func (s *server) person(w http.ResponseWriter, r *http.Request, acc *account.Account) {
+	const path = "/person/"
+	personID, err := idFromPath(path, r)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	id, err := account.ProfileFromRequest(r, acc)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	profile := acc.Profiles[id]
+	c := s.clientFor(acc)
+	// Request the person, the credits and the profile's
+	// lists concurrently.
+	var (
+		credits        *client.PersonMovieCredits
+		watch, watched map[string]bool
+		errs           = make(chan error, 1)
+	)
+	go func() {
+		var err error
+		credits, err = c.GetPersonMovieCredits(personID)
+		errs <- err
+	}()
+	go func() {
+		var err error
+		watch, err = listItemKeys(c, profile.WatchListID)
+		errs <- err
+	}()
+	go func() {
+		var err error
+		watched, err = listItemKeys(c, profile.WatchedListID)
+		errs <- err
+	}()
+	person, err := c.GetPerson(personID)
+	for i := 0; i < 3; i++ {
+		if e := <-errs; e != nil {
+			err = e
+		}
+	}
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	films := filmography(credits)
+	for _, f := range films {
+		f.InWatchList = watch[f.Movie.Key()]
+		f.Watched = watched[f.Movie.Key()]
+	}
+	page := &personPage{
+		Person:      person,
+		Filmography: films,
+	}
+	s.tmpl(r, acc).ExecuteTemplate(w, "person.html", page)
+}
+
+// filmography merges the cast and crew credits of a person by movie,
+// the result is sorted by release date, newest first.
+func filmography(credits *client.PersonMovieCredits) []*filmographyItem {
+	byID := make(map[int]*filmographyItem)
+	films := make([]*filmographyItem, 0, len(credits.Cast)+len(credits.Crew))
+	add := func(c client.PersonCredit, role string) {
+		f, ok := byID[c.ID]
+		if !ok {
+			f = &filmographyItem{Movie: c.Result}
+			byID[c.ID] = f
+			films = append(films, f)
+		}
+		if role != "" {
+			f.Roles = append(f.Roles, role)
+		}
+	}
+	for _, c := range credits.Cast {
+		add(c, c.Character)
+	}
+	for _, c := range credits.Crew {
+		add(c, c.Job)
+	}
+	sort.SliceStable(films, func(i, j int) bool {
+		return films[i].Movie.ReleaseDate > films[j].Movie.ReleaseDate
+	})
+	return films
+}
+
+// searchPerson search a person with specified query and display the results,
+// the query must be sent as a POST request.
+func (s *server) searchPerson(w http.ResponseWriter, r *http.Request, acc *account.Account) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	query := r.FormValue("query")
+	if query == "" {
+		http.Error(w, "Invalid query", http.StatusBadRequest)
+		return
+	}
+	people, err := s.clientFor(acc).SearchPerson(query)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	// Display the found people.
+	s.tmpl(r, acc).ExecuteTemplate(w, "searchperson.html", people)
+}
@@ -0,0 +1,190 @@
+// Package progress tracks the watched episodes of
+// tv shows of each profile.
+package progress
+
+import (
+	"sort"
+	"time"
+
+	"github.com/rschio/movieApp/client"
+	"github.com/rschio/movieApp/store"
+)
+
+// bucket is the store bucket of progress, the keys
+// are profile keys and the values are profileShows.
+const bucket = "progress"
+
+// Show is the progress of a profile in a tv show.
+type Show struct {
+	TVID int
+	// Watched maps a season number to the
+	// watched episode numbers of the season.
+	Watched   map[int][]int
+	UpdatedAt time.Time
+}
+
+// IsWatched reports if the episode of season was watched.
+func (sh *Show) IsWatched(season, episode int) bool {
+	for _, e := range sh.Watched[season] {
+		if e == episode {
+			return true
+		}
+	}
+	return false
+}
+
+// WatchedCount returns the number of watched episodes.
+func (sh *Show) WatchedCount() int {
+	n := 0
+	for _, eps := range sh.Watched {
+		n += len(eps)
+	}
+	return n
+}
+
+// set marks or unmarks episodes of season as watched.
+func (sh *Show) set(season int, episodes []int, watched bool) {
+	if sh.Watched == nil {
+		sh.Watched = make(map[int][]int)
+	}
+	eps := make(map[int]bool)
+	for _, e := range sh.Watched[season] {
+		eps[e] = true
+	}
+	for _, e := range episodes {
+		eps[e] = watched
+	}
+	out := make([]int, 0, len(eps))
+	for e, ok := range eps {
+		if ok {
+			out = append(out, e)
+		}
+	}
+	sort.Ints(out)
+	if len(out) == 0 {
+		delete(sh.Watched, season)
+		return
+	}
+	sh.Watched[season] = out
+}
+
+// profileShows maps tv show IDs to the progress in the show.
+type profileShows map[int]*Show
+
+// Tracker stores the progress of profiles in tv shows.
+type Tracker struct {
+	db *store.Store
+}
+
+// NewTracker creates a tracker that persists progress in db.
+func NewTracker(db *store.Store) *Tracker {
+	return &Tracker{db: db}
+}
+
+// MarkEpisode marks, or unmarks if watched is false, the episode of
+// season of the tv show tvID as watched by profile.
+func (t *Tracker) MarkEpisode(profile string, tvID, season, episode int, watched bool) error {
+	return t.MarkEpisodes(profile, tvID, season, []int{episode}, watched)
+}
+
+// MarkEpisodes marks, or unmarks if watched is false, the episodes of
+// season of the tv show tvID as watched by profile. It is used to mark
+// a whole season.
+func (t *Tracker) MarkEpisodes(profile string, tvID, season int, episodes []int, watched bool) error {
+	shows := make(profileShows)
+	return t.db.Update(bucket, profile, &shows, func() error {
+		sh, ok := shows[tvID]
+		if !ok {
+			sh = &Show{TVID: tvID}
+			shows[tvID] = sh
+		}
+		sh.set(season, episodes, watched)
+		sh.UpdatedAt = time.Now()
+		// Stop tracking shows without watched episodes.
+		if len(sh.Watched) == 0 {
+			delete(shows, tvID)
+		}
+		return nil
+	})
+}
+
+// Show returns the progress of profile in the tv show tvID,
+// a show never watched has no watched episodes.
+func (t *Tracker) Show(profile string, tvID int) (*Show, error) {
+	shows := make(profileShows)
+	if _, err := t.db.Get(bucket, profile, &shows); err != nil {
+		return nil, err
+	}
+	if sh, ok := shows[tvID]; ok {
+		return sh, nil
+	}
+	return &Show{TVID: tvID}, nil
+}
+
+// Shows returns the progress of profile in all the tv shows
+// with at least one watched episode, the most recently
+// updated first.
+func (t *Tracker) Shows(profile string) ([]*Show, error) {
+	shows := make(profileShows)
+	if _, err := t.db.Get(bucket, profile, &shows); err != nil {
+		return nil, err
+	}
+	out := make([]*Show, 0, len(shows))
+	for _, sh := range shows {
+		out = append(out, sh)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].UpdatedAt.After(out[j].UpdatedAt)
+	})
+	return out, nil
+}
+
+// NextEpisode returns the season and episode numbers of the next
+// episode to watch of tv, the episode after the furthest watched
+// one. Specials (season 0) are ignored. ok is false if all the
+// aired episodes were watched.
+func NextEpisode(sh *Show, tv *client.TVShow) (season, episode int, ok bool) {
+	seasons := make([]client.SeasonSummary, 0, len(tv.Seasons))
+	for _, s := range tv.Seasons {
+		if s.SeasonNumber > 0 && s.EpisodeCount > 0 {
+			seasons = append(seasons, s)
+		}
+	}
+	sort.Slice(seasons, func(i, j int) bool {
+		return seasons[i].SeasonNumber < seasons[j].SeasonNumber
+	})
+	if len(seasons) == 0 || tv.LastEpisodeToAir == nil {
+		return 0, 0, false
+	}
+	// Find the furthest watched episode.
+	lastSeason, lastEpisode := 0, 0
+	for s, eps := range sh.Watched {
+		if s == 0 || len(eps) == 0 {
+			continue
+		}
+		e := eps[len(eps)-1]
+		if s > lastSeason || (s == lastSeason && e > lastEpisode) {
+			lastSeason, lastEpisode = s, e
+		}
+	}
+	for _, s := range seasons {
+		switch {
+		case s.SeasonNumber == lastSeason && lastEpisode < s.EpisodeCount:
+			return aired(tv, s.SeasonNumber, lastEpisode+1)
+		case s.SeasonNumber > lastSeason:
+			return aired(tv, s.SeasonNumber, 1)
+		}
+	}
+	return 0, 0, false
+}
+
+// aired returns season and episode and reports if the
+// episode of season of tv was already aired.
+func aired(tv *client.TVShow, season, episode int) (int, int, bool) {
+	last := tv.LastEpisodeToAir
+	if season > last.SeasonNumber ||
+		(season == last.SeasonNumber && episode > last.EpisodeNumber) {
+		return 0, 0, false
+	}
+	return season, episode, true
+}
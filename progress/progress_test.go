@@ -0,0 +1,76 @@
+package progress
+
+import (
+	"testing"
+
+	"github.com/rschio/movieApp/client"
+	"github.com/rschio/movieApp/store"
+)
+
+func newTracker(t *testing.T) *Tracker {
+	db, err := store.Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewTracker(db)
+}
+
+func TestMarkEpisodes(t *testing.T) {
+	tr := newTracker(t)
+	const profile, tvID = "a@b.com/0", 1396
+	if err := tr.MarkEpisodes(profile, tvID, 1, []int{1, 2, 3}, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := tr.MarkEpisode(profile, tvID, 1, 2, false); err != nil {
+		t.Fatal(err)
+	}
+	sh, err := tr.Show(profile, tvID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sh.IsWatched(1, 1) || sh.IsWatched(1, 2) || !sh.IsWatched(1, 3) || sh.WatchedCount() != 2 {
+		t.Errorf("unexpected progress: %+v", sh.Watched)
+	}
+	// Unmarking all episodes stops tracking the show.
+	if err := tr.MarkEpisodes(profile, tvID, 1, []int{1, 3}, false); err != nil {
+		t.Fatal(err)
+	}
+	shows, err := tr.Shows(profile)
+	if err != nil || len(shows) != 0 {
+		t.Errorf("Shows = %v, %v, want no shows", shows, err)
+	}
+}
+
+func TestNextEpisode(t *testing.T) {
+	tv := &client.TVShow{
+		Seasons: []client.SeasonSummary{
+			{SeasonNumber: 0, EpisodeCount: 3},
+			{SeasonNumber: 1, EpisodeCount: 2},
+			{SeasonNumber: 2, EpisodeCount: 3},
+		},
+		LastEpisodeToAir: &client.Episode{SeasonNumber: 2, EpisodeNumber: 2},
+	}
+	tests := []struct {
+		name    string
+		watched map[int][]int
+		season  int
+		episode int
+		ok      bool
+	}{
+		{"nothing watched", nil, 1, 1, true},
+		{"specials only", map[int][]int{0: {1, 2}}, 1, 1, true},
+		{"middle of season", map[int][]int{1: {1}}, 1, 2, true},
+		{"end of season", map[int][]int{1: {1, 2}}, 2, 1, true},
+		{"skipped episodes", map[int][]int{1: {2}, 2: {1}}, 2, 2, true},
+		{"not aired", map[int][]int{2: {1, 2}}, 0, 0, false},
+		{"all watched", map[int][]int{2: {1, 2, 3}}, 0, 0, false},
+	}
+	for _, tt := range tests {
+		sh := &Show{Watched: tt.watched}
+		season, episode, ok := NextEpisode(sh, tv)
+		if season != tt.season || episode != tt.episode || ok != tt.ok {
+			t.Errorf("%s: NextEpisode = %d, %d, %v, want %d, %d, %v",
+				tt.name, season, episode, ok, tt.season, tt.episode, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/rschio/movieApp/account"
+	"github.com/rschio/movieApp/client"
+)
+
+const (
+	// providersTTL is how long the watch providers
+	// of a movie or tv show are cached.
+	providersTTL = 12 * time.Hour
+	// maxProvidersRequests is the maximum number of
+	// concurrent requests of watch providers.
+	maxProvidersRequests = 8
+)
+
+type providersEntry struct {
+	providers map[string]client.CountryProviders
+	fetched   time.Time
+}
+
+// providersCache caches the watch providers of movies and
+// tv shows by item key, they change rarely and browse would
+// request them for every item of every list.
+type providersCache struct {
+	mu      sync.Mutex
+	entries map[string]*providersEntry
+}
+
+func newProvidersCache() *providersCache {
+	return &providersCache{entries: make(map[string]*providersEntry)}
+}
+
+func (pc *providersCache) get(key string) (map[string]client.CountryProviders, bool) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	e, ok := pc.entries[key]
+	if !ok || time.Since(e.fetched) > providersTTL {
+		return nil, false
+	}
+	return e.providers, true
+}
+
+func (pc *providersCache) set(key string, providers map[string]client.CountryProviders) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	// Drop the expired entries, so the cache does not grow forever.
+	for k, e := range pc.entries {
+		if time.Since(e.fetched) > providersTTL {
+			delete(pc.entries, k)
+		}
+	}
+	pc.entries[key] = &providersEntry{providers: providers, fetched: time.Now()}
+}
+
+// watchProviders returns the watch providers of item by country,
+// from cache if possible.
+func (s *server) watchProviders(item client.Item) (map[string]client.CountryProviders, error) {
+	key := item.Key()
+	if providers, ok := s.providers.get(key); ok {
+		return providers, nil
+	}
+	providers, err := s.client.GetWatchProviders(item.MediaType, item.MediaID)
+	if err != nil {
+		return nil, err
+	}
+	s.providers.set(key, providers)
+	return providers, nil
+}
+
+// availability returns the providers subscribed in settings that
+// stream each item in the settings country, the map key is the
+// item key and items not available are not in the map.
+// It is best effort, items with errors are not available.
+func (s *server) availability(items []client.Item, settings *account.Settings) map[string][]client.Provider {
+	out := make(map[string][]client.Provider)
+	if settings.Country == "" || len(settings.Providers) == 0 {
+		return out
+	}
+	// maxProvidersRequests workers request the items.
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		pending = make(chan client.Item)
+	)
+	workers := maxProvidersRequests
+	if len(items) < workers {
+		workers = len(items)
+	}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range pending {
+				subscribed := s.subscribedProviders(item, settings)
+				if len(subscribed) == 0 {
+					continue
+				}
+				mu.Lock()
+				out[item.Key()] = subscribed
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, item := range items {
+		pending <- item
+	}
+	close(pending)
+	wg.Wait()
+	return out
+}
+
+// subscribedProviders returns the providers subscribed in settings
+// that stream item in the settings country, nil if it fails.
+func (s *server) subscribedProviders(item client.Item, settings *account.Settings) []client.Provider {
+	providers, err := s.watchProviders(item)
+	if err != nil {
+		log.Println(err)
+		return nil
+	}
+	country := providers[settings.Country]
+	var subscribed []client.Provider
+	seen := make(map[int]bool)
+	for _, p := range country.Streaming() {
+		if settings.Subscribed(p.ProviderID) && !seen[p.ProviderID] {
+			seen[p.ProviderID] = true
+			subscribed = append(subscribed, p)
+		}
+	}
+	return subscribed
+}
+
+// availableLists returns the pages of the lists in idPage, as in
+// client.GetSortedLists, sorted by sortBy, with only the items
+// streamed by the providers subscribed in settings, and the
+// availability of those items. All the items of the lists are
+// filtered and then paginated, so the pages are full.
+func (s *server) availableLists(c *client.Client, settings *account.Settings, sortBy string, idPage ...int) ([]*client.List, map[string][]client.Provider, error) {
+	n := len(idPage) / 2
+	lists := make([]*client.List, n)
+	errs := make(chan error, 1)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			var err error
+			lists[i], err = c.GetWholeList(idPage[2*i], sortBy)
+			errs <- err
+		}(i)
+	}
+	var err error
+	for i := 0; i < n; i++ {
+		if e := <-errs; e != nil {
+			err = e
+		}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	var items []client.Item
+	for _, l := range lists {
+		for _, r := range l.Results {
+			items = append(items, r.Item())
+		}
+	}
+	available := s.availability(items, settings)
+	for i, l := range lists {
+		l.Results = availableOnly(l.Results, available)
+		lists[i] = listPage(l, idPage[2*i+1])
+	}
+	return lists, available, nil
+}
@@ -0,0 +1,305 @@
+package recommend
+
+import (
+	"errors"
+	"log"
+	"sort"
+	"strconv"
+
+	"github.com/rschio/movieApp/client"
+)
+
+const (
+	// maxCredits is the number of watched items whose
+	// credits are requested to add people to the taste.
+	maxCredits = 10
+	// maxSeeds is the number of watched movies, the most
+	// recently watched, that are seeds of the taste.
+	maxSeeds = 5
+	// topGenres and topPeople are the number of genres and
+	// people of the taste used to discover movies.
+	topGenres = 3
+	topPeople = 3
+	// peopleBonus is the score added to movies discovered with
+	// the person with the most weight in the taste.
+	peopleBonus = 0.5
+	// recommendedBonus and similarBonus are the scores added to
+	// the first of the TMDB recommendations and similar movies
+	// of a seed, the next ones get less. A movie related to many
+	// seeds gets the score of each one.
+	recommendedBonus = 1.0
+	similarBonus     = 0.5
+	// dismissedPenalty is the score subtracted from the first of
+	// the similar movies of a dismissed movie, the next ones get
+	// less. Only the last maxSeeds dismissed movies are used.
+	dismissedPenalty = 1.0
+	// voteWeight is the score of a movie with vote average 10,
+	// it breaks ties between movies with the same features.
+	voteWeight = 0.1
+)
+
+// Recommendation is a recommended movie.
+type Recommendation struct {
+	client.Result
+	// Because is the watched movie that contributed the most
+	// to the recommendation, nil if it was only discovered
+	// with the taste.
+	Because *client.Result
+}
+
+// Recommender recommends movies with the TMDB client c.
+type Recommender struct {
+	c *client.Client
+}
+
+// New returns a Recommender that requests TMDB with c.
+func New(c *client.Client) *Recommender {
+	return &Recommender{c: c}
+}
+
+// Taste builds the taste of the watch and watched lists, watched is
+// in the order the movies were watched. stars maps the keys of the
+// rated watched items to their ratings, in stars from 0.5 to 5, the
+// ratings change the weight of the items. The credits of the last
+// maxCredits watched items are requested concurrently to add their
+// actors and directors, the ones that fail are logged and left out,
+// and the last maxSeeds watched movies not disliked are the seeds
+// of the taste.
+func (r *Recommender) Taste(watch, watched []client.Result, stars map[string]float64) (*Taste, error) {
+	t := NewTaste()
+	for _, res := range watch {
+		t.Add(res, WatchWeight)
+	}
+	disliked := make(map[int]bool)
+	for _, res := range watched {
+		rating := stars[res.Key()]
+		t.Add(res, watchedWeight(rating))
+		if res.Type() == client.MediaMovie && rating > 0 && rating <= dislikedStars && !disliked[res.ID] {
+			disliked[res.ID] = true
+			t.Dismissed = append(t.Dismissed, res.ID)
+		}
+	}
+	seeded := make(map[int]bool)
+	for i := len(watched) - 1; i >= 0 && len(t.Seeds) < maxSeeds; i-- {
+		res := watched[i]
+		if res.Type() == client.MediaMovie && !seeded[res.ID] && !disliked[res.ID] {
+			seeded[res.ID] = true
+			t.Seeds = append(t.Seeds, res)
+		}
+	}
+	recent := watched
+	if len(recent) > maxCredits {
+		recent = recent[len(recent)-maxCredits:]
+	}
+	n := len(recent)
+	credits := make([]*client.Credits, n)
+	errs := make(chan error, 1)
+	for i := 0; i < n; i++ {
+		go func(i int, res client.Result) {
+			var err error
+			credits[i], err = r.c.GetCredits(res.Type(), res.ID)
+			errs <- err
+		}(i, recent[i])
+	}
+	for i := 0; i < n; i++ {
+		if err := <-errs; err != nil {
+			log.Println(err)
+		}
+	}
+	for i, c := range credits {
+		if c != nil {
+			t.AddCredits(c, watchedWeight(stars[recent[i].Key()]))
+		}
+	}
+	return t, nil
+}
+
+// query is a request of candidates. The movies of a query with
+// seed get bonus decreasing with their position in the results,
+// the ones of a discover get bonus. The movies of a dismissed
+// query lose bonus, also decreasing with their position.
+type query struct {
+	get       func(c *client.Client) (*client.SearchMovieResp, error)
+	bonus     float64
+	seed      *client.Result
+	dismissed bool
+}
+
+// discover returns a query that discovers movies with opts.
+func discover(opts *client.DiscoverOptions, bonus float64) query {
+	return query{
+		get:   func(c *client.Client) (*client.SearchMovieResp, error) { return c.Discover(opts) },
+		bonus: bonus,
+	}
+}
+
+// queries returns the queries of the seeds and the strongest signals
+// of t, or a discover of the popular movies if t is empty, and the
+// queries of the last dismissed movies.
+func (t *Taste) queries() []query {
+	var qs []query
+	for i := len(t.Dismissed) - 1; i >= 0 && i >= len(t.Dismissed)-maxSeeds; i-- {
+		id := t.Dismissed[i]
+		qs = append(qs, query{
+			get:       func(c *client.Client) (*client.SearchMovieResp, error) { return c.GetSimilar(id, 1) },
+			bonus:     dismissedPenalty,
+			dismissed: true,
+		})
+	}
+	if t.Empty() {
+		return append(qs, discover(&client.DiscoverOptions{}, 0))
+	}
+	for i := range t.Seeds {
+		seed := &t.Seeds[i]
+		qs = append(qs,
+			query{
+				get:   func(c *client.Client) (*client.SearchMovieResp, error) { return c.GetRecommendations(seed.ID, 1) },
+				bonus: recommendedBonus,
+				seed:  seed,
+			},
+			query{
+				get:   func(c *client.Client) (*client.SearchMovieResp, error) { return c.GetSimilar(seed.ID, 1) },
+				bonus: similarBonus,
+				seed:  seed,
+			},
+		)
+	}
+	genres := t.TopGenres(topGenres)
+	for _, g := range genres {
+		qs = append(qs, discover(&client.DiscoverOptions{Genres: []int{g}}, 0))
+	}
+	people := t.TopPeople(topPeople)
+	for _, p := range people {
+		bonus := peopleBonus * t.People[p] / t.People[people[0]]
+		qs = append(qs, discover(&client.DiscoverOptions{People: []int{p}}, bonus))
+	}
+	if d, ok := t.TopDecade(); ok {
+		opts := &client.DiscoverOptions{
+			ReleaseDateGTE: strconv.Itoa(d) + "-01-01",
+			ReleaseDateLTE: strconv.Itoa(d+9) + "-12-31",
+		}
+		if len(genres) > 0 {
+			opts.Genres = genres[:1]
+		}
+		qs = append(qs, discover(opts, 0))
+	}
+	if lang := t.TopLanguage(); lang != "" {
+		qs = append(qs, discover(&client.DiscoverOptions{OriginalLanguage: lang}, 0))
+	}
+	return qs
+}
+
+// candidate is a movie being scored.
+type candidate struct {
+	client.Result
+	// base is the score of the taste and the votes.
+	base float64
+	// discovered is the biggest bonus of the discovers.
+	discovered float64
+	// seeds maps the seeds IDs to their bonus.
+	seeds map[int]float64
+	// because is the seed with the biggest bonus.
+	because *client.Result
+	// penalty is the sum of the penalties of the dismissed queries.
+	penalty float64
+}
+
+func (c *candidate) score() float64 {
+	score := c.base + c.discovered - c.penalty
+	for _, b := range c.seeds {
+		score += b
+	}
+	return score
+}
+
+// add adds the bonus of q to c, pos is the position of c in
+// the results of q, with n results.
+func (c *candidate) add(q query, pos, n int) {
+	if q.dismissed {
+		c.penalty += q.bonus * float64(n-pos) / float64(n)
+		return
+	}
+	if q.seed == nil {
+		if q.bonus > c.discovered {
+			c.discovered = q.bonus
+		}
+		return
+	}
+	c.seeds[q.seed.ID] += q.bonus * float64(n-pos) / float64(n)
+	if c.because == nil || c.seeds[q.seed.ID] > c.seeds[c.because.ID] {
+		c.because = q.seed
+	}
+}
+
+// Recommend returns up to n movies ranked by how much they match
+// the taste t, without the items known by t and the keys in exclude.
+// Each movie is returned once, even if found by many queries. The
+// queries that fail are logged and left out, it fails only if all
+// of them fail.
+func (r *Recommender) Recommend(t *Taste, exclude map[string]bool, n int) ([]Recommendation, error) {
+	qs := t.queries()
+	// Request the candidates of each query concurrently.
+	pages := make([]*client.SearchMovieResp, len(qs))
+	errs := make(chan error, 1)
+	for i, q := range qs {
+		go func(i int, q query) {
+			var err error
+			pages[i], err = q.get(r.c)
+			errs <- err
+		}(i, q)
+	}
+	failed := 0
+	for range qs {
+		if err := <-errs; err != nil {
+			log.Println(err)
+			failed++
+		}
+	}
+	if failed == len(qs) {
+		return nil, errors.New("recommend: all the queries failed")
+	}
+	candidates := make(map[string]*candidate)
+	for i, page := range pages {
+		if page == nil {
+			continue
+		}
+		for pos, res := range page.Results {
+			key := res.Key()
+			if res.Adult || t.Known[key] || exclude[key] {
+				continue
+			}
+			c, ok := candidates[key]
+			if !ok {
+				c = &candidate{
+					Result: res,
+					base:   t.Score(res) + voteWeight*res.VoteAverage/10,
+					seeds:  make(map[int]float64),
+				}
+				candidates[key] = c
+			}
+			c.add(qs[i], pos, len(page.Results))
+		}
+	}
+	ranked := make([]*candidate, 0, len(candidates))
+	for _, c := range candidates {
+		ranked = append(ranked, c)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if a.score() != b.score() {
+			return a.score() > b.score()
+		}
+		if a.Popularity != b.Popularity {
+			return a.Popularity > b.Popularity
+		}
+		return a.ID < b.ID
+	})
+	if len(ranked) > n {
+		ranked = ranked[:n]
+	}
+	out := make([]Recommendation, len(ranked))
+	for i, c := range ranked {
+		out[i] = Recommendation{Result: c.Result, Because: c.because}
+	}
+	return out, nil
+}
@@ -0,0 +1,205 @@
+package recommend
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rschio/movieApp/client"
+	"github.com/rschio/movieApp/client/clienttest"
+)
+
+var (
+	godfather = client.Result{ID: 238, Title: "The Godfather", ReleaseDate: "1972-03-14",
+		OriginalLanguage: "en", GenreIds: []int{18, 80}}
+	cityOfGod = client.Result{ID: 598, Title: "City of God", ReleaseDate: "2002-02-05",
+		OriginalLanguage: "pt", GenreIds: []int{18, 80}}
+	forrestGump = client.Result{ID: 13, Title: "Forrest Gump", ReleaseDate: "1994-06-23",
+		OriginalLanguage: "en", GenreIds: []int{35, 18}}
+)
+
+func TestTaste(t *testing.T) {
+	taste := NewTaste()
+	taste.Add(godfather, WatchedWeight)
+	taste.Add(cityOfGod, WatchWeight)
+	taste.Add(godfather, WatchedWeight)
+	if got := taste.TopGenres(3); !reflect.DeepEqual(got, []int{18, 80}) {
+		t.Errorf("TopGenres = %v, want [18 80]", got)
+	}
+	if d, ok := taste.TopDecade(); !ok || d != 1970 {
+		t.Errorf("TopDecade = %d, %v, want 1970", d, ok)
+	}
+	if lang := taste.TopLanguage(); lang != "en" {
+		t.Errorf("TopLanguage = %q, want en", lang)
+	}
+	// Drama is in all items, the 1990s and comedy in none.
+	if got := taste.Score(forrestGump); got != 1+2.0/3 {
+		t.Errorf("Score(Forrest Gump) = %v, want %v", got, 1+2.0/3)
+	}
+	if taste.Score(godfather) <= taste.Score(cityOfGod) {
+		t.Error("the watched movie does not score more than the watch list one")
+	}
+
+	taste.AddCredits(&client.Credits{
+		Cast: []client.Cast{{ID: 1}, {ID: 2}},
+		Crew: []client.Crew{{ID: 3, Job: "Director"}, {ID: 4, Job: "Writer"}},
+	}, WatchedWeight)
+	taste.AddCredits(&client.Credits{Cast: []client.Cast{{ID: 2}}}, WatchWeight)
+	if got := taste.TopPeople(2); !reflect.DeepEqual(got, []int{2, 1}) {
+		t.Errorf("TopPeople = %v, want [2 1]", got)
+	}
+	if _, ok := taste.People[4]; ok {
+		t.Error("writer was added to the taste")
+	}
+}
+
+func TestTasteSeeds(t *testing.T) {
+	srv := clienttest.NewServer()
+	defer srv.Close()
+	rec := New(client.New(srv.URL, clienttest.Token, srv.Client()))
+	show := client.Result{ID: 1396, MediaType: client.MediaTV}
+	watched := []client.Result{godfather, cityOfGod}
+	for i := 0; i < maxSeeds; i++ {
+		watched = append(watched, forrestGump, show)
+	}
+	taste, err := rec.Taste(nil, watched, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Seeds are the last watched movies, once each.
+	if len(taste.Seeds) != 3 || taste.Seeds[0].ID != forrestGump.ID || taste.Seeds[2].ID != godfather.ID {
+		t.Errorf("got seeds %+v, want Forrest Gump, City of God and The Godfather", taste.Seeds)
+	}
+}
+
+func TestTasteRatings(t *testing.T) {
+	srv := clienttest.NewServer()
+	defer srv.Close()
+	rec := New(client.New(srv.URL, clienttest.Token, srv.Client()))
+	stars := map[string]float64{godfather.Key(): 4.5, cityOfGod.Key(): 1.5}
+	taste, err := rec.Taste(nil, []client.Result{godfather, cityOfGod, forrestGump}, stars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 4.5 stars weigh 3, 1.5 stars weigh 1 and the
+	// movie not rated weighs WatchedWeight.
+	if en, pt := taste.Languages["en"], taste.Languages["pt"]; en != 5 || pt != 1 {
+		t.Errorf("got language weights en %v and pt %v", en, pt)
+	}
+	// The disliked movie is not a seed, its similar movies are penalized.
+	for _, seed := range taste.Seeds {
+		if seed.ID == cityOfGod.ID {
+			t.Error("the disliked movie is a seed")
+		}
+	}
+	if len(taste.Dismissed) != 1 || taste.Dismissed[0] != cityOfGod.ID {
+		t.Errorf("Dismissed = %v, want [%d]", taste.Dismissed, cityOfGod.ID)
+	}
+}
+
+func TestRecommend(t *testing.T) {
+	srv := clienttest.NewServer()
+	defer srv.Close()
+	rec := New(client.New(srv.URL, clienttest.Token, srv.Client()))
+
+	taste, err := rec.Taste([]client.Result{forrestGump}, []client.Result{godfather}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exclude := map[string]bool{"movie/278": true}
+	picks, err := rec.Recommend(taste, exclude, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(picks) != 3 {
+		t.Fatalf("got %d picks, want 3", len(picks))
+	}
+	// The sequel matches the genres, decade and
+	// language of the watched movie.
+	if picks[0].ID != 240 {
+		t.Errorf("first pick = %d, want 240", picks[0].ID)
+	}
+	if b := picks[0].Because; b == nil || b.ID != godfather.ID {
+		t.Errorf("first pick because of %+v, want The Godfather", b)
+	}
+	seen := make(map[string]bool)
+	for _, p := range picks {
+		if taste.Known[p.Key()] || exclude[p.Key()] || seen[p.Key()] {
+			t.Errorf("picked known, excluded or repeated %s", p.Key())
+		}
+		seen[p.Key()] = true
+	}
+
+	// The dismissed movie is not recommended, and its similar
+	// movies rank lower.
+	index := func(picks []Recommendation, id int) int {
+		for i, p := range picks {
+			if p.ID == id {
+				return i
+			}
+		}
+		return -1
+	}
+	taste, err = rec.Taste(nil, []client.Result{godfather}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before, err := rec.Recommend(taste, nil, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	taste.Dismiss(client.MovieItem(278))
+	after, err := rec.Recommend(taste, nil, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if index(after, 278) >= 0 {
+		t.Error("recommended the dismissed movie")
+	}
+	if index(before, 13) > index(before, 680) || index(after, 13) < index(after, 680) {
+		t.Errorf("Forrest Gump, similar to the dismissed movie, did not rank lower: %v then %v", before, after)
+	}
+
+	// Without history the popular movies are recommended.
+	picks, err = rec.Recommend(NewTaste(), nil, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(picks) != 1 || picks[0].ID != 278 || picks[0].Because != nil {
+		t.Errorf("got %+v, want the most popular movie 278", picks)
+	}
+}
+
+func TestRecommendFailures(t *testing.T) {
+	fake := clienttest.NewServer()
+	defer fake.Close()
+	// The requests of The Godfather fail.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/238/") {
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		fake.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+	rec := New(client.New(srv.URL, clienttest.Token, srv.Client()))
+
+	taste, err := rec.Taste(nil, []client.Result{godfather, cityOfGod}, nil)
+	if err != nil {
+		t.Fatalf("the failed credits failed the taste: %v", err)
+	}
+	picks, err := rec.Recommend(taste, nil, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(picks) == 0 {
+		t.Error("got no picks from the queries that loaded")
+	}
+	for _, p := range picks {
+		if b := p.Because; b != nil && b.ID == godfather.ID {
+			t.Errorf("picked %d because of The Godfather, whose queries failed", p.ID)
+		}
+	}
+}
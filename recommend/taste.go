@@ -0,0 +1,213 @@
+// Package recommend ranks movies for a profile. It builds a weighted
+// taste from the profile's watch and watched lists, with the genres,
+// decades, original languages and people of the movies, and scores
+// the TMDB recommendations and similar movies of the watched movies
+// together with the movies discovered with the strongest signals of
+// the taste. The movies similar to the ones dismissed by the profile
+// are penalized.
+package recommend
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/rschio/movieApp/client"
+)
+
+// Weights of the lists in the taste, watched movies are a
+// stronger signal than the ones the profile wants to watch.
+const (
+	WatchWeight   = 1.0
+	WatchedWeight = 2.0
+)
+
+// Ratings of the watched items, in stars from 0.5 to 5. An item
+// rated neutralStars has WatchedWeight, better rated items weigh
+// more and worse rated weigh less. The movies rated up to
+// dislikedStars are not seeds and their similar movies are
+// penalized, like the dismissed ones.
+const (
+	neutralStars  = 3.0
+	dislikedStars = 2.0
+)
+
+// watchedWeight returns the weight of a watched item rated
+// with stars, WatchedWeight if it was not rated.
+func watchedWeight(stars float64) float64 {
+	if stars <= 0 {
+		return WatchedWeight
+	}
+	return WatchedWeight * stars / neutralStars
+}
+
+// maxCast is the number of top billed actors of
+// a movie added to the taste.
+const maxCast = 5
+
+// Taste is the weighted taste of a profile, each map has the
+// sum of the weights of the movies with the feature.
+type Taste struct {
+	// Genres maps the genre IDs to their weight.
+	Genres map[int]float64
+	// Decades maps the release decades, e.g. 1990, to their weight.
+	Decades map[int]float64
+	// Languages maps the ISO 639-1 original languages to their weight.
+	Languages map[string]float64
+	// People maps the IDs of actors and directors to their weight.
+	People map[int]float64
+	// Known are the keys of the movies and tv shows added to
+	// or dismissed from the taste, e.g. "movie/238".
+	Known map[string]bool
+	// Seeds are the watched movies whose TMDB recommendations
+	// and similar movies are recommended.
+	Seeds []client.Result
+	// Dismissed are the IDs of the movies the profile is not
+	// interested in or disliked, their similar movies are
+	// penalized.
+	Dismissed []int
+	// total is the sum of the weights of the items added.
+	total float64
+}
+
+// NewTaste returns an empty taste.
+func NewTaste() *Taste {
+	return &Taste{
+		Genres:    make(map[int]float64),
+		Decades:   make(map[int]float64),
+		Languages: make(map[string]float64),
+		People:    make(map[int]float64),
+		Known:     make(map[string]bool),
+	}
+}
+
+// Add adds the genres, decade and language of r to the taste with
+// weight w. Items already known are not added again.
+func (t *Taste) Add(r client.Result, w float64) {
+	if t.Known[r.Key()] {
+		return
+	}
+	t.Known[r.Key()] = true
+	t.total += w
+	for _, g := range r.GenreIds {
+		t.Genres[g] += w
+	}
+	if d, ok := decade(r.Date()); ok {
+		t.Decades[d] += w
+	}
+	if r.OriginalLanguage != "" {
+		t.Languages[r.OriginalLanguage] += w
+	}
+}
+
+// Dismiss marks the item as not interesting, it is not recommended
+// and, if it is a movie, the movies similar to it are penalized.
+func (t *Taste) Dismiss(item client.Item) {
+	t.Known[item.Key()] = true
+	if item.MediaType == client.MediaMovie {
+		t.Dismissed = append(t.Dismissed, item.MediaID)
+	}
+}
+
+// AddCredits adds the top billed actors and the directors
+// of credits to the taste with weight w.
+func (t *Taste) AddCredits(credits *client.Credits, w float64) {
+	for i, c := range credits.Cast {
+		if i == maxCast {
+			break
+		}
+		t.People[c.ID] += w
+	}
+	for _, d := range credits.Directors() {
+		t.People[d.ID] += w
+	}
+}
+
+// Empty reports if nothing was added to the taste.
+func (t *Taste) Empty() bool {
+	return t.total == 0
+}
+
+// Score returns how much r matches the taste, the share of the
+// weight of the taste that has each of r's genres, decade and
+// language. The score does not include people, they are not in
+// the results of discover.
+func (t *Taste) Score(r client.Result) float64 {
+	if t.total == 0 {
+		return 0
+	}
+	var score float64
+	for _, g := range r.GenreIds {
+		score += t.Genres[g]
+	}
+	if d, ok := decade(r.Date()); ok {
+		score += t.Decades[d]
+	}
+	score += t.Languages[r.OriginalLanguage]
+	return score / t.total
+}
+
+// TopGenres returns the IDs of the n genres with the most weight.
+func (t *Taste) TopGenres(n int) []int {
+	return topInts(t.Genres, n)
+}
+
+// TopPeople returns the IDs of the n people with the most weight.
+func (t *Taste) TopPeople(n int) []int {
+	return topInts(t.People, n)
+}
+
+// TopDecade returns the decade with the most weight,
+// false if the taste has no decade.
+func (t *Taste) TopDecade() (int, bool) {
+	top := topInts(t.Decades, 1)
+	if len(top) == 0 {
+		return 0, false
+	}
+	return top[0], true
+}
+
+// TopLanguage returns the original language with the most
+// weight or an empty string if the taste has no language.
+func (t *Taste) TopLanguage() string {
+	var (
+		top    string
+		weight float64
+	)
+	for lang, w := range t.Languages {
+		if w > weight || (w == weight && lang < top) {
+			top, weight = lang, w
+		}
+	}
+	return top
+}
+
+// topInts returns the n keys of m with the most weight, ties
+// are broken by the smallest key so the result is stable.
+func topInts(m map[int]float64, n int) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if m[keys[i]] != m[keys[j]] {
+			return m[keys[i]] > m[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	if len(keys) > n {
+		keys = keys[:n]
+	}
+	return keys
+}
+
+// decade returns the decade of date, in the format YYYY-MM-DD.
+func decade(date string) (int, bool) {
+	if len(date) < 4 {
+		return 0, false
+	}
+	year, err := strconv.Atoi(date[:4])
+	if err != nil {
+		return 0, false
+	}
+	return year - year%10, true
+}
@@ -0,0 +1,183 @@
+// Package review stores the star ratings and the private
+// reviews of the movies and tv shows of each profile.
+package review
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/rschio/movieApp/client"
+	"github.com/rschio/movieApp/store"
+)
+
+// bucket is the store bucket of reviews, the keys are
+// profile keys and the values are profileReviews.
+const bucket = "reviews"
+
+// MaxStars is the best rating, ratings go
+// from half a star to MaxStars in half stars.
+const MaxStars = 5
+
+// MaxTextLen is the max length of the text of a review.
+const MaxTextLen = 1000
+
+var (
+	// ErrInvalidStars is returned when the rating is
+	// not a multiple of half a star up to MaxStars.
+	ErrInvalidStars = errors.New("review: invalid stars")
+	// ErrTextTooLong is returned when the text of
+	// the review is longer than MaxTextLen.
+	ErrTextTooLong = errors.New("review: text too long")
+)
+
+// Review is the rating and review of a profile of a movie or tv show.
+type Review struct {
+	Item client.Item
+	// Stars is the rating, from 0.5 to MaxStars,
+	// zero if the item was only reviewed.
+	Stars float64
+	// Text is the private review, it may be empty.
+	Text      string
+	UpdatedAt time.Time
+}
+
+// Icons returns the names of the Material icons of the rating,
+// e.g. "star", "star", "star_half", "star_border", "star_border"
+// for 2.5 stars.
+func (r *Review) Icons() []string {
+	icons := make([]string, MaxStars)
+	for i := range icons {
+		switch n := float64(i); {
+		case r.Stars >= n+1:
+			icons[i] = "star"
+		case r.Stars > n:
+			icons[i] = "star_half"
+		default:
+			icons[i] = "star_border"
+		}
+	}
+	return icons
+}
+
+// ValidStars reports if stars is a rating from half
+// a star to MaxStars in half stars, or zero.
+func ValidStars(stars float64) bool {
+	return stars >= 0 && stars <= MaxStars && stars*2 == math.Floor(stars*2)
+}
+
+// profileReviews maps the item keys, e.g. "movie/238",
+// to the reviews of the profile.
+type profileReviews map[string]*Review
+
+// Book stores the reviews of the profiles.
+type Book struct {
+	db *store.Store
+}
+
+// NewBook creates a book that persists reviews in db.
+func NewBook(db *store.Store) *Book {
+	return &Book{db: db}
+}
+
+// Set sets the rating and the review of profile of item, an item
+// without stars and text has its review deleted.
+func (b *Book) Set(profile string, item client.Item, stars float64, text string) error {
+	text = strings.TrimSpace(text)
+	if !ValidStars(stars) {
+		return ErrInvalidStars
+	}
+	if len(text) > MaxTextLen {
+		return ErrTextTooLong
+	}
+	item.Comment = ""
+	reviews := make(profileReviews)
+	return b.db.Update(bucket, profile, &reviews, func() error {
+		if stars == 0 && text == "" {
+			delete(reviews, item.Key())
+			return nil
+		}
+		reviews[item.Key()] = &Review{
+			Item:      item,
+			Stars:     stars,
+			Text:      text,
+			UpdatedAt: time.Now(),
+		}
+		return nil
+	})
+}
+
+// Import sets the ratings of profile of the items of ratings, only
+// their items and stars are used. The items already rated are skipped
+// and the items only reviewed keep their text. It returns the number
+// of ratings set.
+func (b *Book) Import(profile string, ratings []Review) (int, error) {
+	for _, r := range ratings {
+		if !ValidStars(r.Stars) {
+			return 0, ErrInvalidStars
+		}
+	}
+	var n int
+	reviews := make(profileReviews)
+	err := b.db.Update(bucket, profile, &reviews, func() error {
+		n = 0
+		for _, r := range ratings {
+			key := r.Item.Key()
+			old, ok := reviews[key]
+			if r.Stars == 0 || (ok && old.Stars > 0) {
+				continue
+			}
+			if !ok {
+				item := r.Item
+				item.Comment = ""
+				old = &Review{Item: item}
+				reviews[key] = old
+			}
+			old.Stars = r.Stars
+			old.UpdatedAt = time.Now()
+			n++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// Get returns the review of profile of item, nil if
+// the item was not reviewed.
+func (b *Book) Get(profile string, item client.Item) (*Review, error) {
+	reviews, err := b.All(profile)
+	if err != nil {
+		return nil, err
+	}
+	return reviews[item.Key()], nil
+}
+
+// All returns the reviews of profile keyed by
+// the item keys, e.g. "movie/238".
+func (b *Book) All(profile string) (map[string]*Review, error) {
+	reviews := make(profileReviews)
+	if _, err := b.db.Get(bucket, profile, &reviews); err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+// Stars returns the ratings of profile keyed by the item
+// keys, the items only reviewed have no rating.
+func (b *Book) Stars(profile string) (map[string]float64, error) {
+	reviews, err := b.All(profile)
+	if err != nil {
+		return nil, err
+	}
+	stars := make(map[string]float64)
+	for key, r := range reviews {
+		if r.Stars > 0 {
+			stars[key] = r.Stars
+		}
+	}
+	return stars, nil
+}
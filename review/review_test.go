@@ -0,0 +1,106 @@
+package review
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rschio/movieApp/client"
+	"github.com/rschio/movieApp/store"
+)
+
+func newBook(t *testing.T) *Book {
+	db, err := store.Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewBook(db)
+}
+
+func TestValidStars(t *testing.T) {
+	for _, stars := range []float64{0, 0.5, 3, 4.5, 5} {
+		if !ValidStars(stars) {
+			t.Errorf("ValidStars(%v) = false", stars)
+		}
+	}
+	for _, stars := range []float64{-1, 0.25, 5.5, 10} {
+		if ValidStars(stars) {
+			t.Errorf("ValidStars(%v) = true", stars)
+		}
+	}
+}
+
+func TestBook(t *testing.T) {
+	b := newBook(t)
+	const profile = "a@b.com/0"
+	godfather, fightClub := client.MovieItem(238), client.MovieItem(550)
+	if err := b.Set(profile, godfather, 4.5, " A masterpiece. "); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Set(profile, fightClub, 0, "Only a note"); err != nil {
+		t.Fatal(err)
+	}
+	r, err := b.Get(profile, godfather)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r == nil || r.Stars != 4.5 || r.Text != "A masterpiece." {
+		t.Fatalf("got review %+v", r)
+	}
+	want := []string{"star", "star", "star", "star", "star_half"}
+	if got := r.Icons(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Icons = %v, want %v", got, want)
+	}
+	stars, err := b.Stars(profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stars) != 1 || stars["movie/238"] != 4.5 {
+		t.Errorf("Stars = %v, want only movie/238", stars)
+	}
+	if err := b.Set(profile, godfather, 6, ""); err != ErrInvalidStars {
+		t.Errorf("got error %v, want ErrInvalidStars", err)
+	}
+	// Without stars and text the review is deleted.
+	if err := b.Set(profile, fightClub, 0, " "); err != nil {
+		t.Fatal(err)
+	}
+	if r, err := b.Get(profile, fightClub); err != nil || r != nil {
+		t.Errorf("Get deleted = %+v, %v, want nil", r, err)
+	}
+	// Reviews are per profile.
+	if r, err := b.Get("a@b.com/1", godfather); err != nil || r != nil {
+		t.Errorf("Get of other profile = %+v, %v, want nil", r, err)
+	}
+}
+
+func TestImport(t *testing.T) {
+	b := newBook(t)
+	const profile = "a@b.com/0"
+	godfather, fightClub, pulpFiction := client.MovieItem(238), client.MovieItem(550), client.MovieItem(680)
+	if err := b.Set(profile, godfather, 5, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Set(profile, fightClub, 0, "Only a review."); err != nil {
+		t.Fatal(err)
+	}
+	n, err := b.Import(profile, []Review{
+		{Item: godfather, Stars: 3},
+		{Item: fightClub, Stars: 4},
+		{Item: pulpFiction, Stars: 2.5},
+	})
+	if err != nil || n != 2 {
+		t.Fatalf("Import = %d, %v, want 2", n, err)
+	}
+	all, err := b.All(profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The ratings set in the app are kept.
+	if all["movie/238"].Stars != 5 || all["movie/550"].Stars != 4 ||
+		all["movie/550"].Text != "Only a review." || all["movie/680"].Stars != 2.5 {
+		t.Errorf("got reviews %+v %+v %+v", all["movie/238"], all["movie/550"], all["movie/680"])
+	}
+	if _, err := b.Import(profile, []Review{{Item: godfather, Stars: 7}}); err != ErrInvalidStars {
+		t.Errorf("got error %v, want ErrInvalidStars", err)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/rschio/movieApp/account"
+	"github.com/rschio/movieApp/review"
+)
+
+// reviewItem sets the profile's rating, in stars, and private review of
+// a movie or tv show. The params are stars and review, without both the
+// review is deleted. The param back set to "browse" redirects to the
+// browse page, otherwise it redirects to the item page.
+func (s *server) reviewItem(w http.ResponseWriter, r *http.Request, acc *account.Account) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	const path = "/review/"
+	item, err := itemFromPath(path, r)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	id, err := account.ProfileFromRequest(r, acc)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	var stars float64
+	if v := strings.TrimSpace(r.FormValue("stars")); v != "" {
+		stars, err = strconv.ParseFloat(v, 64)
+		if err != nil || !review.ValidStars(stars) {
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+			return
+		}
+	}
+	text := strings.TrimSpace(r.FormValue("review"))
+	if len(text) > review.MaxTextLen {
+		http.Error(w, "Review too long", http.StatusBadRequest)
+		return
+	}
+	err = s.reviews.Set(acc.ProfileKey(id), item, stars, text)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	s.stats.invalidate(acc.ProfileKey(id))
+	// The ratings weight the suggestions.
+	s.queueSuggestions(r, acc, id, true)
+	if r.FormValue("back") == "browse" {
+		http.Redirect(w, r, "/browse", http.StatusFound)
+		return
+	}
+	http.Redirect(w, r, "/"+item.Key(), http.StatusFound)
+}
+
+// starOptions returns the ratings of the review form, from half
+// a star to review.MaxStars, with the rating of rev selected.
+// rev may be nil.
+func starOptions(rev *review.Review) []formOption {
+	var opts []formOption
+	for stars := 0.5; stars <= review.MaxStars; stars += 0.5 {
+		v := strconv.FormatFloat(stars, 'f', -1, 64)
+		opts = append(opts, formOption{
+			Value:    v,
+			Label:    v,
+			Selected: rev != nil && rev.Stars == stars,
+		})
+	}
+	return opts
+}
@@ -5,15 +5,22 @@ import (
 	"context"
 	"log"
 	"time"
+
+	"github.com/rschio/movieApp/client"
 )
 
 // ScheduleMovie stores the informations to
-// schedule a movie.
+// schedule a movie or a tv show.
 type ScheduledMovie struct {
-	Time     time.Time
-	MovieID  int
-	UserName string
-	Email    string
+	Time time.Time
+	// MediaType is the type of MovieID,
+	// client.MediaMovie or client.MediaTV.
+	MediaType string
+	MovieID   int
+	UserName  string
+	Email     string
+	// Language is the language of the email, see i18n.
+	Language string
 }
 
 // ScheduleList is list of movies to remeber
@@ -71,7 +78,8 @@ func (sl *ScheduleList) popBeforeTime(t time.Time) []*ScheduledMovie {
 }
 
 // schedule checks, periodically, if server should send
-// email to users to rember of some movie.
+// email to users to rember of some movie, and sends the
+// years in review in January.
 func (s *server) schedule(ctx context.Context) {
 	// Start the heap.
 	s.mu.Lock()
@@ -94,13 +102,30 @@ func (s *server) schedule(ctx context.Context) {
 			for _, r := range registers {
 				// Send a email to user Email and MovieID, concurrently.
 				go func(r *ScheduledMovie) {
-					err := s.mailer.SendScheduledMovie(r.UserName, r.Email, r.MovieID)
+					trailer := s.trailerURL(r.MediaType, r.MovieID)
+					err := s.mailer.SendScheduledMovie(r.Language, r.UserName, r.Email, r.MediaType, r.MovieID, trailer)
 					if err != nil {
 						// If error just log. Best effort.
 						log.Println(err)
 					}
 				}(r)
 			}
+			s.checkYearReviews(now)
 		}
 	}
 }
+
+// trailerURL returns the link to the best trailer of the media
+// with type mediaType and ID id or an empty string if it has
+// no trailer.
+func (s *server) trailerURL(mediaType string, id int) string {
+	videos, err := s.client.GetVideos(mediaType, id)
+	if err != nil {
+		log.Println(err)
+		return ""
+	}
+	if v := client.BestTrailer(videos, "en"); v != nil {
+		return v.URL()
+	}
+	return ""
+}
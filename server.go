@@ -2,23 +2,30 @@ package main
 
 import (
 	"context"
-	"fmt"
 	"html/template"
 	"log"
-	"math/rand"
+	"net/http"
 	"path/filepath"
+	"strings"
 	"sync"
 
 	firebase "firebase.google.com/go"
 	"firebase.google.com/go/auth"
+	"github.com/rschio/movieApp/account"
 	"github.com/rschio/movieApp/client"
+	"github.com/rschio/movieApp/diary"
+	"github.com/rschio/movieApp/i18n"
 	"github.com/rschio/movieApp/mail"
+	"github.com/rschio/movieApp/progress"
+	"github.com/rschio/movieApp/review"
+	"github.com/rschio/movieApp/store"
 	"google.golang.org/api/option"
 )
 
 type server struct {
-	// tmpl is used to render web pages.
-	tmpl *template.Template
+	// tmpls are used to render web pages, they
+	// map the UI languages to their templates.
+	tmpls map[string]*template.Template
 	// auther is used to authenticate and
 	// make login with firebase.
 	auther *auth.Client
@@ -32,29 +39,127 @@ type server struct {
 	// scheduleList schedules the movies to
 	// send to email on determined time.
 	scheduleList *ScheduleList
+	// store persists the profiles' data that
+	// is not stored in TMDB lists.
+	store *store.Store
+	// progress tracks the watched episodes
+	// of tv shows.
+	progress *progress.Tracker
+	// providers caches the watch providers
+	// of movies and tv shows.
+	providers *providersCache
+	// reviews stores the ratings and reviews
+	// of the profiles.
+	reviews *review.Book
+	// diary logs when the profiles watched
+	// movies and tv shows.
+	diary *diary.Diary
+	// stats caches the viewing statistics
+	// of the profiles.
+	stats *statsCache
+	// titles caches the titles of the
+	// movies and tv shows of the diary.
+	titles *titlesCache
+	// yearReviews is held while the years in
+	// review are being sent.
+	yearReviews chan struct{}
+	// baseURL is the URL of the app, used in the
+	// links of the emails sent in background.
+	baseURL string
+	// suggestions queues the profiles whose
+	// suggestions must be refreshed.
+	suggestions *suggestQueue
 }
 
 type serverConfig struct {
 	templatePath    string
 	autherCredsPath string
-	clientAPIToken  string
-	mailerAPIKey    string
-	mailerName      string
-	mailerAddr      string
+	// clientBaseURL is the TMDB API URL, if empty
+	// client.DefaultURL is used.
+	clientBaseURL  string
+	clientAPIToken string
+	mailerAPIKey   string
+	mailerName     string
+	mailerAddr     string
+	storePath      string
+	// baseURL is the URL of the app, e.g.
+	// "https://movies.example.com".
+	baseURL string
 }
 
 func NewServer(cfg *serverConfig) *server {
+	s := newServer(cfg)
+	s.auther = NewAuther(cfg.autherCredsPath)
+	return s
+}
+
+// newServer creates a server without auther, the handlers
+// can be used directly without Authorize, like in tests.
+func newServer(cfg *serverConfig) *server {
 	s := new(server)
 	tmpls := filepath.Join(cfg.templatePath, "*")
 	list := make(ScheduleList, 0, 10)
-	s.tmpl = template.Must(template.ParseGlob(tmpls))
-	s.auther = NewAuther(cfg.autherCredsPath)
-	s.client = client.New(client.DefaultURL, cfg.clientAPIToken, nil)
+	baseURL := cfg.clientBaseURL
+	if baseURL == "" {
+		baseURL = client.DefaultURL
+	}
+	s.tmpls = parseTemplates(tmpls)
+	s.client = client.New(baseURL, cfg.clientAPIToken, nil)
 	s.mailer = mail.NewMailer(cfg.mailerName, cfg.mailerAddr, cfg.mailerAPIKey)
 	s.scheduleList = &list
+	s.store = NewStore(cfg.storePath)
+	s.progress = progress.NewTracker(s.store)
+	s.providers = newProvidersCache()
+	s.reviews = review.NewBook(s.store)
+	s.diary = diary.New(s.store)
+	s.stats = newStatsCache()
+	s.titles = newTitlesCache()
+	s.suggestions = newSuggestQueue()
+	s.yearReviews = make(chan struct{}, 1)
+	s.baseURL = strings.TrimSuffix(cfg.baseURL, "/")
 	return s
 }
 
+// parseTemplates parses the templates matching pattern once for
+// each UI language, with the translation functions of the language.
+func parseTemplates(pattern string) map[string]*template.Template {
+	base := template.New("").Funcs(i18n.FuncMap(i18n.DefaultLanguage))
+	base = template.Must(base.ParseGlob(pattern))
+	tmpls := make(map[string]*template.Template)
+	for _, lang := range i18n.Languages() {
+		tmpls[lang] = template.Must(base.Clone()).Funcs(i18n.FuncMap(lang))
+	}
+	return tmpls
+}
+
+// uiLanguage returns the UI language of the request, the language
+// of the account settings if it is supported, otherwise the best
+// match of the Accept-Language header. acc may be nil.
+func uiLanguage(r *http.Request, acc *account.Account) string {
+	var tags []string
+	if acc != nil && acc.Settings != nil && acc.Settings.Language != "" {
+		tags = append(tags, acc.Settings.Language)
+	}
+	tags = append(tags, i18n.ParseAcceptLanguage(r.Header.Get("Accept-Language"))...)
+	return i18n.Match(tags...)
+}
+
+// tmpl returns the templates in the UI language of the request.
+func (s *server) tmpl(r *http.Request, acc *account.Account) *template.Template {
+	return s.tmpls[uiLanguage(r, acc)]
+}
+
+func NewStore(path string) *store.Store {
+	if path == "" {
+		log.Println("STOREPATH not set, using a memory only store")
+	}
+	db, err := store.Open(path)
+	if err != nil {
+		log.Fatalf("error opening store: %v", err)
+	}
+	return db
+}
+
 func NewAuther(credsFile string) *auth.Client {
 	opt := option.WithCredentialsFile(credsFile)
 	app, err := firebase.NewApp(context.Background(), nil, opt)
@@ -68,18 +173,20 @@ func NewAuther(credsFile string) *auth.Client {
 	return client
 }
 
-func (s *server) suggestMovie(listID int, genres []int) error {
-	movies, err := s.client.DiscoverMovie(genres)
-	if err != nil {
-		log.Println(err)
-		return err
+// clientFor returns the client of the account. The lists of accounts
+// linked to TMDB belong to the user's TMDB account, so they are
+// accessed with the user's access token. The content is requested
+// in the language and region of the account's settings.
+func (s *server) clientFor(acc *account.Account) *client.Client {
+	c := s.client
+	if acc.Settings == nil {
+		return c
+	}
+	if acc.Settings.Linked() {
+		c = c.WithToken(acc.Settings.TMDBAccessToken)
 	}
-	n := len(movies)
-	if n <= 0 {
-		log.Println("failed to suggest movie")
-		return fmt.Errorf("failed to suggest movie")
+	if acc.Settings.Language != "" || acc.Settings.Country != "" {
+		c = c.WithLocale(acc.Settings.Language, acc.Settings.Country)
 	}
-	i := rand.Intn(n)
-	_, err = s.client.AddItems(listID, movies[i].ID)
-	return err
+	return c
 }
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"github.com/rschio/movieApp/account"
+	"github.com/rschio/movieApp/client"
+)
+
+// settingsPage is the data used to render settings.html.
+type settingsPage struct {
+	Settings  *account.Settings
+	Languages []formOption
+	Countries []formOption
+	Providers []formOption
+}
+
+// contentLanguages are the languages offered for the TMDB
+// content, the first one is the TMDB default.
+var contentLanguages = []formOption{
+	{Value: "en-US", Label: "English"},
+	{Value: "pt-BR", Label: "Português (Brasil)"},
+	{Value: "pt-PT", Label: "Português (Portugal)"},
+	{Value: "es-ES", Label: "Español"},
+	{Value: "fr-FR", Label: "Français"},
+	{Value: "de-DE", Label: "Deutsch"},
+	{Value: "it-IT", Label: "Italiano"},
+	{Value: "ja-JP", Label: "日本語"},
+}
+
+// languageParam returns the content language with name name in
+// params or an empty string if it is not one of contentLanguages.
+func languageParam(params url.Values, name string) string {
+	language := params.Get(name)
+	for _, l := range contentLanguages {
+		if l.Value == language {
+			return language
+		}
+	}
+	return ""
+}
+
+// settings displays the account settings form and saves
+// the settings sent as a POST request.
+func (s *server) settings(w http.ResponseWriter, r *http.Request, acc *account.Account) {
+	if r.Method == "POST" {
+		if err := r.ParseForm(); err != nil {
+			log.Println(err)
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+			return
+		}
+		var (
+			country   = r.Form.Get("country")
+			providers = intsParam(r.Form, "providers")
+			language  = languageParam(r.Form, "language")
+			updated   account.Settings
+		)
+		// Update only the fields of the form, the sync
+		// may change the settings meanwhile.
+		err := account.UpdateSettings(s.store, acc.Email, func(st *account.Settings) error {
+			// Providers are different in each country, so
+			// changing the country clears the providers.
+			if country != st.Country {
+				st.Providers = nil
+			} else {
+				st.Providers = providers
+			}
+			st.Country = country
+			st.Language = language
+			updated = *st
+			return nil
+		})
+		if err != nil {
+			log.Println(err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		*acc.Settings = updated
+		http.Redirect(w, r, "/settings", http.StatusFound)
+		return
+	}
+	page, err := s.settingsPage(acc.Settings)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	s.tmpl(r, acc).ExecuteTemplate(w, "settings.html", page)
+}
+
+// settingsPage requests the countries and the providers of
+// the settings country and builds the settings form options.
+func (s *server) settingsPage(settings *account.Settings) (*settingsPage, error) {
+	var (
+		providers []client.Provider
+		errs      = make(chan error, 1)
+	)
+	go func() {
+		if settings.Country == "" {
+			errs <- nil
+			return
+		}
+		var err error
+		providers, err = s.client.GetProviders(client.MediaMovie, settings.Country)
+		errs <- err
+	}()
+	regions, err := s.client.GetWatchRegions()
+	if e := <-errs; e != nil {
+		err = e
+	}
+	if err != nil {
+		return nil, err
+	}
+	page := &settingsPage{Settings: settings}
+	for _, l := range contentLanguages {
+		l.Selected = l.Value == settings.Language
+		page.Languages = append(page.Languages, l)
+	}
+	sort.Slice(regions, func(i, j int) bool {
+		return regions[i].EnglishName < regions[j].EnglishName
+	})
+	for _, r := range regions {
+		page.Countries = append(page.Countries, formOption{
+			Value:    r.ISO3166,
+			Label:    r.EnglishName,
+			Selected: r.ISO3166 == settings.Country,
+		})
+	}
+	sort.Slice(providers, func(i, j int) bool {
+		return providers[i].DisplayPriority < providers[j].DisplayPriority
+	})
+	for _, p := range providers {
+		page.Providers = append(page.Providers, formOption{
+			Value:    strconv.Itoa(p.ProviderID),
+			Label:    p.ProviderName,
+			Selected: settings.Subscribed(p.ProviderID),
+		})
+	}
+	return page, nil
+}
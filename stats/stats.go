@@ -0,0 +1,257 @@
+// Package stats computes the viewing statistics of a profile from
+// its watched movies: movies and hours watched per month, the top
+// genres, decades, languages, directors and actors, the profile's
+// ratings against the TMDB votes and the streaks of days watching.
+// It also summarizes the movies watched in a year.
+package stats
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/rschio/movieApp/client"
+)
+
+const (
+	// Months is the number of months, up to the current
+	// one, with the movies and hours watched.
+	Months = 12
+	// Top is the number of genres, decades, languages,
+	// directors and actors in the stats.
+	Top = 5
+	// maxCast is the number of top billed actors
+	// of each movie counted.
+	maxCast = 5
+)
+
+// Watch is a watch of a movie.
+type Watch struct {
+	Movie *client.Movie
+	// Credits are the credits of the movie, nil if unknown.
+	Credits *client.Credits
+	// WatchedAt is the day the movie was watched, zero if
+	// the movie is only on the watched list.
+	WatchedAt time.Time
+	// Stars is the profile's rating of the movie,
+	// from 0.5 to 5, zero if not rated.
+	Stars float64
+}
+
+// Month is the movies watched in a month.
+type Month struct {
+	// Start is the first day of the month.
+	Start time.Time
+	// Watches is the number of watches, including rewatches.
+	Watches int
+	// Minutes is the sum of the runtimes of the watches.
+	Minutes int
+}
+
+// Hours returns the hours watched in the month.
+func (m Month) Hours() float64 {
+	return float64(m.Minutes) / 60
+}
+
+// Count is the number of movies with a feature, like
+// a genre or an actor.
+type Count struct {
+	// ID is the ID of the genre or person, zero
+	// for decades and languages.
+	ID    int
+	Name  string
+	Count int
+}
+
+// Stats are the viewing statistics of a profile.
+type Stats struct {
+	// Movies is the number of distinct movies watched.
+	Movies int
+	// Watches is the number of logged watches, including
+	// rewatches, and Minutes is the sum of their runtimes.
+	Watches int
+	Minutes int
+	// Months are the last Months months, the oldest first.
+	Months []Month
+	// The top features of the movies watched, the
+	// ones with the most movies first.
+	Genres    []Count
+	Decades   []Count
+	Languages []Count
+	Directors []Count
+	Actors    []Count
+	// Rated is the number of movies rated by the profile,
+	// AverageRating is the average of their ratings, in
+	// the TMDB scale from 0 to 10, and AverageVote is the
+	// average of their TMDB votes.
+	Rated         int
+	AverageRating float64
+	AverageVote   float64
+	// CurrentStreak is the number of days in a row, up to
+	// today or yesterday, with a watch, and LongestStreak
+	// is the longest of them.
+	CurrentStreak int
+	LongestStreak int
+}
+
+// Hours returns the hours watched.
+func (s *Stats) Hours() float64 {
+	return float64(s.Minutes) / 60
+}
+
+// counter counts the movies of each feature.
+type counter struct {
+	counts map[string]*Count
+}
+
+func newCounter() *counter {
+	return &counter{counts: make(map[string]*Count)}
+}
+
+func (c *counter) add(key string, id int, name string) {
+	n, ok := c.counts[key]
+	if !ok {
+		n = &Count{ID: id, Name: name}
+		c.counts[key] = n
+	}
+	n.Count++
+}
+
+// top returns the n counts with the most movies, ties
+// are broken by name so the result is stable.
+func (c *counter) top(n int) []Count {
+	out := make([]Count, 0, len(c.counts))
+	for _, cnt := range c.counts {
+		out = append(out, *cnt)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Name < out[j].Name
+	})
+	if len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
+
+// Compute computes the stats of watches at now. The features and
+// the ratings count each movie once, the months and the streaks
+// count each watch with a date.
+func Compute(watches []Watch, now time.Time) *Stats {
+	s := new(Stats)
+	var (
+		genres    = newCounter()
+		decades   = newCounter()
+		languages = newCounter()
+		directors = newCounter()
+		actors    = newCounter()
+		seen      = make(map[int]bool)
+		days      = make(map[time.Time]bool)
+		ratings   float64
+		votes     float64
+	)
+	first := monthStart(now).AddDate(0, 1-Months, 0)
+	s.Months = make([]Month, Months)
+	for i := range s.Months {
+		s.Months[i].Start = first.AddDate(0, i, 0)
+	}
+	for _, w := range watches {
+		m := w.Movie
+		if !w.WatchedAt.IsZero() {
+			s.Watches++
+			s.Minutes += m.Runtime
+			days[day(w.WatchedAt)] = true
+			start := monthStart(w.WatchedAt)
+			if i := monthsBetween(first, start); i >= 0 && i < Months {
+				s.Months[i].Watches++
+				s.Months[i].Minutes += m.Runtime
+			}
+		}
+		if seen[m.ID] {
+			continue
+		}
+		seen[m.ID] = true
+		s.Movies++
+		for _, g := range m.Genres {
+			genres.add(strconv.Itoa(g.ID), g.ID, g.Name)
+		}
+		if len(m.ReleaseDate) >= 4 {
+			if year, err := strconv.Atoi(m.ReleaseDate[:4]); err == nil {
+				d := strconv.Itoa(year-year%10) + "s"
+				decades.add(d, 0, d)
+			}
+		}
+		if m.OriginalLanguage != "" {
+			languages.add(m.OriginalLanguage, 0, m.OriginalLanguage)
+		}
+		if w.Credits != nil {
+			for _, d := range w.Credits.Directors() {
+				directors.add(strconv.Itoa(d.ID), d.ID, d.Name)
+			}
+			for i, a := range w.Credits.Cast {
+				if i == maxCast {
+					break
+				}
+				actors.add(strconv.Itoa(a.ID), a.ID, a.Name)
+			}
+		}
+		if w.Stars > 0 {
+			s.Rated++
+			ratings += 2 * w.Stars
+			votes += m.VoteAverage
+		}
+	}
+	s.Genres = genres.top(Top)
+	s.Decades = decades.top(Top)
+	s.Languages = languages.top(Top)
+	s.Directors = directors.top(Top)
+	s.Actors = actors.top(Top)
+	if s.Rated > 0 {
+		s.AverageRating = ratings / float64(s.Rated)
+		s.AverageVote = votes / float64(s.Rated)
+	}
+	s.CurrentStreak, s.LongestStreak = streaks(days, day(now))
+	return s
+}
+
+// streaks returns the current and the longest streaks of days
+// in a row in days. The current streak ends today or yesterday.
+func streaks(days map[time.Time]bool, today time.Time) (current, longest int) {
+	for d := range days {
+		// Count only from the first day of each streak.
+		if days[d.AddDate(0, 0, -1)] {
+			continue
+		}
+		n := 1
+		for days[d.AddDate(0, 0, n)] {
+			n++
+		}
+		if n > longest {
+			longest = n
+		}
+		last := d.AddDate(0, 0, n-1)
+		if last.Equal(today) || last.Equal(today.AddDate(0, 0, -1)) {
+			current = n
+		}
+	}
+	return current, longest
+}
+
+// day returns midnight in UTC of the date of t.
+func day(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// monthStart returns the first day of the month of t.
+func monthStart(t time.Time) time.Time {
+	y, m, _ := t.Date()
+	return time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// monthsBetween returns the number of months from a to b.
+func monthsBetween(a, b time.Time) int {
+	return (b.Year()-a.Year())*12 + int(b.Month()) - int(a.Month())
+}
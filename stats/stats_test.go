@@ -0,0 +1,104 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rschio/movieApp/client"
+)
+
+var (
+	drama = client.Genre{ID: 18, Name: "Drama"}
+	crime = client.Genre{ID: 80, Name: "Crime"}
+
+	godfather = &client.Movie{ID: 238, Title: "The Godfather", Runtime: 175, ReleaseDate: "1972-03-14",
+		OriginalLanguage: "en", VoteAverage: 8.7, Genres: []client.Genre{drama, crime}}
+	cityOfGod = &client.Movie{ID: 598, Title: "City of God", Runtime: 130, ReleaseDate: "2002-02-05",
+		OriginalLanguage: "pt", VoteAverage: 8.4, Genres: []client.Genre{drama, crime}}
+	fightClub = &client.Movie{ID: 550, Title: "Fight Club", Runtime: 139, ReleaseDate: "1999-10-15",
+		OriginalLanguage: "en", VoteAverage: 8.4, Genres: []client.Genre{drama}}
+
+	coppola = &client.Credits{
+		Cast: []client.Cast{{ID: 3084, Name: "Marlon Brando"}},
+		Crew: []client.Crew{{ID: 1776, Name: "Francis Ford Coppola", Job: "Director"}},
+	}
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestCompute(t *testing.T) {
+	now := time.Date(2026, 10, 19, 20, 0, 0, 0, time.UTC)
+	watches := []Watch{
+		{Movie: godfather, Credits: coppola, WatchedAt: date(2026, 10, 18), Stars: 5},
+		{Movie: godfather, Credits: coppola, WatchedAt: date(2026, 10, 19), Stars: 5},
+		{Movie: cityOfGod, WatchedAt: date(2026, 9, 1), Stars: 3.5},
+		{Movie: cityOfGod, WatchedAt: date(2026, 9, 2)},
+		{Movie: cityOfGod, WatchedAt: date(2026, 9, 3)},
+		{Movie: fightClub},
+		{Movie: fightClub, WatchedAt: date(2024, 1, 1)},
+	}
+	s := Compute(watches, now)
+	if s.Movies != 3 || s.Watches != 6 || s.Minutes != 2*175+3*130+139 {
+		t.Errorf("got %d movies, %d watches and %d minutes", s.Movies, s.Watches, s.Minutes)
+	}
+	if len(s.Months) != Months || !s.Months[0].Start.Equal(date(2025, 11, 1)) {
+		t.Fatalf("got months from %v", s.Months[0].Start)
+	}
+	oct, sep := s.Months[Months-1], s.Months[Months-2]
+	if oct.Watches != 2 || oct.Minutes != 350 || sep.Watches != 3 || sep.Hours() != 6.5 {
+		t.Errorf("got October %+v and September %+v", oct, sep)
+	}
+	if s.Genres[0].Name != "Drama" || s.Genres[0].Count != 3 || s.Genres[1].Count != 2 {
+		t.Errorf("Genres = %+v", s.Genres)
+	}
+	if len(s.Decades) != 3 || s.Decades[0].Name != "1970s" {
+		t.Errorf("Decades = %+v", s.Decades)
+	}
+	if s.Languages[0].Name != "en" || s.Languages[0].Count != 2 {
+		t.Errorf("Languages = %+v", s.Languages)
+	}
+	if len(s.Directors) != 1 || s.Directors[0].ID != 1776 || len(s.Actors) != 1 {
+		t.Errorf("got directors %+v and actors %+v", s.Directors, s.Actors)
+	}
+	// Each movie is rated once, 5 and 3.5 stars are 10 and 7.
+	if s.Rated != 2 || s.AverageRating != 8.5 || s.AverageVote != (8.7+8.4)/2 {
+		t.Errorf("got %d rated, average %v and vote %v", s.Rated, s.AverageRating, s.AverageVote)
+	}
+	if s.CurrentStreak != 2 || s.LongestStreak != 3 {
+		t.Errorf("got streaks %d and %d, want 2 and 3", s.CurrentStreak, s.LongestStreak)
+	}
+	// The streak is broken without a watch yesterday or today.
+	if s := Compute(watches, now.AddDate(0, 0, 2)); s.CurrentStreak != 0 {
+		t.Errorf("got current streak %d, want 0", s.CurrentStreak)
+	}
+}
+
+func TestYear(t *testing.T) {
+	watches := []Watch{
+		{Movie: fightClub, WatchedAt: date(2026, 12, 31), Stars: 4},
+		{Movie: godfather, WatchedAt: date(2026, 2, 1), Stars: 5},
+		{Movie: cityOfGod, WatchedAt: date(2026, 1, 5), Stars: 4},
+		{Movie: godfather, WatchedAt: date(2026, 3, 1), Stars: 5},
+		{Movie: cityOfGod, WatchedAt: date(2025, 12, 31)},
+		{Movie: godfather},
+	}
+	y := Year(watches, 2026)
+	if y.Movies != 3 || y.Watches != 4 || y.Minutes != 139+2*175+130 {
+		t.Errorf("got %d movies, %d watches and %d minutes", y.Movies, y.Watches, y.Minutes)
+	}
+	if y.First.Title != "City of God" || y.Last.Title != "Fight Club" || y.Longest.Title != "The Godfather" {
+		t.Errorf("got first %+v, last %+v and longest %+v", y.First, y.Last, y.Longest)
+	}
+	// City of God was watched before Fight Club, both with 4 stars.
+	if len(y.TopRated) != 3 || y.TopRated[0].ID != 238 || y.TopRated[1].ID != 598 {
+		t.Errorf("TopRated = %+v", y.TopRated)
+	}
+	if y.TopGenre.Name != "Drama" || y.TopGenre.Count != 3 {
+		t.Errorf("TopGenre = %+v", y.TopGenre)
+	}
+	if y := Year(watches, 2024); !y.Empty() || y.First != nil {
+		t.Errorf("got review of 2024 %+v, want empty", y)
+	}
+}
@@ -0,0 +1,118 @@
+package stats
+
+import (
+	"sort"
+	"strconv"
+	"time"
+)
+
+// topRated is the number of the best rated
+// movies in the year in review.
+const topRated = 3
+
+// Highlight is a movie of the year in review.
+type Highlight struct {
+	ID      int
+	Title   string
+	Runtime int
+	// Stars is the profile's rating, zero if not rated.
+	Stars float64
+	// WatchedAt is the first day the movie was watched in the year.
+	WatchedAt time.Time
+}
+
+// YearReview is the summary of the movies watched in a year.
+type YearReview struct {
+	Year int
+	// Movies is the number of distinct movies watched, Watches
+	// includes the rewatches and Minutes is the sum of the
+	// runtimes of the watches.
+	Movies  int
+	Watches int
+	Minutes int
+	// TopRated are the best rated movies, the best first.
+	TopRated []Highlight
+	// TopGenre is the genre with the most movies,
+	// with zero Count if no movie has genres.
+	TopGenre Count
+	// Longest is the movie with the longest runtime, First and
+	// Last are the first and last movies watched. They are nil
+	// if no movie was watched.
+	Longest *Highlight
+	First   *Highlight
+	Last    *Highlight
+}
+
+// Hours returns the hours watched in the year.
+func (y *YearReview) Hours() float64 {
+	return float64(y.Minutes) / 60
+}
+
+// Empty reports if no movie was watched in the year.
+func (y *YearReview) Empty() bool {
+	return y.Watches == 0
+}
+
+// Year computes the review of the movies watched in year. Only the
+// watches with a date in year count, each movie is rated once.
+func Year(watches []Watch, year int) *YearReview {
+	y := &YearReview{Year: year}
+	var inYear []Watch
+	for _, w := range watches {
+		if !w.WatchedAt.IsZero() && w.WatchedAt.Year() == year {
+			inYear = append(inYear, w)
+		}
+	}
+	if len(inYear) == 0 {
+		return y
+	}
+	sort.SliceStable(inYear, func(i, j int) bool {
+		return inYear[i].WatchedAt.Before(inYear[j].WatchedAt)
+	})
+	genres := newCounter()
+	seen := make(map[int]bool)
+	var rated []Highlight
+	for _, w := range inYear {
+		y.Watches++
+		y.Minutes += w.Movie.Runtime
+		if seen[w.Movie.ID] {
+			continue
+		}
+		seen[w.Movie.ID] = true
+		y.Movies++
+		h := highlight(w)
+		if y.Longest == nil || h.Runtime > y.Longest.Runtime {
+			y.Longest = &h
+		}
+		if h.Stars > 0 {
+			rated = append(rated, h)
+		}
+		for _, g := range w.Movie.Genres {
+			genres.add(strconv.Itoa(g.ID), g.ID, g.Name)
+		}
+	}
+	first, last := highlight(inYear[0]), highlight(inYear[len(inYear)-1])
+	y.First, y.Last = &first, &last
+	// The best rated first, ties by the first watched.
+	sort.SliceStable(rated, func(i, j int) bool {
+		return rated[i].Stars > rated[j].Stars
+	})
+	if len(rated) > topRated {
+		rated = rated[:topRated]
+	}
+	y.TopRated = rated
+	if top := genres.top(1); len(top) > 0 {
+		y.TopGenre = top[0]
+	}
+	return y
+}
+
+func highlight(w Watch) Highlight {
+	return Highlight{
+		ID:        w.Movie.ID,
+		Title:     w.Movie.DisplayTitle(),
+		Runtime:   w.Movie.Runtime,
+		Stars:     w.Stars,
+		WatchedAt: w.WatchedAt,
+	}
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rschio/movieApp/account"
+	"github.com/rschio/movieApp/client"
+	"github.com/rschio/movieApp/stats"
+)
+
+const (
+	// statsTTL is how long the stats of a profile are cached,
+	// the changes of the profile's watches drop them earlier.
+	statsTTL = time.Hour
+	// movieInfoTTL is how long the details and credits of
+	// a movie are cached for the stats.
+	movieInfoTTL = 7 * 24 * time.Hour
+	// maxStatsRequests is the maximum number of concurrent
+	// requests of movies details and credits.
+	maxStatsRequests = 8
+)
+
+// movieInfo are the details and credits of a movie.
+type movieInfo struct {
+	movie   *client.Movie
+	credits *client.Credits
+	fetched time.Time
+}
+
+type statsEntry struct {
+	stats    *stats.Stats
+	computed time.Time
+}
+
+// statsCache caches the stats of the profiles by profile key and
+// language, the titles of the stats are localized, and the movies
+// of the stats by language and ID, computing the stats requests
+// TMDB for every watched movie.
+type statsCache struct {
+	mu sync.Mutex
+	// profiles maps the profile keys to the stats
+	// of the profile in each language.
+	profiles map[string]map[string]*statsEntry
+	movies   map[string]*movieInfo
+}
+
+func newStatsCache() *statsCache {
+	return &statsCache{
+		profiles: make(map[string]map[string]*statsEntry),
+		movies:   make(map[string]*movieInfo),
+	}
+}
+
+func (sc *statsCache) get(profile, lang string) (*stats.Stats, bool) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	e, ok := sc.profiles[profile][lang]
+	if !ok || time.Since(e.computed) > statsTTL {
+		return nil, false
+	}
+	return e.stats, true
+}
+
+func (sc *statsCache) set(profile, lang string, st *stats.Stats) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	// Drop the expired entries, so the cache does not grow forever.
+	for k, langs := range sc.profiles {
+		for l, e := range langs {
+			if time.Since(e.computed) > statsTTL {
+				delete(langs, l)
+			}
+		}
+		if len(langs) == 0 {
+			delete(sc.profiles, k)
+		}
+	}
+	if sc.profiles[profile] == nil {
+		sc.profiles[profile] = make(map[string]*statsEntry)
+	}
+	sc.profiles[profile][lang] = &statsEntry{stats: st, computed: time.Now()}
+}
+
+// invalidate drops the stats of profile in all the
+// languages, its watches changed.
+func (sc *statsCache) invalidate(profile string) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	delete(sc.profiles, profile)
+}
+
+func (sc *statsCache) movie(key string) (*movieInfo, bool) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	info, ok := sc.movies[key]
+	if !ok || time.Since(info.fetched) > movieInfoTTL {
+		return nil, false
+	}
+	return info, true
+}
+
+func (sc *statsCache) setMovie(key string, info *movieInfo) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	for k, i := range sc.movies {
+		if time.Since(i.fetched) > movieInfoTTL {
+			delete(sc.movies, k)
+		}
+	}
+	sc.movies[key] = info
+}
+
+// movieInfo returns the details and credits of the movie with ID id,
+// requested with c, from cache if possible.
+func (s *server) movieInfo(c *client.Client, id int) (*movieInfo, error) {
+	key := c.LanguageCode() + "/" + strconv.Itoa(id)
+	if info, ok := s.stats.movie(key); ok {
+		return info, nil
+	}
+	var (
+		credits *client.Credits
+		errs    = make(chan error, 1)
+	)
+	go func() {
+		var err error
+		credits, err = c.GetCredits(client.MediaMovie, id)
+		errs <- err
+	}()
+	movie, err := c.GetMovie(id)
+	if e := <-errs; e != nil {
+		err = e
+	}
+	if err != nil {
+		return nil, err
+	}
+	info := &movieInfo{movie: movie, credits: credits, fetched: time.Now()}
+	s.stats.setMovie(key, info)
+	return info, nil
+}
+
+// profileStats returns the stats of the movies watched by the profile
+// with ID id, in the language of c, from cache if possible.
+func (s *server) profileStats(c *client.Client, acc *account.Account, id int) (*stats.Stats, error) {
+	key := acc.ProfileKey(id)
+	if st, ok := s.stats.get(key, c.LanguageCode()); ok {
+		return st, nil
+	}
+	watches, err := s.watches(c, key, acc.Profiles[id].WatchedListID)
+	if err != nil {
+		return nil, err
+	}
+	st := stats.Compute(watches, time.Now())
+	s.stats.set(key, c.LanguageCode(), st)
+	return st, nil
+}
+
+// watches returns the movies watched by the profile with key profile,
+// with their details and credits requested with c and the profile's
+// ratings. The watches are the diary entries and the movies of the
+// list watchedListID not in the diary, without date. It is best
+// effort, the movies with errors are left out.
+func (s *server) watches(c *client.Client, profile string, watchedListID int) ([]stats.Watch, error) {
+	watched, err := c.GetAllItems(watchedListID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.diary.Entries(profile, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	stars, err := s.reviews.Stars(profile)
+	if err != nil {
+		return nil, err
+	}
+	var watches []stats.Watch
+	logged := make(map[int]bool)
+	for _, e := range entries {
+		if e.Item.MediaType == client.MediaMovie {
+			logged[e.Item.MediaID] = true
+			watches = append(watches, stats.Watch{
+				Movie:     &client.Movie{ID: e.Item.MediaID},
+				WatchedAt: e.WatchedAt,
+			})
+		}
+	}
+	for _, r := range watched {
+		if r.Type() == client.MediaMovie && !logged[r.ID] {
+			logged[r.ID] = true
+			watches = append(watches, stats.Watch{Movie: &client.Movie{ID: r.ID}})
+		}
+	}
+	// Request the movies concurrently, once each.
+	var (
+		mu    sync.Mutex
+		wg    sync.WaitGroup
+		sem   = make(chan struct{}, maxStatsRequests)
+		infos = make(map[int]*movieInfo)
+	)
+	for movieID := range logged {
+		wg.Add(1)
+		go func(movieID int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			info, err := s.movieInfo(c, movieID)
+			<-sem
+			if err != nil {
+				log.Println(err)
+				return
+			}
+			mu.Lock()
+			infos[movieID] = info
+			mu.Unlock()
+		}(movieID)
+	}
+	wg.Wait()
+	out := watches[:0]
+	for _, w := range watches {
+		info, ok := infos[w.Movie.ID]
+		if !ok {
+			continue
+		}
+		w.Stars = stars[client.MovieItem(w.Movie.ID).Key()]
+		w.Movie, w.Credits = info.movie, info.credits
+		out = append(out, w)
+	}
+	return out, nil
+}
+
+// showStats displays the viewing statistics of the profile.
+func (s *server) showStats(w http.ResponseWriter, r *http.Request, acc *account.Account) {
+	id, err := account.ProfileFromRequest(r, acc)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	st, err := s.profileStats(s.clientFor(acc), acc, id)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	s.tmpl(r, acc).ExecuteTemplate(w, "stats.html", st)
+}
@@ -0,0 +1,161 @@
+// Package store implements a small key value store
+// persisted as a JSON file. Values are grouped in
+// buckets and encoded as JSON.
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// Store is a key value store safe for concurrent use.
+type Store struct {
+	mu      sync.Mutex
+	path    string
+	buckets map[string]map[string]json.RawMessage
+}
+
+// Open opens the store persisted at path, the file is created
+// at first write if it does not exist. An empty path opens a
+// store that is kept only in memory.
+func Open(path string) (*Store, error) {
+	s := &Store{
+		path:    path,
+		buckets: make(map[string]map[string]json.RawMessage),
+	}
+	if path == "" {
+		return s, nil
+	}
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(b, &s.buckets); err != nil {
+		return nil, fmt.Errorf("failed to decode store: %v", err)
+	}
+	return s, nil
+}
+
+// Get decodes the value of key in bucket into dst and
+// reports if the key was found.
+func (s *Store) Get(bucket, key string, dst interface{}) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.get(bucket, key, dst)
+}
+
+// Put sets the value of key in bucket to v.
+func (s *Store) Put(bucket, key string, v interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.put(bucket, key, v); err != nil {
+		return err
+	}
+	return s.save()
+}
+
+// Update decodes the value of key in bucket into v, if it
+// exists, calls fn and stores v if fn returns no error.
+// The store is locked during fn, so the read-modify-write
+// is atomic. fn must not use the store.
+func (s *Store) Update(bucket, key string, v interface{}, fn func() error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.get(bucket, key, v); err != nil {
+		return err
+	}
+	if err := fn(); err != nil {
+		return err
+	}
+	if err := s.put(bucket, key, v); err != nil {
+		return err
+	}
+	return s.save()
+}
+
+// Delete deletes key from bucket.
+func (s *Store) Delete(bucket, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.buckets[bucket]
+	if !ok {
+		return nil
+	}
+	if _, ok := b[key]; !ok {
+		return nil
+	}
+	delete(b, key)
+	return s.save()
+}
+
+// Keys returns the sorted keys of bucket.
+func (s *Store) Keys(bucket string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b := s.buckets[bucket]
+	keys := make([]string, 0, len(b))
+	for k := range b {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (s *Store) get(bucket, key string, dst interface{}) (bool, error) {
+	raw, ok := s.buckets[bucket][key]
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal(raw, dst); err != nil {
+		return false, fmt.Errorf("failed to decode %s/%s: %v", bucket, key, err)
+	}
+	return true, nil
+}
+
+func (s *Store) put(bucket, key string, v interface{}) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	b, ok := s.buckets[bucket]
+	if !ok {
+		b = make(map[string]json.RawMessage)
+		s.buckets[bucket] = b
+	}
+	b[key] = raw
+	return nil
+}
+
+// save writes the store to a temporary file and renames
+// it to path, so a crash never leaves a partial file.
+func (s *Store) save() error {
+	if s.path == "" {
+		return nil
+	}
+	b, err := json.Marshal(s.buckets)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
@@ -0,0 +1,88 @@
+package store
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+type value struct {
+	Name  string
+	Count int
+}
+
+func TestStorePersists(t *testing.T) {
+	dir, err := ioutil.TempDir("", "store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "store.json")
+
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	want := value{Name: "a", Count: 1}
+	if err := s.Put("bucket", "key", want); err != nil {
+		t.Fatalf("failed to put: %v", err)
+	}
+	// Reopen the store and check the value.
+	s, err = Open(path)
+	if err != nil {
+		t.Fatalf("failed to reopen store: %v", err)
+	}
+	var got value
+	ok, err := s.Get("bucket", "key", &got)
+	if err != nil || !ok {
+		t.Fatalf("Get = %v, %v, want true, nil", ok, err)
+	}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestStoreUpdate(t *testing.T) {
+	s, err := Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		var v value
+		err := s.Update("bucket", "key", &v, func() error {
+			v.Count++
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("failed to update: %v", err)
+		}
+	}
+	var v value
+	if _, err := s.Get("bucket", "key", &v); err != nil || v.Count != 3 {
+		t.Errorf("got %+v, %v, want Count 3", v, err)
+	}
+}
+
+func TestStoreKeysAndDelete(t *testing.T) {
+	s, err := Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range []string{"b", "a", "c"} {
+		if err := s.Put("bucket", k, k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Delete("bucket", "b"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := s.Keys("bucket"), []string{"a", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Keys = %v, want %v", got, want)
+	}
+	var v string
+	if ok, _ := s.Get("bucket", "b", &v); ok {
+		t.Errorf("deleted key was found")
+	}
+}
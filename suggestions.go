@@ -0,0 +1,412 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rschio/movieApp/account"
+	"github.com/rschio/movieApp/client"
+	"github.com/rschio/movieApp/i18n"
+	"github.com/rschio/movieApp/recommend"
+)
+
+const (
+	// suggestionsBucket is the store bucket of the suggestions
+	// of the profiles, the keys are profile keys.
+	suggestionsBucket = "suggestions"
+	// maxSuggestions is the number of movies kept
+	// in the profiles' SujestionsList.
+	maxSuggestions = 10
+	// suggestionTTL is how long a movie stays suggested, then it
+	// is rotated out to make room for new suggestions.
+	suggestionTTL = 7 * 24 * time.Hour
+	// rotatedCooldown is how long a movie rotated out is not
+	// suggested again.
+	rotatedCooldown = 30 * 24 * time.Hour
+	// suggestInterval is the interval of the periodic refresh
+	// of the suggestions, profiles refreshed less than
+	// suggestInterval ago are skipped.
+	suggestInterval = 24 * time.Hour
+	// suggestQueueSize is the number of profiles waiting
+	// for a refresh of their suggestions.
+	suggestQueueSize = 100
+	// maxDismissed is the number of dismissed movies kept
+	// for each profile, the oldest are forgotten.
+	maxDismissed = 100
+)
+
+// suggestions is the state of the suggestions of a profile.
+type suggestions struct {
+	// Email is the email of the profile's account.
+	Email   string
+	Profile account.Profile
+	// Language is the language of the suggestions comments.
+	Language string
+	// Added maps the keys of the movies in SujestionsList
+	// to when they were suggested.
+	Added map[string]time.Time
+	// Rotated maps the keys of the movies rotated out of
+	// SujestionsList to when they were rotated.
+	Rotated map[string]time.Time
+	// Dismissed are the suggestions the profile is not
+	// interested in, the most recently dismissed last.
+	Dismissed []client.Item
+	// LastRefresh is the time of the last successful refresh.
+	LastRefresh time.Time
+}
+
+// suggestQueue is a queue of the profile keys whose suggestions
+// must be refreshed, each profile is queued at most once.
+type suggestQueue struct {
+	keys    chan string
+	mu      sync.Mutex
+	pending map[string]bool
+}
+
+func newSuggestQueue() *suggestQueue {
+	return &suggestQueue{
+		keys:    make(chan string, suggestQueueSize),
+		pending: make(map[string]bool),
+	}
+}
+
+// push queues key, unless it is already queued, and reports if key
+// is queued. It does not block, when the queue is full key is dropped
+// and waits for the periodic refresh.
+func (q *suggestQueue) push(key string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.pending[key] {
+		return true
+	}
+	select {
+	case q.keys <- key:
+		q.pending[key] = true
+		return true
+	default:
+		return false
+	}
+}
+
+// pop marks key, received from keys, as not queued, so the
+// changes made during its refresh queue it again.
+func (q *suggestQueue) pop(key string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.pending, key)
+}
+
+// Actions on the suggestions.
+const (
+	// suggestionWatch adds the suggestion to WatchList.
+	suggestionWatch = "watch"
+	// suggestionSeen adds the suggestion to WatchedList.
+	suggestionSeen = "seen"
+	// suggestionDismiss dismisses the suggestion, it is not
+	// suggested again and its similar movies rank lower.
+	suggestionDismiss = "dismiss"
+)
+
+// suggestionFeedback removes a movie or tv show from SujestionsList
+// and, with the param action, adds it to WatchList, to WatchedList,
+// logging it in the diary today, or dismisses it. The suggestions
+// are refreshed to learn from the feedback.
+func (s *server) suggestionFeedback(w http.ResponseWriter, r *http.Request, acc *account.Account) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	const path = "/suggestion/"
+	item, err := itemFromPath(path, r)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	id, err := account.ProfileFromRequest(r, acc)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	profile := acc.Profiles[id]
+	c := s.clientFor(acc)
+	action := r.FormValue("action")
+	switch action {
+	case suggestionWatch:
+		_, err = c.AddItems(profile.WatchListID, item)
+	case suggestionSeen:
+		_, err = c.AddItems(profile.WatchedListID, item)
+		if err == nil {
+			// Best effort, the diary is only a history.
+			if _, err := s.diary.Log(acc.ProfileKey(id), item, time.Now(), ""); err != nil {
+				log.Println(err)
+			}
+			s.rememberProfile(r, acc, id)
+		}
+	case suggestionDismiss:
+		var st suggestions
+		err = s.store.Update(suggestionsBucket, acc.ProfileKey(id), &st, func() error {
+			st.Dismissed = append(st.Dismissed, item)
+			if len(st.Dismissed) > maxDismissed {
+				st.Dismissed = st.Dismissed[len(st.Dismissed)-maxDismissed:]
+			}
+			return nil
+		})
+	default:
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	_, err = c.DeleteItems(profile.SujestionsListID, item)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	s.stats.invalidate(acc.ProfileKey(id))
+	s.queueSuggestions(r, acc, id, true)
+	http.Redirect(w, r, "/browse", http.StatusFound)
+}
+
+// queueSuggestions queues the refresh of the suggestions of the
+// profile with ID id. Unless listsChanged, a profile is queued only
+// if it was never queued or its lists or language changed.
+func (s *server) queueSuggestions(r *http.Request, acc *account.Account, id int, listsChanged bool) {
+	key := acc.ProfileKey(id)
+	profile := acc.Profiles[id]
+	lang := uiLanguage(r, acc)
+	var st suggestions
+	found, err := s.store.Get(suggestionsBucket, key, &st)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	if found && !listsChanged && st.Profile == profile && st.Language == lang {
+		return
+	}
+	err = s.store.Update(suggestionsBucket, key, &st, func() error {
+		st.Email = acc.Email
+		st.Profile = profile
+		st.Language = lang
+		return nil
+	})
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	s.suggestions.push(key)
+}
+
+// refreshSuggestions refreshes the suggestions of the profile with
+// key key, with the client of the profile's account.
+func (s *server) refreshSuggestions(key string) error {
+	var st suggestions
+	found, err := s.store.Get(suggestionsBucket, key, &st)
+	if err != nil || !found {
+		return err
+	}
+	settings, err := account.LoadSettings(s.store, st.Email)
+	if err != nil {
+		return err
+	}
+	stars, err := s.reviews.Stars(key)
+	if err != nil {
+		return err
+	}
+	c := s.clientFor(&account.Account{Email: st.Email, Settings: settings})
+	if err := s.suggest(c, &st, stars, time.Now()); err != nil {
+		return err
+	}
+	// Only the refresh changes the suggested movies, the
+	// profile and language may have changed meanwhile.
+	var cur suggestions
+	return s.store.Update(suggestionsBucket, key, &cur, func() error {
+		cur.Added = st.Added
+		cur.Rotated = st.Rotated
+		cur.LastRefresh = st.LastRefresh
+		return nil
+	})
+}
+
+// queueStaleSuggestions queues the profiles whose suggestions were
+// not refreshed in the last interval.
+func (s *server) queueStaleSuggestions(interval time.Duration) {
+	for _, key := range s.store.Keys(suggestionsBucket) {
+		var st suggestions
+		if _, err := s.store.Get(suggestionsBucket, key, &st); err != nil {
+			log.Println(err)
+			continue
+		}
+		if time.Since(st.LastRefresh) >= interval {
+			s.suggestions.push(key)
+		}
+	}
+}
+
+// suggestWorker refreshes the suggestions of the queued profiles, one
+// at a time, and queues the stale ones each interval.
+func (s *server) suggestWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.queueStaleSuggestions(interval)
+		case key := <-s.suggestions.keys:
+			s.suggestions.pop(key)
+			if err := s.refreshSuggestions(key); err != nil {
+				log.Printf("failed to refresh suggestions of %s: %v", key, err)
+			}
+		}
+	}
+}
+
+// suggest fills the SujestionsList of the profile of st, up to
+// maxSuggestions, with the movies that best match the taste of its
+// watch and watched lists, weighted by the ratings in stars, c is the
+// client of the profile's lists. Suggestions that are now on the
+// watch or watched list or dismissed are removed, the ones older
+// than suggestionTTL or over maxSuggestions are rotated out, and
+// movies on any of the lists, dismissed or rotated out are not
+// suggested again. The movies similar to the dismissed ones rank
+// lower. The suggestions of a watched movie have a comment in
+// st.Language, e.g. "Because you watched The Godfather".
+func (s *server) suggest(c *client.Client, st *suggestions, stars map[string]float64, now time.Time) error {
+	profile := st.Profile
+	var (
+		watch, watched []client.Result
+		errs           = make(chan error, 1)
+	)
+	go func() {
+		var err error
+		watch, err = c.GetAllItems(profile.WatchListID)
+		errs <- err
+	}()
+	go func() {
+		var err error
+		watched, err = c.GetAllItems(profile.WatchedListID)
+		errs <- err
+	}()
+	suggested, err := c.GetAllItems(profile.SujestionsListID)
+	for i := 0; i < 2; i++ {
+		if e := <-errs; e != nil {
+			err = e
+		}
+	}
+	if err != nil {
+		return err
+	}
+	known := make(map[string]bool)
+	for _, r := range append(watch, watched...) {
+		known[r.Key()] = true
+	}
+	for _, item := range st.Dismissed {
+		known[item.Key()] = true
+	}
+	added := make(map[string]time.Time)
+	rotated := make(map[string]time.Time)
+	for key, t := range st.Rotated {
+		if now.Sub(t) < rotatedCooldown {
+			rotated[key] = t
+		}
+	}
+	var remove, kept []client.Item
+	for _, r := range suggested {
+		key := r.Key()
+		t, ok := st.Added[key]
+		if !ok {
+			// Suggested before the suggestions were tracked.
+			t = now
+		}
+		switch {
+		case known[key]:
+			remove = append(remove, r.Item())
+		case now.Sub(t) >= suggestionTTL:
+			remove = append(remove, r.Item())
+			rotated[key] = now
+		default:
+			added[key] = t
+			kept = append(kept, r.Item())
+		}
+	}
+	// The list may have more than maxSuggestions, e.g. filled before
+	// the suggestions were tracked, the oldest are rotated out, the
+	// untracked ones first.
+	if len(kept) > maxSuggestions {
+		sort.SliceStable(kept, func(i, j int) bool {
+			return st.Added[kept[i].Key()].After(st.Added[kept[j].Key()])
+		})
+		for _, item := range kept[maxSuggestions:] {
+			remove = append(remove, item)
+			delete(added, item.Key())
+			rotated[item.Key()] = now
+		}
+	}
+	if len(remove) > 0 {
+		if _, err := c.DeleteItems(profile.SujestionsListID, remove...); err != nil {
+			return err
+		}
+	}
+	st.Added, st.Rotated = added, rotated
+	n := maxSuggestions - len(added)
+	if n <= 0 {
+		st.LastRefresh = now
+		return nil
+	}
+	exclude := make(map[string]bool)
+	for key := range added {
+		exclude[key] = true
+	}
+	for key := range rotated {
+		exclude[key] = true
+	}
+	rec := recommend.New(c)
+	taste, err := rec.Taste(watch, watched, stars)
+	if err != nil {
+		return err
+	}
+	for _, item := range st.Dismissed {
+		taste.Dismiss(item)
+	}
+	picks, err := rec.Recommend(taste, exclude, n)
+	if err != nil {
+		return err
+	}
+	st.LastRefresh = now
+	if len(picks) == 0 {
+		// Every movie found is known or rotated out.
+		return nil
+	}
+	items := make([]client.Item, len(picks))
+	var explained []client.Item
+	for i, r := range picks {
+		items[i] = r.Item()
+		if r.Because != nil {
+			item := r.Item()
+			item.Comment = i18n.T(st.Language, "Because you watched %s", r.Because.DisplayTitle())
+			explained = append(explained, item)
+		}
+	}
+	if _, err := c.AddItems(profile.SujestionsListID, items...); err != nil {
+		return err
+	}
+	for _, r := range picks {
+		added[r.Key()] = now
+	}
+	if len(explained) == 0 {
+		return nil
+	}
+	_, err = c.UpdateItems(profile.SujestionsListID, explained...)
+	return err
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"log"
+	"math"
+	"time"
+
+	"github.com/rschio/movieApp/account"
+	"github.com/rschio/movieApp/client"
+	"github.com/rschio/movieApp/diary"
+	"github.com/rschio/movieApp/review"
+)
+
+// syncInterval is the interval of the periodic
+// sync with the linked TMDB accounts.
+const syncInterval = 6 * time.Hour
+
+// syncTMDB pulls the rated, favorite and watchlist movies of the
+// linked TMDB account into the lists of the synced profile. Rated
+// and favorite movies go to WatchedList and the watchlist goes to
+// WatchList, movies already on the lists are skipped, so a movie
+// watched is not added back to WatchList. The movies added to
+// WatchedList are logged in the diary of the profile, on the day
+// they were rated or today, and the ratings are imported as the
+// stars of the movies not rated in the profile yet. The stats of
+// the profile are computed again if the sync changed them.
+func (s *server) syncTMDB(settings *account.Settings) error {
+	sync := settings.Sync
+	c := s.client.WithToken(settings.TMDBAccessToken)
+	kinds := []string{client.AccountRated, client.AccountFavorites, client.AccountWatchlist}
+	// Request the account movies and the lists concurrently.
+	var (
+		movies         = make([][]client.AccountMovie, len(kinds))
+		watch, watched map[string]bool
+		errs           = make(chan error, 1)
+	)
+	for i, kind := range kinds {
+		go func(i int, kind string) {
+			var err error
+			movies[i], err = c.GetAllAccountMovies(settings.TMDBAccountID, kind)
+			errs <- err
+		}(i, kind)
+	}
+	go func() {
+		var err error
+		watch, err = listItemKeys(c, sync.WatchListID)
+		errs <- err
+	}()
+	watched, err := listItemKeys(c, sync.WatchedListID)
+	for i := 0; i < len(kinds)+1; i++ {
+		if e := <-errs; e != nil {
+			err = e
+		}
+	}
+	if err != nil {
+		return err
+	}
+	var (
+		toWatch, toWatched []client.Item
+		entries            []diary.Entry
+		ratings            []review.Review
+		today              = time.Now()
+	)
+	for i, kind := range kinds {
+		for _, m := range movies[i] {
+			key := m.Key()
+			if m.AccountRating != nil && m.AccountRating.Value > 0 {
+				// TMDB rates from 0.5 to 10, in half points.
+				stars := math.Round(m.AccountRating.Value) / 2
+				ratings = append(ratings, review.Review{Item: m.Item(), Stars: stars})
+			}
+			if watched[key] || (kind == client.AccountWatchlist && watch[key]) {
+				continue
+			}
+			if kind == client.AccountWatchlist {
+				toWatch = append(toWatch, m.Item())
+				watch[key] = true
+			} else {
+				toWatched = append(toWatched, m.Item())
+				watched[key] = true
+				e := diary.Entry{Item: m.Item(), WatchedAt: today}
+				if m.AccountRating != nil {
+					if t, err := time.Parse(time.RFC3339, m.AccountRating.CreatedAt); err == nil {
+						e.WatchedAt = t
+					}
+				}
+				entries = append(entries, e)
+			}
+		}
+	}
+	failed, err := addItems(c, sync.WatchedListID, toWatched)
+	if err != nil {
+		return err
+	}
+	if _, err := addItems(c, sync.WatchListID, toWatch); err != nil {
+		return err
+	}
+	// Best effort, the lists are already synced.
+	if sync.ProfileKey != "" {
+		logged := entries[:0]
+		for _, e := range entries {
+			if !failed[e.Item.Key()] {
+				logged = append(logged, e)
+			}
+		}
+		if _, err := s.diary.Import(sync.ProfileKey, logged); err != nil {
+			log.Println(err)
+		}
+		rated, err := s.reviews.Import(sync.ProfileKey, ratings)
+		if err != nil {
+			log.Println(err)
+		}
+		if len(toWatched) > 0 || len(toWatch) > 0 || rated > 0 {
+			s.stats.invalidate(sync.ProfileKey)
+		}
+	}
+	sync.LastSync = time.Now()
+	return nil
+}
+
+// syncAll syncs all the accounts with a synced profile. The settings
+// are updated atomically, so a sync does not undo changes made by the
+// user during the sync.
+func (s *server) syncAll() {
+	for _, email := range account.SettingsEmails(s.store) {
+		settings, err := account.LoadSettings(s.store, email)
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+		if !settings.Linked() || settings.Sync == nil {
+			continue
+		}
+		if err := s.syncTMDB(settings); err != nil {
+			log.Printf("failed to sync %s: %v", email, err)
+			continue
+		}
+		err = account.UpdateSettings(s.store, email, func(st *account.Settings) error {
+			// The profile may have changed during the sync.
+			if st.Sync != nil && st.Sync.WatchListID == settings.Sync.WatchListID {
+				st.Sync.LastSync = settings.Sync.LastSync
+			}
+			return nil
+		})
+		if err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+// syncPeriodically syncs all the accounts each interval.
+func (s *server) syncPeriodically(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.syncAll()
+		}
+	}
+}
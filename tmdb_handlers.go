@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/rschio/movieApp/account"
+	"github.com/rschio/movieApp/client"
+)
+
+// linkTMDB starts linking the user's own TMDB account, it creates
+// a request token and redirects the user to approve it in TMDB.
+// TMDB redirects the user back to /tmdb/callback.
+func (s *server) linkTMDB(w http.ResponseWriter, r *http.Request, acc *account.Account) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	settings := acc.Settings
+	if settings.Linked() {
+		http.Redirect(w, r, "/settings", http.StatusFound)
+		return
+	}
+	requestToken, err := s.client.CreateRequestToken(absoluteURL(r, "/tmdb/callback"))
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	settings.TMDBRequestToken = requestToken
+	if err := settings.Save(s.store, acc.Email); err != nil {
+		log.Println(err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, client.ApproveURL(requestToken), http.StatusFound)
+}
+
+// tmdbCallback finishes linking the user's TMDB account after the
+// user approved the request token. The profiles' lists are moved
+// to the user's TMDB account, so the user must login again.
+func (s *server) tmdbCallback(w http.ResponseWriter, r *http.Request, acc *account.Account) {
+	settings := acc.Settings
+	requestToken := settings.TMDBRequestToken
+	if requestToken == "" || settings.Linked() {
+		http.Error(w, "No TMDB account waiting to be linked", http.StatusBadRequest)
+		return
+	}
+	// The request token can be used only once.
+	settings.TMDBRequestToken = ""
+	if err := settings.Save(s.store, acc.Email); err != nil {
+		log.Println(err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	token, err := s.client.CreateAccessToken(requestToken)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "TMDB access was not approved", http.StatusForbidden)
+		return
+	}
+	userClient := s.client.WithToken(token.AccessToken)
+	err = s.moveLists(r.Context(), acc, s.client, userClient, func(st *account.Settings) {
+		st.TMDBAccessToken = token.AccessToken
+		st.TMDBAccountID = token.AccountID
+		// The synced lists are not the profiles' lists anymore.
+		st.Sync = nil
+	})
+	if err != nil {
+		log.Println(err)
+		if err := s.client.DeleteAccessToken(token.AccessToken); err != nil {
+			log.Println(err)
+		}
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/logout", http.StatusFound)
+}
+
+// unlinkTMDB moves the profiles' lists back to the app TMDB account
+// and logs out the user's TMDB access token.
+func (s *server) unlinkTMDB(w http.ResponseWriter, r *http.Request, acc *account.Account) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	settings := acc.Settings
+	if !settings.Linked() {
+		http.Redirect(w, r, "/settings", http.StatusFound)
+		return
+	}
+	accessToken := settings.TMDBAccessToken
+	err := s.moveLists(r.Context(), acc, s.clientFor(acc), s.client, func(st *account.Settings) {
+		st.TMDBAccessToken = ""
+		st.TMDBAccountID = ""
+		st.Sync = nil
+	})
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	// Best effort, the token is not used anymore.
+	if err := s.client.DeleteAccessToken(accessToken); err != nil {
+		log.Println(err)
+	}
+	http.Redirect(w, r, "/logout", http.StatusFound)
+}
+
+// tmdbSync syncs the current profile with the linked TMDB account now
+// and periodically, or stops syncing if the form field stop is set.
+func (s *server) tmdbSync(w http.ResponseWriter, r *http.Request, acc *account.Account) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	settings := acc.Settings
+	if !settings.Linked() {
+		http.Error(w, "TMDB account not linked", http.StatusBadRequest)
+		return
+	}
+	var sync *account.Sync
+	if r.FormValue("stop") == "" {
+		id, err := account.ProfileFromRequest(r, acc)
+		if err != nil {
+			log.Println(err)
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+			return
+		}
+		profile := acc.Profiles[id]
+		sync = &account.Sync{
+			Profile:       profile.Name,
+			ProfileKey:    acc.ProfileKey(id),
+			WatchListID:   profile.WatchListID,
+			WatchedListID: profile.WatchedListID,
+		}
+		// syncTMDB uses only the token and account
+		// ID of the copy, and sets its LastSync.
+		st := *settings
+		st.Sync = sync
+		if err := s.syncTMDB(&st); err != nil {
+			log.Println(err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		s.rememberProfile(r, acc, id)
+	}
+	err := account.UpdateSettings(s.store, acc.Email, func(st *account.Settings) error {
+		st.Sync = sync
+		return nil
+	})
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	settings.Sync = sync
+	http.Redirect(w, r, "/settings", http.StatusFound)
+}
+
+// moveLists moves the profiles' lists from the TMDB account of client
+// from to the TMDB account of client to. The lists are copied, then
+// change is applied to the settings and the new profiles are stored
+// in firebase, only after that the old lists are deleted. If any step
+// fails the account is left as it was.
+func (s *server) moveLists(ctx context.Context, acc *account.Account, from, to *client.Client, change func(*account.Settings)) error {
+	profiles, err := acc.CopyLists(from, to)
+	if err != nil {
+		return err
+	}
+	oldProfiles, oldSettings := acc.Profiles, *acc.Settings
+	change(acc.Settings)
+	if err := acc.Settings.Save(s.store, acc.Email); err != nil {
+		*acc.Settings = oldSettings
+		account.DeleteLists(to, profiles)
+		return err
+	}
+	acc.Profiles = profiles
+	if err := s.updateProfiles(ctx, acc); err != nil {
+		acc.Profiles = oldProfiles
+		*acc.Settings = oldSettings
+		if err := acc.Settings.Save(s.store, acc.Email); err != nil {
+			log.Println(err)
+		}
+		account.DeleteLists(to, profiles)
+		return err
+	}
+	account.DeleteLists(from, oldProfiles)
+	return nil
+}
@@ -0,0 +1,325 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/rschio/movieApp/account"
+	"github.com/rschio/movieApp/client"
+	"github.com/rschio/movieApp/progress"
+	"github.com/rschio/movieApp/review"
+)
+
+// tvPage is the data used to render tv.html.
+type tvPage struct {
+	Show    *client.TVShow
+	Trailer *client.Video
+	Cast    []client.Cast
+	// Progress is the profile's progress in the show
+	// and Next is the next episode to watch, if any.
+	Progress *progress.Show
+	Next     *continueItem
+	// Review is the profile's review of the
+	// show, nil if it was not reviewed.
+	Review *review.Review
+	Stars  []formOption
+}
+
+// tv displays the details of a tv show with its trailer, cast, seasons
+// and the profile's progress.
+func (s *server) tv(w http.ResponseWriter, r *http.Request, acc *account.Account) {
+	const path = "/tv/"
+	tvID, err := idFromPath(path, r)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	id, err := account.ProfileFromRequest(r, acc)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	c := s.clientFor(acc)
+	// Request the details, the videos and the credits concurrently.
+	var (
+		videos  []client.Video
+		credits = new(client.Credits)
+		errs    = make(chan error, 1)
+	)
+	go func() {
+		var err error
+		videos, err = c.GetVideos(client.MediaTV, tvID)
+		errs <- err
+	}()
+	go func() {
+		cr, err := c.GetCredits(client.MediaTV, tvID)
+		if err == nil {
+			credits = cr
+		}
+		errs <- err
+	}()
+	show, err := c.GetTV(tvID)
+	for i := 0; i < 2; i++ {
+		// Show without trailer or credits is still a show.
+		if e := <-errs; e != nil {
+			log.Println(e)
+		}
+	}
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+	sh, err := s.progress.Show(acc.ProfileKey(id), tvID)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	rev, err := s.reviews.Get(acc.ProfileKey(id), client.Item{MediaType: client.MediaTV, MediaID: tvID})
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	cast := credits.Cast
+	if len(cast) > maxCast {
+		cast = cast[:maxCast]
+	}
+	page := &tvPage{
+		Show:     show,
+		Trailer:  client.BestTrailer(videos, c.LanguageCode()),
+		Cast:     cast,
+		Progress: sh,
+		Review:   rev,
+		Stars:    starOptions(rev),
+	}
+	if season, episode, ok := progress.NextEpisode(sh, show); ok {
+		page.Next = &continueItem{Show: show, Season: season, Episode: episode}
+	}
+	s.tmpl(r, acc).ExecuteTemplate(w, "tv.html", page)
+}
+
+// continueItem is a tv show with the next episode to watch.
+type continueItem struct {
+	Show    *client.TVShow
+	Season  int
+	Episode int
+}
+
+// maxContinue is the number of tv shows displayed in
+// the continue watching row.
+const maxContinue = 6
+
+// continueWatching returns the tv shows in progress of profile with
+// the next episode to watch, the most recently watched first. The
+// shows are requested with c.
+func (s *server) continueWatching(c *client.Client, profile string) ([]*continueItem, error) {
+	shows, err := s.progress.Shows(profile)
+	if err != nil {
+		return nil, err
+	}
+	if len(shows) > maxContinue {
+		shows = shows[:maxContinue]
+	}
+	// Request the tv shows concurrently.
+	tvs := make([]*client.TVShow, len(shows))
+	errs := make(chan error, 1)
+	for i, sh := range shows {
+		go func(i, tvID int) {
+			var err error
+			tvs[i], err = c.GetTV(tvID)
+			errs <- err
+		}(i, sh.TVID)
+	}
+	for range shows {
+		if e := <-errs; e != nil {
+			err = e
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*continueItem, 0, len(shows))
+	for i, sh := range shows {
+		season, episode, ok := progress.NextEpisode(sh, tvs[i])
+		if !ok {
+			continue
+		}
+		items = append(items, &continueItem{
+			Show:    tvs[i],
+			Season:  season,
+			Episode: episode,
+		})
+	}
+	return items, nil
+}
+
+// episodeItem is an episode with the profile's progress.
+type episodeItem struct {
+	client.Episode
+	Watched bool
+}
+
+// seasonPage is the data used to render season.html.
+type seasonPage struct {
+	Show     *client.TVShow
+	Season   *client.Season
+	Episodes []episodeItem
+}
+
+// season displays the episodes of a season of a tv show with
+// actions to mark them as watched. The path is
+// /season/{tv show ID}/{season number}.
+func (s *server) season(w http.ResponseWriter, r *http.Request, acc *account.Account) {
+	const path = "/season/"
+	tvID, number, err := idPairFromPath(path, r)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	id, err := account.ProfileFromRequest(r, acc)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	c := s.clientFor(acc)
+	// Request the tv show and the season concurrently.
+	var (
+		show *client.TVShow
+		errs = make(chan error, 1)
+	)
+	go func() {
+		var err error
+		show, err = c.GetTV(tvID)
+		errs <- err
+	}()
+	season, err := c.GetSeason(tvID, number)
+	if e := <-errs; e != nil {
+		err = e
+	}
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+	sh, err := s.progress.Show(acc.ProfileKey(id), tvID)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	page := &seasonPage{Show: show, Season: season}
+	for _, e := range season.Episodes {
+		page.Episodes = append(page.Episodes, episodeItem{
+			Episode: e,
+			Watched: sh.IsWatched(number, e.EpisodeNumber),
+		})
+	}
+	s.tmpl(r, acc).ExecuteTemplate(w, "season.html", page)
+}
+
+// markEpisode marks or unmarks an episode as watched by the profile.
+// The form has the fields tv, season, episode and watched, an empty
+// watched unmarks the episode. If the field back is "browse" it
+// redirects to browse, else to the season page.
+func (s *server) markEpisode(w http.ResponseWriter, r *http.Request, acc *account.Account) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		log.Println(err)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	var (
+		tvID    = intParam(r.Form, "tv")
+		season  = intParam(r.Form, "season")
+		episode = intParam(r.Form, "episode")
+		watched = r.Form.Get("watched") != ""
+	)
+	if tvID <= 0 || episode <= 0 {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	id, err := account.ProfileFromRequest(r, acc)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	err = s.progress.MarkEpisode(acc.ProfileKey(id), tvID, season, episode, watched)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, progressRedirect(r.Form, tvID, season), http.StatusFound)
+}
+
+// markSeason marks or unmarks all the aired episodes of a season as
+// watched by the profile. The form has the fields tv, season and
+// watched, an empty watched unmarks the season.
+func (s *server) markSeason(w http.ResponseWriter, r *http.Request, acc *account.Account) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		log.Println(err)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	var (
+		tvID    = intParam(r.Form, "tv")
+		number  = intParam(r.Form, "season")
+		watched = r.Form.Get("watched") != ""
+	)
+	if tvID <= 0 {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	id, err := account.ProfileFromRequest(r, acc)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	season, err := s.clientFor(acc).GetSeason(tvID, number)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+	// Only the episodes already aired can be watched.
+	today := time.Now().Format("2006-01-02")
+	var episodes []int
+	for _, e := range season.Episodes {
+		if e.AirDate != "" && e.AirDate <= today {
+			episodes = append(episodes, e.EpisodeNumber)
+		}
+	}
+	err = s.progress.MarkEpisodes(acc.ProfileKey(id), tvID, number, episodes, watched)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, progressRedirect(r.Form, tvID, number), http.StatusFound)
+}
+
+// progressRedirect returns the page to redirect after a progress change.
+func progressRedirect(form url.Values, tvID, season int) string {
+	if form.Get("back") == "browse" {
+		return "/browse"
+	}
+	return "/season/" + strconv.Itoa(tvID) + "/" + strconv.Itoa(season)
+}
@@ -1,9 +1,11 @@
 package main
 
 import (
+	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/rschio/movieApp/client"
 )
@@ -29,6 +31,52 @@ func paginate(list *client.List) *ListPage {
 	return lp
 }
 
+// listPageSize is the number of items in a page of a TMDB list.
+const listPageSize = 20
+
+// listPage returns the page page of whole, a list
+// with all its results, see client.GetWholeList.
+func listPage(whole *client.List, page int) *client.List {
+	l := *whole
+	l.TotalResults = len(whole.Results)
+	l.TotalPages = (l.TotalResults + listPageSize - 1) / listPageSize
+	if page < 1 {
+		page = 1
+	}
+	l.Page = page
+	start := (page - 1) * listPageSize
+	if start > len(whole.Results) {
+		start = len(whole.Results)
+	}
+	end := start + listPageSize
+	if end > len(whole.Results) {
+		end = len(whole.Results)
+	}
+	l.Results = whole.Results[start:end]
+	return &l
+}
+
+// resultsPage paginates a page of movie results.
+func resultsPage(resp *client.SearchMovieResp) *ListPage {
+	return paginate(&client.List{
+		Page:         resp.Page,
+		TotalPages:   resp.TotalPages,
+		TotalResults: resp.TotalResults,
+		Results:      resp.Results,
+	})
+}
+
+// pageURL returns the URL to path with params and
+// the param page set to page.
+func pageURL(path string, params url.Values, page int) string {
+	q := make(url.Values, len(params)+1)
+	for k, v := range params {
+		q[k] = v
+	}
+	q.Set("page", strconv.Itoa(page))
+	return path + "?" + q.Encode()
+}
+
 // pageParam return the page params from url with name name.
 func pageParam(params url.Values, name string) int {
 	page, err := strconv.Atoi(params.Get(name))
@@ -38,32 +86,149 @@ func pageParam(params url.Values, name string) int {
 	return page
 }
 
+// intParam return the int param from url with name name
+// or 0 if it is not a valid int.
+func intParam(params url.Values, name string) int {
+	n, err := strconv.Atoi(params.Get(name))
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// floatParam return the float param from url with name name
+// or 0 if it is not a valid float.
+func floatParam(params url.Values, name string) float64 {
+	f, err := strconv.ParseFloat(params.Get(name), 64)
+	if err != nil {
+		return 0
+	}
+	return f
+}
+
+// intsParam return all the ints of param with name name,
+// the param can be repeated or separated by comma or pipe.
+// Invalid values are ignored.
+func intsParam(params url.Values, name string) []int {
+	var out []int
+	for _, val := range params[name] {
+		fields := strings.FieldsFunc(val, func(r rune) bool {
+			return r == ',' || r == '|' || r == ' '
+		})
+		for _, f := range fields {
+			if n, err := strconv.Atoi(f); err == nil {
+				out = append(out, n)
+			}
+		}
+	}
+	return out
+}
+
+// mediaTypeParam return the media type param from url with
+// name name, the default media type is movie.
+func mediaTypeParam(params url.Values, name string) string {
+	if params.Get(name) == client.MediaTV {
+		return client.MediaTV
+	}
+	return client.MediaMovie
+}
+
+// itemFromPath return the item from path, the rest of the
+// path must be "{media type}/{id}", e.g. "/watch/tv/1396".
+func itemFromPath(path string, r *http.Request) (client.Item, error) {
+	rest := r.URL.Path[len(path):]
+	i := strings.IndexByte(rest, '/')
+	if i < 0 {
+		return client.Item{}, fmt.Errorf("invalid item path: %s", rest)
+	}
+	mediaType := rest[:i]
+	if mediaType != client.MediaMovie && mediaType != client.MediaTV {
+		return client.Item{}, fmt.Errorf("invalid media type: %s", mediaType)
+	}
+	id, err := strconv.Atoi(rest[i+1:])
+	if err != nil {
+		return client.Item{}, err
+	}
+	return client.Item{MediaType: mediaType, MediaID: id}, nil
+}
+
+// idPairFromPath return the two ids from path, the rest
+// of the path must be "{id}/{id}", e.g. "/season/1396/2".
+func idPairFromPath(path string, r *http.Request) (int, int, error) {
+	rest := r.URL.Path[len(path):]
+	i := strings.IndexByte(rest, '/')
+	if i < 0 {
+		return 0, 0, fmt.Errorf("invalid path: %s", rest)
+	}
+	first, err := strconv.Atoi(rest[:i])
+	if err != nil {
+		return 0, 0, err
+	}
+	second, err := strconv.Atoi(rest[i+1:])
+	if err != nil {
+		return 0, 0, err
+	}
+	return first, second, nil
+}
+
+// absoluteURL returns the URL of path in the host of r.
+func absoluteURL(r *http.Request, path string) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + path
+}
+
 // idFromPath return the id from path.
 func idFromPath(path string, r *http.Request) (int, error) {
 	strID := r.URL.Path[len(path):]
 	return strconv.Atoi(strID)
 }
 
-// preferredGenre returns the ID that is most frequent
-// in the watch and watched lists.
-func preferredGenre(watch, watched []client.Result) int {
-	genres := make(map[int]int)
-	for _, r := range watch {
-		for _, id := range r.GenreIds {
-			genres[id]++
+// availableOnly returns the results that are in available.
+func availableOnly(results []client.Result, available map[string][]client.Provider) []client.Result {
+	out := results[:0]
+	for _, r := range results {
+		if _, ok := available[r.Key()]; ok {
+			out = append(out, r)
 		}
 	}
-	for _, r := range watched {
-		for _, id := range r.GenreIds {
-			genres[id]++
+	return out
+}
+
+// listBatchSize is the number of items
+// added to a list in each request.
+const listBatchSize = 100
+
+// addItems adds items to the list with ID listID, in batches of
+// listBatchSize, and returns the keys of the items not added.
+func addItems(c *client.Client, listID int, items []client.Item) (map[string]bool, error) {
+	failed := make(map[string]bool)
+	for _, batch := range client.Batches(items, listBatchSize) {
+		resp, err := c.AddItems(listID, batch...)
+		if err != nil {
+			return nil, err
 		}
-	}
-	mostWatchedKey := 0
-	mostWatchedVal := 0
-	for key, val := range genres {
-		if val > mostWatchedVal {
-			mostWatchedKey = key
+		for _, res := range resp.Results {
+			if !res.Success {
+				failed[client.Item{MediaType: res.MediaType, MediaID: res.MediaID}.Key()] = true
+			}
 		}
 	}
-	return mostWatchedKey
+	return failed, nil
+}
+
+// listItemKeys returns a set with the keys of all the
+// items of list listID, walking all the list pages.
+func listItemKeys(c *client.Client, listID int) (map[string]bool, error) {
+	keys := make(map[string]bool)
+	err := c.WalkList(listID, func(r client.Result) bool {
+		keys[r.Key()] = true
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
 }
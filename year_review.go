@@ -0,0 +1,405 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rschio/movieApp/account"
+	"github.com/rschio/movieApp/client"
+	"github.com/rschio/movieApp/mail"
+	"github.com/rschio/movieApp/stats"
+)
+
+const (
+	// yearReviewsBucket is the store bucket of the years in
+	// review, the keys are the profile key and the year,
+	// e.g. "a@b.com/0/2026".
+	yearReviewsBucket = "yearreviews"
+	// yearReviewTokensBucket maps the share tokens of the
+	// years in review to their keys in yearReviewsBucket.
+	yearReviewTokensBucket = "yearreviewtokens"
+	// reviewProfilesBucket keeps the reviewProfile of the
+	// profiles with a diary, the keys are profile keys.
+	reviewProfilesBucket = "reviewprofiles"
+	// yearReviewRunsBucket keeps the yearReviewRun of the
+	// emails of each year, the keys are the years.
+	yearReviewRunsBucket = "yearreviewruns"
+	// yearReviewRetry is the interval between the runs of the
+	// emails of a year while some of them fail.
+	yearReviewRetry = time.Hour
+)
+
+// reviewProfile is the account data needed to send the year in
+// review of a profile, the profiles are only in the firebase claims.
+type reviewProfile struct {
+	Email   string
+	Profile account.Profile
+	// Language is the language of the emails.
+	Language string
+}
+
+// yearReviewRun is the state of the emails of the reviews of a year.
+type yearReviewRun struct {
+	// Done is set when all the reviews were sent.
+	Done bool
+	// Tried is when the emails were last sent.
+	Tried time.Time
+}
+
+// yearReview is a stored year in review of a profile.
+type yearReview struct {
+	// Token identifies the shareable page of the review.
+	Token string
+	// Name is the name of the profile.
+	Name   string
+	Review *stats.YearReview
+	// Version identifies the data the review was computed from,
+	// see yearReviewVersion.
+	Version string
+	// Sent is set when the review was sent by email.
+	Sent bool
+}
+
+// yearReviewKey returns the key of the year in review
+// of year of the profile with key profile.
+func yearReviewKey(profile string, year int) string {
+	return profile + "/" + strconv.Itoa(year)
+}
+
+// newToken returns a random token for shareable links.
+func newToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// yearReviewVersion returns the version of the year in review of
+// year of the profile with key profile, in the language of c. It
+// changes when the diary entries of the year or their ratings change.
+func (s *server) yearReviewVersion(c *client.Client, profile string, year int) (string, error) {
+	entries, err := s.diary.Entries(profile, year, 0)
+	if err != nil {
+		return "", err
+	}
+	stars, err := s.reviews.Stars(profile)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	io.WriteString(h, c.LanguageCode())
+	for _, e := range entries {
+		key := e.Item.Key()
+		fmt.Fprintf(h, "\n%s %s %v", key, e.WatchedAt.Format("2006-01-02"), stars[key])
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// saveYearReview computes the year in review of year of the profile p,
+// with key profile, requesting the movies with c, and stores it. The
+// stored review keeps its share token and sent state.
+func (s *server) saveYearReview(c *client.Client, profile string, p account.Profile, year int) (*yearReview, error) {
+	version, err := s.yearReviewVersion(c, profile, year)
+	if err != nil {
+		return nil, err
+	}
+	watches, err := s.watches(c, profile, p.WatchedListID)
+	if err != nil {
+		return nil, err
+	}
+	key := yearReviewKey(profile, year)
+	yr := new(yearReview)
+	err = s.store.Update(yearReviewsBucket, key, yr, func() error {
+		if yr.Token == "" {
+			token, err := newToken()
+			if err != nil {
+				return err
+			}
+			yr.Token = token
+		}
+		yr.Name = p.Name
+		yr.Review = stats.Year(watches, year)
+		yr.Version = version
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.Put(yearReviewTokensBucket, yr.Token, key); err != nil {
+		return nil, err
+	}
+	return yr, nil
+}
+
+// loadYearReview returns the stored year in review of year of the
+// profile p, with key profile. It is computed again, as in
+// saveYearReview, if it is missing or its version changed.
+func (s *server) loadYearReview(c *client.Client, profile string, p account.Profile, year int) (*yearReview, error) {
+	yr := new(yearReview)
+	found, err := s.store.Get(yearReviewsBucket, yearReviewKey(profile, year), yr)
+	if err != nil {
+		return nil, err
+	}
+	if found && yr.Review != nil && yr.Name == p.Name {
+		version, err := s.yearReviewVersion(c, profile, year)
+		if err != nil {
+			return nil, err
+		}
+		if version == yr.Version {
+			return yr, nil
+		}
+	}
+	return s.saveYearReview(c, profile, p, year)
+}
+
+// reviewYear returns the year reviewed by default at now,
+// the last year in January and the current one otherwise.
+func reviewYear(now time.Time) int {
+	if now.Month() == time.January {
+		return now.Year() - 1
+	}
+	return now.Year()
+}
+
+// yearReviewPage is the data used to render yearreview.html.
+type yearReviewPage struct {
+	*yearReview
+	// Years are the years with watches of the profile.
+	Years []formOption
+	// ShareURL is the link to the shareable page, empty
+	// in the shareable page.
+	ShareURL string
+}
+
+// yearInReview displays the review of the movies watched by the profile
+// in the year in the param year, by default the year of reviewYear, with
+// a link to share it. The review is computed only when its data changed.
+func (s *server) yearInReview(w http.ResponseWriter, r *http.Request, acc *account.Account) {
+	id, err := account.ProfileFromRequest(r, acc)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	key := acc.ProfileKey(id)
+	year := intParam(r.URL.Query(), "year")
+	if year == 0 {
+		year = reviewYear(time.Now())
+	}
+	yr, err := s.loadYearReview(s.clientFor(acc), key, acc.Profiles[id], year)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	years, err := s.diary.Years(key)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	page := &yearReviewPage{
+		yearReview: yr,
+		ShareURL:   absoluteURL(r, "/year/"+yr.Token),
+	}
+	for _, y := range years {
+		v := strconv.Itoa(y)
+		page.Years = append(page.Years, formOption{Value: v, Label: v, Selected: y == year})
+	}
+	s.tmpl(r, acc).ExecuteTemplate(w, "yearreview.html", page)
+}
+
+// sharedYearReview displays the year in review with the token in
+// the path, it does not need login so the review can be shared.
+func (s *server) sharedYearReview(w http.ResponseWriter, r *http.Request) {
+	const path = "/year/"
+	token := r.URL.Path[len(path):]
+	var key string
+	found, err := s.store.Get(yearReviewTokensBucket, token, &key)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	yr := new(yearReview)
+	if found {
+		found, err = s.store.Get(yearReviewsBucket, key, yr)
+	}
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if !found || yr.Token != token {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+	s.tmpl(r, nil).ExecuteTemplate(w, "yearreview.html", &yearReviewPage{yearReview: yr})
+}
+
+// rememberProfile keeps the reviewProfile of the profile with ID
+// id of acc, to send its year in review. It must be called when
+// the diary of the profile changes. It is best effort.
+func (s *server) rememberProfile(r *http.Request, acc *account.Account, id int) {
+	p := &reviewProfile{Email: acc.Email, Profile: acc.Profiles[id], Language: uiLanguage(r, acc)}
+	if err := s.store.Put(reviewProfilesBucket, acc.ProfileKey(id), p); err != nil {
+		log.Println(err)
+	}
+}
+
+// reviewProfile returns the reviewProfile of the profile with key
+// profile, the profiles with a diary from before they were kept
+// are found in their suggestions.
+func (s *server) reviewProfile(profile string) (*reviewProfile, error) {
+	p := new(reviewProfile)
+	found, err := s.store.Get(reviewProfilesBucket, profile, p)
+	if err != nil || found {
+		return p, err
+	}
+	var st suggestions
+	found, err = s.store.Get(suggestionsBucket, profile, &st)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errors.New("profile unknown")
+	}
+	return &reviewProfile{Email: st.Email, Profile: st.Profile, Language: st.Language}, nil
+}
+
+// sendYearReviews sends the reviews of year by email to the profiles
+// with diary entries in year, the reviews count only the dated
+// watches, that were not sent yet. It reports if all were sent.
+func (s *server) sendYearReviews(year int) bool {
+	ok := true
+	for _, profile := range s.diary.Profiles() {
+		years, err := s.diary.Years(profile)
+		if err != nil {
+			log.Println(err)
+			ok = false
+			continue
+		}
+		if !hasYear(years, year) {
+			continue
+		}
+		key := yearReviewKey(profile, year)
+		yr := new(yearReview)
+		if _, err := s.store.Get(yearReviewsBucket, key, yr); err != nil {
+			log.Println(err)
+			ok = false
+			continue
+		}
+		if yr.Sent {
+			continue
+		}
+		if err := s.sendYearReview(profile, year); err != nil {
+			log.Printf("failed to send the year in review of %s: %v", key, err)
+			ok = false
+		}
+	}
+	return ok
+}
+
+// hasYear reports if year is in years.
+func hasYear(years []int, year int) bool {
+	for _, y := range years {
+		if y == year {
+			return true
+		}
+	}
+	return false
+}
+
+// sendYearReview sends the review of year of the profile with key
+// profile by email, with the link to share it if the server has a
+// base URL, and marks it as sent.
+func (s *server) sendYearReview(profile string, year int) error {
+	st, err := s.reviewProfile(profile)
+	if err != nil {
+		return err
+	}
+	settings, err := account.LoadSettings(s.store, st.Email)
+	if err != nil {
+		return err
+	}
+	c := s.clientFor(&account.Account{Email: st.Email, Settings: settings})
+	yr, err := s.saveYearReview(c, profile, st.Profile, year)
+	if err != nil {
+		return err
+	}
+	if !yr.Review.Empty() {
+		link := ""
+		if s.baseURL != "" {
+			link = s.baseURL + "/year/" + yr.Token
+		}
+		err := s.mailer.SendYearInReview(st.Language, st.Profile.Name, st.Email, yearInReviewMail(yr.Review), link)
+		if err != nil {
+			return err
+		}
+	}
+	return s.store.Update(yearReviewsBucket, yearReviewKey(profile, year), yr, func() error {
+		yr.Sent = true
+		return nil
+	})
+}
+
+// yearInReviewMail returns the email of rev, rev must not be empty.
+func yearInReviewMail(rev *stats.YearReview) *mail.YearInReview {
+	m := &mail.YearInReview{
+		Year:           rev.Year,
+		Movies:         rev.Movies,
+		Watches:        rev.Watches,
+		Hours:          rev.Hours(),
+		Longest:        rev.Longest.Title,
+		LongestRuntime: rev.Longest.Runtime,
+		First:          rev.First.Title,
+		Last:           rev.Last.Title,
+	}
+	if len(rev.TopRated) > 0 {
+		m.TopRated = rev.TopRated[0].Title
+	}
+	if rev.TopGenre.Count > 0 {
+		m.TopGenre = rev.TopGenre.Name
+	}
+	return m
+}
+
+// checkYearReviews starts sending the reviews of the last year in
+// January, unless they are still being sent or were all sent. While
+// some fail they are sent again each yearReviewRetry.
+func (s *server) checkYearReviews(now time.Time) {
+	if now.Month() != time.January {
+		return
+	}
+	year := now.Year() - 1
+	key := strconv.Itoa(year)
+	var run yearReviewRun
+	if _, err := s.store.Get(yearReviewRunsBucket, key, &run); err != nil {
+		log.Println(err)
+		return
+	}
+	if run.Done || now.Sub(run.Tried) < yearReviewRetry {
+		return
+	}
+	select {
+	case s.yearReviews <- struct{}{}:
+		go func() {
+			defer func() { <-s.yearReviews }()
+			run := yearReviewRun{Tried: now}
+			run.Done = s.sendYearReviews(year)
+			if err := s.store.Put(yearReviewRunsBucket, key, &run); err != nil {
+				log.Println(err)
+			}
+		}()
+	default:
+	}
+}